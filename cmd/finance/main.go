@@ -29,22 +29,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	migrator := repository.NewSQLiteMigrator(db)
+
 	profileRepo := repository.NewSQLiteProfile(db)
-	if err := profileRepo.Init(); err != nil {
-		fmt.Printf("could not init profile repo: %s", err)
-		os.Exit(1)
-	}
 	transactionRepo := repository.NewSQLiteTransaction(db)
-	if err := transactionRepo.Init(); err != nil {
-		fmt.Printf("could not init transaction repo: %s", err)
-		os.Exit(1)
-	}
+	scheduleRepo := repository.NewSQLiteSchedule(db)
+	exchangeRateRepo := repository.NewSQLiteExchangeRate(db)
+	accountRepo := repository.NewSQLiteAccount(db)
+	tagRuleRepo := repository.NewSQLiteTagRule(db)
+	tagRepo := repository.NewSQLiteTag(db)
+	envelopeRepo := repository.NewSQLiteEnvelope(db)
+	unitOfWork := repository.NewSQLiteUnitOfWork(db)
 
 	validator := validate.NewValidator(profileRepo, transactionRepo)
 
-	profileService := service.NewProfileService(profileRepo, transactionRepo, validator)
+	profileService := service.NewProfileService(unitOfWork, profileRepo, transactionRepo, validator)
+	exchangeRateService := service.NewExchangeRateService(unitOfWork, exchangeRateRepo, validator)
+	scheduleService := service.NewScheduleService(unitOfWork, profileRepo, scheduleRepo, validator)
+	accountService := service.NewAccountService(unitOfWork, profileRepo, accountRepo, validator)
+	tagRuleService := service.NewTagRuleService(unitOfWork, profileRepo, tagRuleRepo, validator)
+	tagService := service.NewTagService(unitOfWork, profileRepo, tagRepo, validator)
+	envelopeService := service.NewEnvelopeService(unitOfWork, profileRepo, envelopeRepo, validator)
+	forecastService := service.NewForecastService(scheduleService, exchangeRateService)
 
-	rootCmd := command.Load(profileService)
+	rootCmd := command.Load(migrator, profileService, scheduleService, exchangeRateService, accountService, tagRuleService, tagService, envelopeService, forecastService)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"time"
+)
+
+// NewAccount returns a new Account.
+func NewAccount() *Account {
+	return &Account{}
+}
+
+// Account represents a single bank account, savings account or credit card within a profile.
+type Account struct {
+	// ID is a unique identifier.
+	ID string
+	// ProfileID is the identifier for the profile the account belongs to.
+	ProfileID string
+	// Name is the name of the account, which is unique within the profile.
+	Name string
+	// Currency is the currency of the account and all of its transactions.
+	Currency Currency
+	// OpeningBalance is the balance of the account before any of its transactions, in the minor unit of Currency.
+	OpeningBalance int64
+	// Closed is true if the account can no longer be used for new transactions.
+	Closed bool
+	// CreatedAt is the time at which the account was created.
+	CreatedAt time.Time
+	// UpdatedAt is the time at which the account was last updated.
+	UpdatedAt time.Time
+}
+
+// Balance returns the opening balance of the account plus the total of the transactions in
+// the given collection that belong to the account.
+func (x *Account) Balance(transactions *TransactionCollection) Money {
+	balance := x.OpeningBalance
+	for _, t := range transactions.ForAccount(x.ID).All() {
+		balance += t.Amount
+	}
+	return NewMoney(balance, x.Currency)
+}
+
+// Transfer describes money moving from one account to another within the same profile.
+type Transfer struct {
+	// ID is a unique identifier, shared by both of the transactions the transfer creates.
+	ID string
+	// From is the account the money leaves.
+	From *Account
+	// To is the account the money arrives in.
+	To *Account
+	// Amount is the amount leaving From, in the currency of From.
+	Amount Money
+	// Received is the amount arriving in To, in the currency of To.
+	// Received is the same as Amount unless the accounts use different currencies.
+	Received Money
+	// Label is a label for both transactions.
+	Label string
+	// Tags contains a set of tags that both transactions can be grouped by.
+	Tags []string
+	// Date is the day on which the transfer took place.
+	Date time.Time
+}
+
+// Transactions returns the pair of linked transactions that record the transfer: money going out of
+// From and money coming into To.
+// Both transactions have the same TransferID, and IDs must be set on them before they are saved.
+func (x *Transfer) Transactions() (out *Transaction, in *Transaction) {
+	newTransaction := func(account *Account, amount Money, label string) *Transaction {
+		return NewTransaction().
+			WithProfileID(account.ProfileID).
+			WithAccountID(account.ID).
+			WithTransferID(x.ID).
+			WithLabel(label).
+			WithAmount(amount.Amount).
+			WithCurrency(amount.Currency).
+			WithTags(append([]string{}, x.Tags...)...).
+			WithDate(x.Date)
+	}
+
+	outLabel, inLabel := x.Label, x.Label
+	if x.Label == "" {
+		outLabel = "Transfer to " + x.To.Name
+		inLabel = "Transfer from " + x.From.Name
+	}
+
+	out = newTransaction(x.From, NewMoney(-abs(x.Amount.Amount), x.Amount.Currency), outLabel)
+	in = newTransaction(x.To, NewMoney(abs(x.Received.Amount), x.Received.Currency), inLabel)
+	return out, in
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,82 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestAccount_Balance(t *testing.T) {
+	t.Parallel()
+
+	account := &domain.Account{ID: "acc:1", Currency: "GBP", OpeningBalance: 10000}
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAccountID("acc:1").WithAmount(-2500),
+		domain.NewTransaction().WithAccountID("acc:1").WithAmount(500),
+		domain.NewTransaction().WithAccountID("acc:2").WithAmount(-100000),
+		domain.NewTransaction().WithAmount(-100000),
+	)
+
+	if exp, got := domain.NewMoney(8000, "GBP"), account.Balance(c); exp != got {
+		t.Errorf("expected balance %s, got %s", exp, got)
+	}
+}
+
+func TestTransfer_Transactions(t *testing.T) {
+	t.Parallel()
+
+	from := &domain.Account{ID: "acc:1", ProfileID: "pro:1", Name: "Current", Currency: "GBP"}
+	to := &domain.Account{ID: "acc:2", ProfileID: "pro:1", Name: "Holiday", Currency: "EUR"}
+
+	transfer := &domain.Transfer{
+		ID:       "trf:1",
+		From:     from,
+		To:       to,
+		Amount:   domain.NewMoney(10000, "GBP"),
+		Received: domain.NewMoney(11500, "EUR"),
+		Tags:     []string{"savings"},
+		Date:     date(2019, 3, 14),
+	}
+
+	out, in := transfer.Transactions()
+
+	checks := []struct {
+		name     string
+		t        *domain.Transaction
+		account  string
+		label    string
+		amount   domain.Money
+		transfer string
+	}{
+		{name: "out", t: out, account: "acc:1", label: "Transfer to Holiday", amount: domain.NewMoney(-10000, "GBP")},
+		{name: "in", t: in, account: "acc:2", label: "Transfer from Current", amount: domain.NewMoney(11500, "EUR")},
+	}
+	for _, c := range checks {
+		if c.t.AccountID != c.account {
+			t.Errorf("%s: expected account %s, got %s", c.name, c.account, c.t.AccountID)
+		}
+		if c.t.ProfileID != "pro:1" {
+			t.Errorf("%s: expected profile pro:1, got %s", c.name, c.t.ProfileID)
+		}
+		if c.t.Label != c.label {
+			t.Errorf("%s: expected label %s, got %s", c.name, c.label, c.t.Label)
+		}
+		if c.t.Money() != c.amount {
+			t.Errorf("%s: expected amount %s, got %s", c.name, c.amount, c.t.Money())
+		}
+		if !c.t.IsTransfer() || c.t.TransferID != "trf:1" {
+			t.Errorf("%s: expected transfer id trf:1, got %s", c.name, c.t.TransferID)
+		}
+		if !c.t.Date.Equal(date(2019, 3, 14)) {
+			t.Errorf("%s: unexpected date %s", c.name, c.t.Date)
+		}
+		if len(c.t.Tags) != 1 || c.t.Tags[0] != "savings" {
+			t.Errorf("%s: unexpected tags %v", c.name, c.t.Tags)
+		}
+	}
+
+	if exp, got := 0, len(domain.NewTransactionCollection().Add(out, in).ExcludeTransfers().All()); exp != got {
+		t.Errorf("expected %d transactions after excluding transfers, got %d", exp, got)
+	}
+}
@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMoney parses a human readable amount such as `-435.00`, `1,234.5` or `12,50` in the given currency.
+// The decimal separator is detected from the value:
+//   - If both `.` and `,` are used, the last of them is the decimal separator.
+//   - A single `,` followed by fewer than 3 digits is the decimal separator, such as `12,50`.
+//   - Otherwise `.` is the decimal separator.
+func ParseMoney(value string, currency Currency) (Money, error) {
+	return ParseMoneyWithSeparator(value, currency, detectDecimalSeparator(value))
+}
+
+// detectDecimalSeparator returns the decimal separator used in the given value.
+func detectDecimalSeparator(value string) rune {
+	lastDot := strings.LastIndex(value, ".")
+	lastComma := strings.LastIndex(value, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastComma > lastDot {
+			return ','
+		}
+		return '.'
+	case lastComma >= 0 && strings.Count(value, ",") == 1:
+		digits := 0
+		for _, r := range value[lastComma+1:] {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if digits > 0 && digits < 3 {
+			return ','
+		}
+	}
+	return '.'
+}
+
+// ParseMoneyWithSeparator parses a human readable amount such as `-435.00`, `1,234.5` or `(12,50)` in
+// the given currency.
+// decimalSeparator is the character used between the major and minor units, and is either '.' or ','.
+// The other of the two characters is treated as a thousands separator and ignored.
+// Amounts in parentheses are treated as negative, and any currency symbols or a leading or trailing
+// currency code are ignored.
+func ParseMoneyWithSeparator(value string, currency Currency, decimalSeparator rune) (Money, error) {
+	res := NewMoney(0, currency)
+	if decimalSeparator != '.' && decimalSeparator != ',' {
+		return res, fmt.Errorf("invalid decimal separator `%c`", decimalSeparator)
+	}
+	thousandsSeparator := ','
+	if decimalSeparator == ',' {
+		thousandsSeparator = '.'
+	}
+
+	s := strings.TrimSpace(value)
+	if currency != "" {
+		code := string(currency)
+		if len(s) >= len(code) && strings.EqualFold(s[:len(code)], code) {
+			s = s[len(code):]
+		} else if len(s) >= len(code) && strings.EqualFold(s[len(s)-len(code):], code) {
+			s = s[:len(s)-len(code)]
+		}
+		s = strings.TrimSpace(s)
+	}
+
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = s[1 : len(s)-1]
+	}
+
+	var whole, fraction strings.Builder
+	seenDecimal := false
+	seenSign := false
+	seenDigit := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			seenDigit = true
+			if seenDecimal {
+				fraction.WriteRune(r)
+			} else {
+				whole.WriteRune(r)
+			}
+		case r == decimalSeparator:
+			if seenDecimal {
+				return res, fmt.Errorf("invalid amount `%s`: multiple decimal separators", value)
+			}
+			seenDecimal = true
+		case r == thousandsSeparator || r == ' ' || r == '\'':
+			if seenDecimal {
+				return res, fmt.Errorf("invalid amount `%s`: unexpected `%c` after decimal separator", value, r)
+			}
+		case r == '-' || r == '+':
+			if seenSign || seenDigit {
+				return res, fmt.Errorf("invalid amount `%s`: unexpected `%c`", value, r)
+			}
+			seenSign = true
+			negative = negative != (r == '-')
+		case strings.ContainsRune("£$€¥₹₩", r):
+			// Ignore currency symbols.
+		default:
+			return res, fmt.Errorf("invalid amount `%s`: unexpected `%c`", value, r)
+		}
+	}
+	if !seenDigit {
+		return res, fmt.Errorf("invalid amount `%s`: no digits", value)
+	}
+
+	exponent := currency.Exponent()
+	minor := fraction.String()
+	if len(minor) > exponent {
+		// Allow trailing zeros, such as `1200.00` for JPY.
+		if strings.Trim(minor[exponent:], "0") != "" {
+			return res, fmt.Errorf("invalid amount `%s`: too many decimal places for %s", value, currencyName(currency))
+		}
+		minor = minor[:exponent]
+	}
+
+	digits := whole.String() + minor + strings.Repeat("0", exponent-len(minor))
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return res, fmt.Errorf("invalid amount `%s`: %s", value, err)
+	}
+	if negative {
+		amount = -amount
+	}
+	res.Amount = amount
+	return res, nil
+}
+
+// currencyName returns the name of the currency to use in error messages.
+func currencyName(currency Currency) string {
+	if currency == "" {
+		return "the currency"
+	}
+	return string(currency)
+}
@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestParseMoneyWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value            string
+		currency         domain.Currency
+		decimalSeparator rune
+		exp              int64
+		expErr           bool
+	}{
+		{value: "-435.00", currency: "GBP", decimalSeparator: '.', exp: -43500},
+		{value: "12,50", currency: "EUR", decimalSeparator: ',', exp: 1250},
+		{value: "1,234.5", currency: "GBP", decimalSeparator: '.', exp: 123450},
+		{value: "1.234,56", currency: "EUR", decimalSeparator: ',', exp: 123456},
+		{value: "£7", currency: "GBP", decimalSeparator: '.', exp: 700},
+		{value: "(12.34)", currency: "GBP", decimalSeparator: '.', exp: -1234},
+		{value: " +3.1 ", currency: "GBP", decimalSeparator: '.', exp: 310},
+		{value: "EUR 12.50", currency: "EUR", decimalSeparator: '.', exp: 1250},
+		{value: "12.50 eur", currency: "EUR", decimalSeparator: '.', exp: 1250},
+		{value: "¥1,200", currency: "JPY", decimalSeparator: '.', exp: 1200},
+		{value: "1200.00", currency: "JPY", decimalSeparator: '.', exp: 1200},
+		{value: "1.250", currency: "KWD", decimalSeparator: '.', exp: 1250},
+		{value: "1200.5", currency: "JPY", decimalSeparator: '.', expErr: true},
+		{value: "1.234", currency: "GBP", decimalSeparator: '.', expErr: true},
+		{value: "1.2.3", currency: "GBP", decimalSeparator: '.', expErr: true},
+		{value: "abc", currency: "GBP", decimalSeparator: '.', expErr: true},
+		{value: "", currency: "GBP", decimalSeparator: '.', expErr: true},
+		{value: "5-", currency: "GBP", decimalSeparator: '.', expErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := domain.ParseMoneyWithSeparator(tc.value, tc.currency, tc.decimalSeparator)
+			if tc.expErr {
+				if err == nil {
+					t.Errorf("expected error, got %d", got.Amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.Amount != tc.exp || got.Currency != tc.currency {
+				t.Errorf("expected %d %s, got %d %s", tc.exp, tc.currency, got.Amount, got.Currency)
+			}
+		})
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		currency domain.Currency
+		exp      int64
+	}{
+		{value: "-435.00", currency: "GBP", exp: -43500},
+		{value: "-435", currency: "GBP", exp: -43500},
+		{value: "12,50", currency: "EUR", exp: 1250},
+		{value: "12,5", currency: "EUR", exp: 1250},
+		{value: "1,234", currency: "GBP", exp: 123400},
+		{value: "1,234.56", currency: "GBP", exp: 123456},
+		{value: "1.234,56", currency: "EUR", exp: 123456},
+		{value: "1,200", currency: "JPY", exp: 1200},
+		{value: "1,234,567", currency: "GBP", exp: 123456700},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := domain.ParseMoney(tc.value, tc.currency)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got.Amount != tc.exp {
+				t.Errorf("expected %d, got %d", tc.exp, got.Amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"time"
+)
+
+// Budget describes how much money is left to spend over a period, such as
+// from one payday to the next.
+// All amounts are in the same units as Transaction.Amount.
+type Budget struct {
+	// ProfileID is the identifier for the profile the budget was calculated for.
+	ProfileID string
+	// Currency is the currency of all amounts in the budget.
+	Currency Currency
+	// Period is the range of days covered by the budget.
+	Period DateRange
+	// Today is the day the budget was calculated for.
+	Today time.Time
+
+	// DaysTotal is the number of days in the period.
+	DaysTotal int
+	// DaysElapsed is the number of days in the period before Today.
+	DaysElapsed int
+	// DaysRemaining is the number of days left in the period, including Today.
+	DaysRemaining int
+
+	// Income is the total of all incoming transactions in the period.
+	// Transfers between accounts are not counted as income or spending.
+	Income int64
+	// Spent is the total of all outgoing transactions in the period up to and including Today.
+	// Spent is positive when money has been spent.
+	Spent int64
+	// Upcoming is the total of all outgoing transactions in the period after Today.
+	// Upcoming is positive when money is due to be spent.
+	Upcoming int64
+	// Remaining is the amount of money left over for the rest of the period.
+	Remaining int64
+	// PerDay is the amount that can be spent on each remaining day.
+	PerDay int64
+	// PerWeek is the amount that can be spent in each remaining week.
+	PerWeek int64
+	// Pace is the difference between the amount that would have been spent by the end
+	// of Today when spending evenly across the period, and the amount actually spent.
+	// A positive Pace is ahead of budget, a negative Pace is behind.
+	Pace int64
+}
+
+// NewBudget calculates a Budget for the given period from the given transactions.
+// Transactions outside of the period and transfers between accounts are ignored.
+func NewBudget(period DateRange, today time.Time, transactions *TransactionCollection) *Budget {
+	today = TruncateDay(today)
+	from := TruncateDay(period.From)
+
+	b := &Budget{
+		Period:    period,
+		Today:     today,
+		DaysTotal: period.Days(),
+	}
+
+	switch {
+	case today.Before(from):
+		b.DaysElapsed = 0
+	case !today.Before(period.End()):
+		b.DaysElapsed = b.DaysTotal
+	default:
+		b.DaysElapsed = int(today.Sub(from).Hours() / 24)
+	}
+	b.DaysRemaining = b.DaysTotal - b.DaysElapsed
+
+	_ = transactions.Between(period).ExcludeTransfers().Range(nil, func(t *Transaction) error {
+		switch {
+		case t.Amount > 0:
+			b.Income += t.Amount
+		case t.Date.After(today):
+			b.Upcoming -= t.Amount
+		default:
+			b.Spent -= t.Amount
+		}
+		return nil
+	})
+
+	b.Remaining = b.Income - b.Spent - b.Upcoming
+
+	if b.DaysRemaining > 0 {
+		b.PerDay = b.Remaining / int64(b.DaysRemaining)
+		if b.DaysRemaining < 7 {
+			b.PerWeek = b.Remaining
+		} else {
+			b.PerWeek = b.Remaining * 7 / int64(b.DaysRemaining)
+		}
+	}
+
+	if b.DaysTotal > 0 {
+		daysSpent := int64(b.DaysElapsed)
+		if period.Contains(today) {
+			// Today counts towards the expected spend.
+			daysSpent++
+		}
+		expected := (b.Income - b.Upcoming) * daysSpent / int64(b.DaysTotal)
+		b.Pace = expected - b.Spent
+	}
+
+	return b
+}
+
+// Ahead returns true if less has been spent than expected so far.
+func (x *Budget) Ahead() bool {
+	return x.Pace >= 0
+}
@@ -0,0 +1,66 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestNewBudget(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(300000).WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithAmount(-3000).WithDate(date(2019, 3, 2)),
+		domain.NewTransaction().WithAmount(-100000).WithDate(date(2019, 3, 20)),
+		// Outside of the period.
+		domain.NewTransaction().WithAmount(-5000).WithDate(date(2019, 2, 28)),
+		// Transfers between accounts.
+		domain.NewTransaction().WithAmount(-20000).WithTransferID("trf:1").WithDate(date(2019, 3, 5)),
+		domain.NewTransaction().WithAmount(20000).WithTransferID("trf:1").WithDate(date(2019, 3, 5)),
+	)
+
+	period := domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 30)}
+	b := domain.NewBudget(period, date(2019, 3, 11), c)
+
+	checks := []struct {
+		name string
+		exp  int64
+		got  int64
+	}{
+		{name: "days total", exp: 30, got: int64(b.DaysTotal)},
+		{name: "days elapsed", exp: 10, got: int64(b.DaysElapsed)},
+		{name: "days remaining", exp: 20, got: int64(b.DaysRemaining)},
+		{name: "income", exp: 300000, got: b.Income},
+		{name: "spent", exp: 3000, got: b.Spent},
+		{name: "upcoming", exp: 100000, got: b.Upcoming},
+		{name: "remaining", exp: 197000, got: b.Remaining},
+		{name: "per day", exp: 9850, got: b.PerDay},
+		{name: "per week", exp: 68950, got: b.PerWeek},
+		// 200000 spread over 30 days, 11 of which have been spent.
+		{name: "pace", exp: 70333, got: b.Pace},
+	}
+	for _, c := range checks {
+		if c.exp != c.got {
+			t.Errorf("expected %s %d, got %d", c.name, c.exp, c.got)
+		}
+	}
+	if !b.Ahead() {
+		t.Errorf("expected budget to be ahead")
+	}
+}
+
+func TestNewBudget_AfterPeriod(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(domain.NewTransaction().WithAmount(1000).WithDate(date(2019, 3, 1)))
+
+	b := domain.NewBudget(domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 7)}, date(2019, 4, 1), c)
+	if exp, got := 0, b.DaysRemaining; exp != got {
+		t.Errorf("expected %d days remaining, got %d", exp, got)
+	}
+	if exp, got := int64(0), b.PerDay; exp != got {
+		t.Errorf("expected per day %d, got %d", exp, got)
+	}
+}
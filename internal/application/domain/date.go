@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+)
+
+// DateFormat is the format used when reading and writing dates.
+const DateFormat = "2006-01-02"
+
+// TruncateDay returns midnight UTC on the calendar day of t.
+func TruncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseDate parses a date in DateFormat.
+func ParseDate(value string) (time.Time, error) {
+	return time.Parse(DateFormat, value)
+}
+
+// DateRange represents a range of calendar days.
+// A zero From or To means the range is unbounded in that direction.
+type DateRange struct {
+	// From is the first day in the range.
+	From time.Time
+	// To is the last day in the range.
+	To time.Time
+}
+
+// Contains returns true if the given time falls on a day within the range.
+func (x DateRange) Contains(t time.Time) bool {
+	if !x.From.IsZero() && t.Before(TruncateDay(x.From)) {
+		return false
+	}
+	if !x.To.IsZero() && !t.Before(x.End()) {
+		return false
+	}
+	return true
+}
+
+// End returns the exclusive upper bound of the range, which is midnight at the
+// start of the day after To.
+// End returns a zero time if To is zero.
+func (x DateRange) End() time.Time {
+	if x.To.IsZero() {
+		return time.Time{}
+	}
+	return TruncateDay(x.To).AddDate(0, 0, 1)
+}
+
+// Days returns the number of days in the range, including both From and To.
+// Days returns 0 if the range is unbounded.
+func (x DateRange) Days() int {
+	if x.From.IsZero() || x.To.IsZero() {
+		return 0
+	}
+	days := int(x.End().Sub(TruncateDay(x.From)).Hours() / 24)
+	if days < 0 {
+		return 0
+	}
+	return days
+}
@@ -0,0 +1,52 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDateRange_Contains(t *testing.T) {
+	t.Parallel()
+
+	march := domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 31)}
+
+	tests := []struct {
+		name      string
+		dateRange domain.DateRange
+		t         time.Time
+		exp       bool
+	}{
+		{name: "unbounded", dateRange: domain.DateRange{}, t: date(2019, 1, 1), exp: true},
+		{name: "before from", dateRange: march, t: date(2019, 2, 28), exp: false},
+		{name: "on from", dateRange: march, t: date(2019, 3, 1), exp: true},
+		{name: "on to", dateRange: march, t: date(2019, 3, 31).Add(time.Hour * 23), exp: true},
+		{name: "after to", dateRange: march, t: date(2019, 4, 1), exp: false},
+		{name: "open ended", dateRange: domain.DateRange{From: date(2019, 3, 1)}, t: date(2030, 1, 1), exp: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.dateRange.Contains(tc.t); got != tc.exp {
+				t.Errorf("expected %v, got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestDateRange_Days(t *testing.T) {
+	t.Parallel()
+
+	r := domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 31)}
+	if exp, got := 31, r.Days(); exp != got {
+		t.Errorf("expected %d days, got %d", exp, got)
+	}
+	if exp, got := 0, (domain.DateRange{From: date(2019, 3, 1)}).Days(); exp != got {
+		t.Errorf("expected %d days, got %d", exp, got)
+	}
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"time"
+)
+
+// EnvelopePeriod defines how often the amount of an envelope is renewed.
+type EnvelopePeriod string
+
+const (
+	// EnvelopePeriodWeekly renews the envelope every week, starting on Monday.
+	EnvelopePeriodWeekly EnvelopePeriod = "weekly"
+	// EnvelopePeriodMonthly renews the envelope on the first day of every month.
+	EnvelopePeriodMonthly EnvelopePeriod = "monthly"
+	// EnvelopePeriodYearly renews the envelope on the first day of every year.
+	EnvelopePeriodYearly EnvelopePeriod = "yearly"
+)
+
+// Valid returns true if the envelope period is known.
+func (x EnvelopePeriod) Valid() bool {
+	switch x {
+	case EnvelopePeriodWeekly, EnvelopePeriodMonthly, EnvelopePeriodYearly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Range returns the period that contains the given day.
+func (x EnvelopePeriod) Range(day time.Time) DateRange {
+	day = TruncateDay(day)
+	var from time.Time
+	switch x {
+	case EnvelopePeriodWeekly:
+		from = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return DateRange{From: from, To: from.AddDate(0, 0, 6)}
+	case EnvelopePeriodYearly:
+		from = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return DateRange{From: from, To: from.AddDate(1, 0, -1)}
+	default:
+		from = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return DateRange{From: from, To: from.AddDate(0, 1, -1)}
+	}
+}
+
+// NewEnvelope returns a new Envelope.
+func NewEnvelope() *Envelope {
+	return &Envelope{
+		Period: EnvelopePeriodMonthly,
+	}
+}
+
+// Envelope caps the amount that can be spent on a tag, and its descendants, in each period.
+type Envelope struct {
+	// ID is a unique identifier.
+	ID string
+	// ProfileID is the identifier for the profile the envelope belongs to.
+	ProfileID string
+	// Tag is the tag the envelope applies to, which is unique within the profile.
+	// Transactions with any of its descendants also count towards the envelope.
+	Tag string
+	// Amount is the amount that can be spent in each period, in the minor unit of Currency.
+	Amount int64
+	// Currency is the currency of Amount.
+	Currency Currency
+	// Period is how often Amount is renewed.
+	Period EnvelopePeriod
+	// Rollover is true if the amount left over at the end of each period, or the amount overspent,
+	// is carried into the next period.
+	Rollover bool
+	// Start is the first day of the first period of the envelope, from which amounts are rolled over.
+	Start time.Time
+	// CreatedAt is the time at which the envelope was created.
+	CreatedAt time.Time
+	// UpdatedAt is the time at which the envelope was last updated.
+	UpdatedAt time.Time
+}
+
+// Spent returns the amount spent against the envelope within the given date range.
+// Incoming transactions with the tag, such as refunds, reduce the amount spent.
+// Transfers between accounts and transactions in other currencies are ignored.
+func (x *Envelope) Spent(transactions *TransactionCollection, dateRange DateRange) int64 {
+	var spent int64
+	_ = transactions.Between(dateRange).ExcludeTransfers().Range(nil, func(t *Transaction) error {
+		if t.Currency != x.Currency {
+			return nil
+		}
+		for _, tag := range t.Tags {
+			if TagWithin(tag, x.Tag) {
+				spent -= t.Amount
+				break
+			}
+		}
+		return nil
+	})
+	return spent
+}
+
+// Status calculates the status of the envelope in the period that contains the given day.
+// Transactions in other currencies are ignored, so they must be converted into Currency first.
+func (x *Envelope) Status(day time.Time, transactions *TransactionCollection) *EnvelopeStatus {
+	period := x.Period.Range(day)
+	res := &EnvelopeStatus{
+		Envelope: x,
+		Period:   period,
+		Budgeted: x.Amount,
+	}
+	if x.Rollover {
+		for p := x.Period.Range(x.Start); p.From.Before(period.From); p = x.Period.Range(p.End()) {
+			res.CarriedOver += x.Amount - x.Spent(transactions, p)
+		}
+	}
+	res.Available = res.Budgeted + res.CarriedOver
+	res.Spent = x.Spent(transactions, period)
+	res.Remaining = res.Available - res.Spent
+	return res
+}
+
+// EnvelopeStatus describes how much of an envelope has been spent in a single period.
+// All amounts are in the currency of the envelope.
+type EnvelopeStatus struct {
+	// Envelope is the envelope the status was calculated for.
+	Envelope *Envelope
+	// Period is the range of days covered by the status.
+	Period DateRange
+	// Budgeted is the amount of the envelope for the period.
+	Budgeted int64
+	// CarriedOver is the amount rolled over from previous periods.
+	// CarriedOver is positive when less than the budget was spent, and negative when more was spent.
+	CarriedOver int64
+	// Available is the total amount that can be spent in the period.
+	Available int64
+	// Spent is the amount spent in the period.
+	Spent int64
+	// Remaining is the amount left to spend in the period, which is negative when it has been overspent.
+	Remaining int64
+}
+
+// Overspent returns true if more than the available amount has been spent.
+func (x *EnvelopeStatus) Overspent() bool {
+	return x.Remaining < 0
+}
+
+// SpentPercent returns Spent as a percentage of Available, or 0 if nothing is available.
+func (x *EnvelopeStatus) SpentPercent() float64 {
+	if x.Available <= 0 {
+		return 0
+	}
+	return percent(x.Spent, x.Available)
+}
@@ -0,0 +1,94 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestEnvelopePeriod_Range(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		period domain.EnvelopePeriod
+		day    string
+		from   string
+		to     string
+	}{
+		// 2019-03-14 is a Thursday.
+		{period: domain.EnvelopePeriodWeekly, day: "2019-03-14", from: "2019-03-11", to: "2019-03-17"},
+		{period: domain.EnvelopePeriodWeekly, day: "2019-03-11", from: "2019-03-11", to: "2019-03-17"},
+		{period: domain.EnvelopePeriodWeekly, day: "2019-03-17", from: "2019-03-11", to: "2019-03-17"},
+		{period: domain.EnvelopePeriodMonthly, day: "2019-02-14", from: "2019-02-01", to: "2019-02-28"},
+		{period: domain.EnvelopePeriodMonthly, day: "2020-02-29", from: "2020-02-01", to: "2020-02-29"},
+		{period: domain.EnvelopePeriodYearly, day: "2019-03-14", from: "2019-01-01", to: "2019-12-31"},
+	}
+	for _, test := range tests {
+		day, _ := domain.ParseDate(test.day)
+		got := test.period.Range(day)
+		if from := got.From.Format(domain.DateFormat); from != test.from {
+			t.Errorf("expected %s period of %s to start on %s, got %s", test.period, test.day, test.from, from)
+		}
+		if to := got.To.Format(domain.DateFormat); to != test.to {
+			t.Errorf("expected %s period of %s to end on %s, got %s", test.period, test.day, test.to, to)
+		}
+	}
+}
+
+func TestEnvelope_Status(t *testing.T) {
+	t.Parallel()
+
+	gbp := func(amount int64) *domain.Transaction {
+		return domain.NewTransaction().WithAmount(amount).WithCurrency("GBP")
+	}
+	c := domain.NewTransactionCollection()
+	c.Add(
+		// January: 300 of 400 spent.
+		gbp(-30000).WithTags("groceries").WithDate(date(2019, 1, 10)),
+		// February: 450 of 400 spent, less a refund of 20.
+		gbp(-47000).WithTags("groceries/market").WithDate(date(2019, 2, 3)),
+		gbp(2000).WithTags("groceries").WithDate(date(2019, 2, 4)),
+		// March.
+		gbp(-10000).WithTags("groceries", "household").WithDate(date(2019, 3, 2)),
+		// Other tags, currencies and transfers are ignored.
+		gbp(-5000).WithTags("groceries-extra").WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithAmount(-5000).WithCurrency("EUR").WithTags("groceries").WithDate(date(2019, 3, 3)),
+		gbp(-5000).WithTags("groceries").WithTransferID("trf:1").WithDate(date(2019, 3, 3)),
+		// Before the envelope started.
+		gbp(-90000).WithTags("groceries").WithDate(date(2018, 12, 31)),
+	)
+
+	envelope := domain.NewEnvelope()
+	envelope.Tag = "groceries"
+	envelope.Amount = 40000
+	envelope.Currency = "GBP"
+	envelope.Start = date(2019, 1, 1)
+
+	checks := func(name string, s *domain.EnvelopeStatus, carriedOver int64, spent int64, remaining int64) {
+		if s.CarriedOver != carriedOver {
+			t.Errorf("%s: expected carried over %d, got %d", name, carriedOver, s.CarriedOver)
+		}
+		if s.Spent != spent {
+			t.Errorf("%s: expected spent %d, got %d", name, spent, s.Spent)
+		}
+		if s.Remaining != remaining {
+			t.Errorf("%s: expected remaining %d, got %d", name, remaining, s.Remaining)
+		}
+		if s.Available != s.Budgeted+s.CarriedOver {
+			t.Errorf("%s: expected available to be budgeted plus carried over, got %d", name, s.Available)
+		}
+	}
+
+	checks("without rollover", envelope.Status(date(2019, 3, 15), c), 0, 10000, 30000)
+	overspent := envelope.Status(date(2019, 2, 15), c)
+	checks("overspent", overspent, 0, 45000, -5000)
+	if !overspent.Overspent() {
+		t.Errorf("expected february to be overspent")
+	}
+
+	envelope.Rollover = true
+	// 100 left over in January, 50 overspent in February.
+	checks("with rollover", envelope.Status(date(2019, 3, 15), c), 5000, 10000, 35000)
+	checks("first period", envelope.Status(date(2019, 1, 15), c), 0, 30000, 10000)
+	// The 100 left over in January covers the overspend.
+	checks("overspent with rollover", envelope.Status(date(2019, 2, 15), c), 10000, 45000, 5000)
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// ExchangeRate is the rate used to convert from one currency to another from a given day.
+type ExchangeRate struct {
+	// From is the currency being converted from.
+	From Currency
+	// To is the currency being converted to.
+	To Currency
+	// Date is the first day the rate is in effect.
+	// The rate remains in effect until the date of the next rate between the same currencies.
+	Date time.Time
+	// Rate is the number of units of To that one unit of From is worth.
+	Rate float64
+}
+
+// MissingRateError is returned when there is no exchange rate in effect between two currencies on a day.
+type MissingRateError struct {
+	From Currency
+	To   Currency
+	Date time.Time
+}
+
+// Error implements error.
+func (x *MissingRateError) Error() string {
+	return fmt.Sprintf("no exchange rate from %s to %s on or before %s", x.From, x.To, x.Date.Format(DateFormat))
+}
+
+// currencyPair is the key used to store rates in a RateTable.
+type currencyPair struct {
+	from, to Currency
+}
+
+// RateTable contains exchange rates and converts amounts between currencies using the rate
+// in effect on a given day.
+type RateTable struct {
+	rates map[currencyPair][]*ExchangeRate
+}
+
+// NewRateTable returns a new RateTable containing the given rates.
+func NewRateTable(rates ...*ExchangeRate) *RateTable {
+	x := &RateTable{
+		rates: make(map[currencyPair][]*ExchangeRate),
+	}
+	x.Add(rates...)
+	return x
+}
+
+// Add adds the given rates to the table.
+// A rate replaces any existing rate between the same currencies on the same day.
+func (x *RateTable) Add(rates ...*ExchangeRate) {
+	for _, r := range rates {
+		pair := currencyPair{from: r.From, to: r.To}
+		day := TruncateDay(r.Date)
+		existing := x.rates[pair]
+
+		replaced := false
+		for i, e := range existing {
+			if TruncateDay(e.Date).Equal(day) {
+				existing[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			existing = append(existing, r)
+		}
+		sort.SliceStable(existing, func(i, j int) bool {
+			return existing[i].Date.Before(existing[j].Date)
+		})
+		x.rates[pair] = existing
+	}
+}
+
+// latest returns the most recent rate between the given currencies that is in effect on the given day.
+func (x *RateTable) latest(pair currencyPair, day time.Time) *ExchangeRate {
+	rates := x.rates[pair]
+	// Find the first rate that starts after the given day.
+	i := sort.Search(len(rates), func(i int) bool {
+		return TruncateDay(rates[i].Date).After(day)
+	})
+	if i == 0 {
+		return nil
+	}
+	return rates[i-1]
+}
+
+// Rate returns the rate in effect from one currency to another on the given day.
+// The inverse of a rate in the other direction is used if it is more recent.
+// A *MissingRateError is returned if no rate is in effect on the day.
+func (x *RateTable) Rate(from Currency, to Currency, date time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	day := TruncateDay(date)
+	direct := x.latest(currencyPair{from: from, to: to}, day)
+	inverse := x.latest(currencyPair{from: to, to: from}, day)
+	switch {
+	case direct != nil && (inverse == nil || !inverse.Date.After(direct.Date)):
+		return direct.Rate, nil
+	case inverse != nil:
+		return 1 / inverse.Rate, nil
+	default:
+		return 0, &MissingRateError{From: from, To: to, Date: day}
+	}
+}
+
+// Convert converts the given money into the given currency using the rate in effect on the given day.
+// The result is rounded to the nearest minor unit of the currency.
+func (x *RateTable) Convert(money Money, to Currency, date time.Time) (Money, error) {
+	if money.Currency == to {
+		return money, nil
+	}
+	rate, err := x.Rate(money.Currency, to, date)
+	if err != nil {
+		return Money{}, err
+	}
+	scale := math.Pow10(to.Exponent() - money.Currency.Exponent())
+	return NewMoney(int64(math.Round(float64(money.Amount)*rate*scale)), to), nil
+}
+
+// ConvertTransactions returns a new TransactionCollection containing copies of the given transactions
+// converted into the given currency using the rate in effect on the date of each transaction.
+func (x *RateTable) ConvertTransactions(transactions *TransactionCollection, to Currency) (*TransactionCollection, error) {
+	res := NewTransactionCollection()
+	err := transactions.Range(nil, func(t *Transaction) error {
+		converted, err := x.Convert(t.Money(), to, t.Date)
+		if err != nil {
+			return err
+		}
+		c := *t
+		c.Tags = make([]string, len(t.Tags))
+		copy(c.Tags, t.Tags)
+		c.Amount = converted.Amount
+		c.Currency = converted.Currency
+		res.Add(&c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
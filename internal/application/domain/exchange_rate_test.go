@@ -0,0 +1,82 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestRateTable_Convert(t *testing.T) {
+	t.Parallel()
+
+	rates := domain.NewRateTable(
+		&domain.ExchangeRate{From: "EUR", To: "GBP", Date: date(2019, 3, 1), Rate: 0.86},
+		&domain.ExchangeRate{From: "EUR", To: "GBP", Date: date(2019, 3, 15), Rate: 0.85},
+		&domain.ExchangeRate{From: "GBP", To: "JPY", Date: date(2019, 3, 1), Rate: 145.5},
+		&domain.ExchangeRate{From: "GBP", To: "EUR", Date: date(2019, 3, 20), Rate: 1.25},
+	)
+
+	tests := []struct {
+		name   string
+		money  domain.Money
+		to     domain.Currency
+		day    int
+		exp    domain.Money
+		expErr bool
+	}{
+		{name: "same currency", money: domain.NewMoney(1000, "GBP"), to: "GBP", day: 1, exp: domain.NewMoney(1000, "GBP")},
+		{name: "first rate", money: domain.NewMoney(1000, "EUR"), to: "GBP", day: 14, exp: domain.NewMoney(860, "GBP")},
+		{name: "later rate", money: domain.NewMoney(1000, "EUR"), to: "GBP", day: 15, exp: domain.NewMoney(850, "GBP")},
+		{name: "more recent inverse rate", money: domain.NewMoney(1000, "EUR"), to: "GBP", day: 25, exp: domain.NewMoney(800, "GBP")},
+		{name: "inverse rate", money: domain.NewMoney(-1200, "JPY"), to: "GBP", day: 2, exp: domain.NewMoney(-825, "GBP")},
+		{name: "different exponent", money: domain.NewMoney(-1000, "GBP"), to: "JPY", day: 2, exp: domain.NewMoney(-1455, "JPY")},
+		{name: "before first rate", money: domain.NewMoney(1000, "EUR"), to: "GBP", day: 0, expErr: true},
+		{name: "unknown pair", money: domain.NewMoney(1000, "USD"), to: "GBP", day: 2, expErr: true},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rates.Convert(tc.money, tc.to, date(2019, 3, tc.day))
+			if tc.expErr {
+				if _, ok := err.(*domain.MissingRateError); !ok {
+					t.Errorf("expected missing rate error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tc.exp {
+				t.Errorf("expected %s, got %s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRateTable_ConvertTransactions(t *testing.T) {
+	t.Parallel()
+
+	rates := domain.NewRateTable(
+		&domain.ExchangeRate{From: "EUR", To: "GBP", Date: date(2019, 3, 1), Rate: 0.5},
+	)
+	original := domain.NewTransaction().WithAmount(-1000).WithCurrency("EUR").WithTags("food").WithDate(date(2019, 3, 2))
+	c := domain.NewTransactionCollection().Add(
+		original,
+		domain.NewTransaction().WithAmount(2000).WithCurrency("GBP").WithDate(date(2019, 3, 2)),
+	)
+
+	converted, err := rates.ConvertTransactions(c, "GBP")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := int64(1500), converted.Sum(); exp != got {
+		t.Errorf("expected sum %d, got %d", exp, got)
+	}
+	if original.Amount != -1000 || original.Currency != "EUR" {
+		t.Errorf("original transaction was modified")
+	}
+
+	if _, err := rates.ConvertTransactions(c, "JPY"); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,238 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// Direction limits transactions to incoming or outgoing funds.
+type Direction string
+
+const (
+	// DirectionAny includes both incoming and outgoing transactions.
+	DirectionAny Direction = ""
+	// DirectionIn only includes transactions with a positive amount.
+	DirectionIn Direction = "in"
+	// DirectionOut only includes transactions with a negative amount.
+	DirectionOut Direction = "out"
+)
+
+// TransactionSort defines the field transactions are ordered by.
+type TransactionSort string
+
+const (
+	// TransactionSortDate orders transactions by date.
+	TransactionSortDate TransactionSort = "date"
+	// TransactionSortAmount orders transactions by their signed amount, so the largest outgoing
+	// transactions come first in ascending order.
+	TransactionSortAmount TransactionSort = "amount"
+	// TransactionSortLabel orders transactions by label.
+	TransactionSortLabel TransactionSort = "label"
+)
+
+// TransactionSorts contains all of the valid sort fields.
+var TransactionSorts = []TransactionSort{
+	TransactionSortDate,
+	TransactionSortAmount,
+	TransactionSortLabel,
+}
+
+// Valid returns true if the sort field is known.
+func (x TransactionSort) Valid() bool {
+	for _, s := range TransactionSorts {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// TransactionFilter describes which transactions to include, how to order them and which page of them to return.
+// The zero value includes every transaction ordered by date.
+//
+// A filter can be evaluated in memory with TransactionCollection.Filter, or by the transaction repository
+// which should give the same result.
+type TransactionFilter struct {
+	// DateRange limits transactions to those that took place within the range.
+	DateRange DateRange
+	// Direction limits transactions to incoming or outgoing funds.
+	Direction Direction
+	// AccountID limits transactions to those in the given account.
+	AccountID string
+	// Tags limits transactions to those with any of the given tags, or all of them if MatchAllTags is true.
+	// A tag also matches its descendants, so transport matches transactions tagged transport/rail.
+	Tags []string
+	// MatchAllTags requires transactions to have every tag in Tags, or one of its descendants.
+	MatchAllTags bool
+	// ExcludeTags excludes transactions with any of the given tags, or any of their descendants.
+	ExcludeTags []string
+	// Untagged limits transactions to those without any tags.
+	Untagged bool
+	// LabelContains limits transactions to those with a label containing the given text, ignoring the case of
+	// ASCII letters. Other letters must match exactly, as with the lower function of SQLite.
+	LabelContains string
+	// MinAmount limits transactions to those of at least the given size, regardless of direction.
+	// Transactions in other currencies are compared against the same number of major units of their currency.
+	// A zero amount means there is no minimum.
+	MinAmount Money
+	// MaxAmount limits transactions to those of at most the given size, regardless of direction.
+	// Transactions in other currencies are compared against the same number of major units of their currency.
+	// A zero amount means there is no maximum.
+	MaxAmount Money
+	// Query limits transactions to those that match the given query, or is nil to include all of them.
+	Query *Query
+	// Sort is the field transactions are ordered by. Transactions are ordered by date if Sort is empty.
+	// Transactions with the same value are ordered by date.
+	Sort TransactionSort
+	// Descending reverses the order of Sort.
+	Descending bool
+	// Offset is the number of matching transactions to skip.
+	Offset int
+	// Limit is the maximum number of transactions to return, or 0 for no limit.
+	Limit int
+}
+
+// Paginated returns true if the filter skips or limits the transactions returned.
+func (x TransactionFilter) Paginated() bool {
+	return x.Offset > 0 || x.Limit > 0
+}
+
+// WithoutPagination returns a copy of the filter that returns all matching transactions.
+// This is useful when more transactions will be added before the page is chosen.
+func (x TransactionFilter) WithoutPagination() TransactionFilter {
+	x.Offset = 0
+	x.Limit = 0
+	return x
+}
+
+// WithNormalisedTags returns a copy of the filter with the tags in Tags, ExcludeTags and Query normalised with
+// NormaliseTag, so they match the tags stored in a profile with the given tag case.
+func (x TransactionFilter) WithNormalisedTags(tagCase TagCase) TransactionFilter {
+	if x.Tags != nil {
+		x.Tags = NormaliseTags(x.Tags, tagCase)
+	}
+	if x.ExcludeTags != nil {
+		x.ExcludeTags = NormaliseTags(x.ExcludeTags, tagCase)
+	}
+	if x.Query != nil {
+		x.Query = x.Query.WithNormalisedTags(tagCase)
+	}
+	return x
+}
+
+// Matches returns true if the given transaction is included by the filter.
+// Matches ignores the order and pagination of the filter.
+func (x TransactionFilter) Matches(t *Transaction) bool {
+	if !x.DateRange.Contains(t.Date) {
+		return false
+	}
+	switch x.Direction {
+	case DirectionIn:
+		if t.Amount <= 0 {
+			return false
+		}
+	case DirectionOut:
+		if t.Amount >= 0 {
+			return false
+		}
+	}
+	if x.AccountID != "" && t.AccountID != x.AccountID {
+		return false
+	}
+	if len(x.Tags) > 0 {
+		matched := 0
+		for _, tag := range x.Tags {
+			if hasTagWithin(t, tag) {
+				matched++
+			}
+		}
+		if matched == 0 || (x.MatchAllTags && matched < len(x.Tags)) {
+			return false
+		}
+	}
+	for _, tag := range x.ExcludeTags {
+		if hasTagWithin(t, tag) {
+			return false
+		}
+	}
+	if x.Untagged && len(t.Tags) > 0 {
+		return false
+	}
+	if x.LabelContains != "" && !strings.Contains(lowerASCII(t.Label), lowerASCII(x.LabelContains)) {
+		return false
+	}
+	if x.Query != nil && !x.Query.Matches(t) {
+		return false
+	}
+	size := float64(abs(t.Amount))
+	if x.MinAmount.Amount != 0 && size < x.MinAmount.MinorUnitsIn(t.Currency) {
+		return false
+	}
+	if x.MaxAmount.Amount != 0 && size > x.MaxAmount.MinorUnitsIn(t.Currency) {
+		return false
+	}
+	return true
+}
+
+// lowerASCII returns s with only its ASCII letters converted to lower case, in the same way as the lower function
+// of SQLite, so that labels are compared the same way in memory as in the database.
+func lowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// hasTagWithin returns true if the transaction has the given tag, or one of its descendants.
+func hasTagWithin(t *Transaction, root string) bool {
+	for _, existing := range t.Tags {
+		if TagWithin(existing, root) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns a new TransactionCollection containing the transactions in x that match the filter,
+// in the order and page given by the filter.
+func (x *TransactionCollection) Filter(filter TransactionFilter) *TransactionCollection {
+	transactions := x.Subset(filter.Matches).SortByDate().All()
+
+	var less func(i, j int) bool
+	switch filter.Sort {
+	case TransactionSortAmount:
+		less = func(i, j int) bool {
+			return transactions[i].Amount < transactions[j].Amount
+		}
+	case TransactionSortLabel:
+		less = func(i, j int) bool {
+			return transactions[i].Label < transactions[j].Label
+		}
+	default:
+		less = func(i, j int) bool {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+	}
+	if filter.Descending {
+		ascending := less
+		less = func(i, j int) bool {
+			return ascending(j, i)
+		}
+	}
+	sort.SliceStable(transactions, less)
+
+	if filter.Offset > 0 {
+		if filter.Offset >= len(transactions) {
+			transactions = transactions[:0]
+		} else {
+			transactions = transactions[filter.Offset:]
+		}
+	}
+	if filter.Limit > 0 && filter.Limit < len(transactions) {
+		transactions = transactions[:filter.Limit]
+	}
+	return NewTransactionCollection().Add(transactions...)
+}
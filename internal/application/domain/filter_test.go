@@ -0,0 +1,146 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func filterTestTransactions() *domain.TransactionCollection {
+	day := func(d int) time.Time {
+		return time.Date(2019, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	return domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithID("tra:1").WithLabel("Train ticket").WithAmount(-43500).WithCurrency("GBP").
+			WithTags("travel", "commute").WithDate(day(1)),
+		domain.NewTransaction().WithID("tra:2").WithLabel("Salary").WithAmount(250000).WithCurrency("GBP").
+			WithDate(day(2)),
+		domain.NewTransaction().WithID("tra:3").WithLabel("Bus").WithAmount(-250).WithCurrency("GBP").
+			WithTags("travel").WithDate(day(3)),
+		domain.NewTransaction().WithID("tra:4").WithLabel("Ramen").WithAmount(-1200).WithCurrency("JPY").
+			WithTags("food", "travel").WithDate(day(3)),
+		domain.NewTransaction().WithID("tra:5").WithLabel("Groceries").WithAmount(-5000).WithCurrency("GBP").
+			WithTags("food").WithDate(day(4)).WithAccountID("acc:1"),
+	)
+}
+
+func ids(c *domain.TransactionCollection) []string {
+	res := make([]string, 0)
+	for _, t := range c.All() {
+		res = append(res, t.ID)
+	}
+	return res
+}
+
+func TestTransactionCollection_Filter(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range filterTestCases() {
+		got := ids(filterTestTransactions().Filter(tc.filter))
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.exp, got)
+		}
+	}
+}
+
+func TestTransactionFilter_WithNormalisedTags(t *testing.T) {
+	t.Parallel()
+
+	query, err := domain.ParseQuery(`not tag = " Food " and (tag:Travel or tag:"COMMUTE") and label ~ "Train"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	filter := domain.TransactionFilter{
+		Tags:        []string{"Travel", " travel "},
+		ExcludeTags: []string{"Commute"},
+		Query:       query,
+	}
+
+	got := filter.WithNormalisedTags(domain.TagCaseLower)
+	if exp := []string{"travel"}; !reflect.DeepEqual(exp, got.Tags) {
+		t.Errorf("expected tags %v, got %v", exp, got.Tags)
+	}
+	if exp := []string{"commute"}; !reflect.DeepEqual(exp, got.ExcludeTags) {
+		t.Errorf("expected exclude tags %v, got %v", exp, got.ExcludeTags)
+	}
+	if exp := []string{"tra:1"}; !reflect.DeepEqual(exp, ids(filterTestTransactions().Subset(got.Query.Matches))) {
+		t.Errorf("expected query to match %v, got %v", exp, ids(filterTestTransactions().Subset(got.Query.Matches)))
+	}
+	if exp := []string{}; !reflect.DeepEqual(exp, ids(filterTestTransactions().Subset(query.Matches))) {
+		t.Errorf("expected the original query to be unchanged, got %v", ids(filterTestTransactions().Subset(query.Matches)))
+	}
+
+	preserved := filter.WithNormalisedTags(domain.TagCasePreserve)
+	if exp := []string{"Travel", "travel"}; !reflect.DeepEqual(exp, preserved.Tags) {
+		t.Errorf("expected tags %v, got %v", exp, preserved.Tags)
+	}
+}
+
+func TestTransactionFilter_Matches_TagDescendants(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithID("tra:1").WithTags("transport"),
+		domain.NewTransaction().WithID("tra:2").WithTags("transport/rail", "travel/uk"),
+		domain.NewTransaction().WithID("tra:3").WithTags("transportation"),
+	)
+
+	tests := []struct {
+		name   string
+		filter domain.TransactionFilter
+		exp    []string
+	}{
+		{name: "Tag", filter: domain.TransactionFilter{Tags: []string{"transport"}}, exp: []string{"tra:1", "tra:2"}},
+		{name: "Descendant", filter: domain.TransactionFilter{Tags: []string{"transport/rail"}}, exp: []string{"tra:2"}},
+		{name: "AllTags", filter: domain.TransactionFilter{Tags: []string{"transport", "travel"}, MatchAllTags: true}, exp: []string{"tra:2"}},
+		{name: "ExcludeTags", filter: domain.TransactionFilter{ExcludeTags: []string{"transport"}}, exp: []string{"tra:3"}},
+	}
+	for _, tc := range tests {
+		got := ids(c.Filter(tc.filter))
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.exp, got)
+		}
+	}
+}
+
+type filterTestCase struct {
+	name   string
+	filter domain.TransactionFilter
+	exp    []string
+}
+
+func filterTestCases() []filterTestCase {
+	return []filterTestCase{
+		{name: "All", exp: []string{"tra:1", "tra:2", "tra:3", "tra:4", "tra:5"}},
+		{
+			name:   "DateRange",
+			filter: domain.TransactionFilter{DateRange: domain.DateRange{From: time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2019, 3, 3, 0, 0, 0, 0, time.UTC)}},
+			exp:    []string{"tra:2", "tra:3", "tra:4"},
+		},
+		{name: "In", filter: domain.TransactionFilter{Direction: domain.DirectionIn}, exp: []string{"tra:2"}},
+		{name: "Out", filter: domain.TransactionFilter{Direction: domain.DirectionOut}, exp: []string{"tra:1", "tra:3", "tra:4", "tra:5"}},
+		{name: "Account", filter: domain.TransactionFilter{AccountID: "acc:1"}, exp: []string{"tra:5"}},
+		{name: "AnyTag", filter: domain.TransactionFilter{Tags: []string{"commute", "food"}}, exp: []string{"tra:1", "tra:4", "tra:5"}},
+		{name: "AllTags", filter: domain.TransactionFilter{Tags: []string{"food", "travel"}, MatchAllTags: true}, exp: []string{"tra:4"}},
+		{name: "ExcludeTags", filter: domain.TransactionFilter{Tags: []string{"travel"}, ExcludeTags: []string{"commute", "food"}}, exp: []string{"tra:3"}},
+		{name: "Untagged", filter: domain.TransactionFilter{Untagged: true}, exp: []string{"tra:2"}},
+		{name: "LabelContains", filter: domain.TransactionFilter{LabelContains: "RA"}, exp: []string{"tra:1", "tra:4"}},
+		{
+			name:   "MinAmount",
+			filter: domain.TransactionFilter{Tags: []string{"travel"}, MinAmount: domain.NewMoney(5000, "GBP")},
+			exp:    []string{"tra:1", "tra:4"},
+		},
+		{
+			name:   "MaxAmount",
+			filter: domain.TransactionFilter{MinAmount: domain.NewMoney(50, "GBP"), MaxAmount: domain.NewMoney(5000, "GBP")},
+			exp:    []string{"tra:3", "tra:5"},
+		},
+		{name: "SortAmount", filter: domain.TransactionFilter{Sort: domain.TransactionSortAmount}, exp: []string{"tra:1", "tra:5", "tra:4", "tra:3", "tra:2"}},
+		{name: "SortLabelDesc", filter: domain.TransactionFilter{Sort: domain.TransactionSortLabel, Descending: true}, exp: []string{"tra:1", "tra:2", "tra:4", "tra:5", "tra:3"}},
+		{name: "SortDateDesc", filter: domain.TransactionFilter{Sort: domain.TransactionSortDate, Descending: true}, exp: []string{"tra:5", "tra:3", "tra:4", "tra:2", "tra:1"}},
+		{name: "Limit", filter: domain.TransactionFilter{Direction: domain.DirectionOut, Limit: 2}, exp: []string{"tra:1", "tra:3"}},
+		{name: "Offset", filter: domain.TransactionFilter{Direction: domain.DirectionOut, Offset: 1, Limit: 2}, exp: []string{"tra:3", "tra:4"}},
+		{name: "OffsetPastEnd", filter: domain.TransactionFilter{Offset: 10}, exp: []string{}},
+	}
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"time"
+)
+
+// Forecast describes how a balance is expected to change day by day over a period.
+// All amounts are in the same units as Transaction.Amount.
+type Forecast struct {
+	// Currency is the currency of all amounts in the forecast.
+	Currency Currency
+	// Today is the day the forecast starts from.
+	Today time.Time
+	// Period is the range of days covered by the forecast, starting the day after Today.
+	Period DateRange
+	// Opening is the balance at the end of Today.
+	Opening int64
+	// Threshold is the balance below which the forecast gives a warning.
+	Threshold int64
+	// Days contains every day in the period in order, including days without any transactions.
+	Days []*ForecastDay
+	// Lowest is the first day with the lowest balance, or nil if the balance never falls below Opening.
+	Lowest *ForecastDay
+}
+
+// ForecastDay is the expected balance at the end of a single day.
+type ForecastDay struct {
+	// Date is the day.
+	Date time.Time
+	// Transactions contains the transactions expected on the day.
+	Transactions []*Transaction
+	// Change is the total of the transactions expected on the day.
+	Change int64
+	// Balance is the expected balance at the end of the day.
+	Balance int64
+}
+
+// NewForecast calculates a Forecast over the given period, starting from the given opening balance at
+// the end of today and applying the given transactions on the day they are expected.
+// Transactions outside of the period are ignored. The period must have an end date.
+func NewForecast(opening int64, today time.Time, period DateRange, transactions *TransactionCollection) *Forecast {
+	today = TruncateDay(today)
+	res := &Forecast{
+		Today:   today,
+		Period:  period,
+		Opening: opening,
+		Days:    make([]*ForecastDay, 0, period.Days()),
+	}
+
+	byDay := make(map[time.Time][]*Transaction)
+	_ = transactions.Between(period).Range(nil, func(t *Transaction) error {
+		day := TruncateDay(t.Date)
+		byDay[day] = append(byDay[day], t)
+		return nil
+	})
+
+	balance, lowest := opening, opening
+	for day := TruncateDay(period.From); day.Before(period.End()); day = day.AddDate(0, 0, 1) {
+		d := &ForecastDay{
+			Date:         day,
+			Transactions: byDay[day],
+		}
+		for _, t := range d.Transactions {
+			d.Change += t.Amount
+		}
+		balance += d.Change
+		d.Balance = balance
+		res.Days = append(res.Days, d)
+
+		if d.Balance < lowest {
+			res.Lowest, lowest = d, d.Balance
+		}
+	}
+	return res
+}
+
+// Closing returns the expected balance at the end of the period.
+func (x *Forecast) Closing() int64 {
+	if len(x.Days) == 0 {
+		return x.Opening
+	}
+	return x.Days[len(x.Days)-1].Balance
+}
+
+// LowestBalance returns the lowest balance in the forecast and the first day it is expected on,
+// which is Today if the balance never falls below Opening.
+func (x *Forecast) LowestBalance() (time.Time, int64) {
+	if x.Lowest == nil {
+		return x.Today, x.Opening
+	}
+	return x.Lowest.Date, x.Lowest.Balance
+}
+
+// BelowThreshold returns true if the given balance is below the threshold of the forecast.
+func (x *Forecast) BelowThreshold(balance int64) bool {
+	return balance < x.Threshold
+}
+
+// FirstBelowThreshold returns the first day on which the balance is expected to be below the threshold,
+// which is Today if the opening balance already is. False is returned if the balance never falls below it.
+func (x *Forecast) FirstBelowThreshold() (time.Time, bool) {
+	if x.BelowThreshold(x.Opening) {
+		return x.Today, true
+	}
+	for _, d := range x.Days {
+		if x.BelowThreshold(d.Balance) {
+			return d.Date, true
+		}
+	}
+	return time.Time{}, false
+}
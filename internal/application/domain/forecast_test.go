@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestNewForecast(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithLabel("Rent").WithAmount(-80000).WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithLabel("Gym").WithAmount(-2500).WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithLabel("Salary").WithAmount(250000).WithDate(date(2019, 3, 5)),
+		// Outside of the period.
+		domain.NewTransaction().WithLabel("Coffee").WithAmount(-300).WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithLabel("Coffee").WithAmount(-300).WithDate(date(2019, 3, 7)),
+	)
+
+	period := domain.DateRange{From: date(2019, 3, 2), To: date(2019, 3, 6)}
+	f := domain.NewForecast(50000, date(2019, 3, 1), period, c)
+	f.Threshold = 10000
+
+	if len(f.Days) != 5 {
+		t.Fatalf("expected a day for each day in the period, got %d", len(f.Days))
+	}
+	exp := []int64{50000, -32500, -32500, 217500, 217500}
+	for i, d := range f.Days {
+		if d.Balance != exp[i] {
+			t.Errorf("expected balance of %d on %s, got %d", exp[i], d.Date.Format(domain.DateFormat), d.Balance)
+		}
+	}
+	if got := len(f.Days[1].Transactions); got != 2 {
+		t.Errorf("expected 2 transactions on the lowest day, got %d", got)
+	}
+	if f.Lowest != f.Days[1] {
+		t.Errorf("expected the lowest day to be the first day with the lowest balance, got %v", f.Lowest)
+	}
+	if got := f.Closing(); got != 217500 {
+		t.Errorf("expected closing balance of 217500, got %d", got)
+	}
+	if below, ok := f.FirstBelowThreshold(); !ok || !below.Equal(date(2019, 3, 3)) {
+		t.Errorf("expected the balance to fall below the threshold on 2019-03-03, got %v", below)
+	}
+
+	// The opening balance is the lowest when the balance never falls below it.
+	f = domain.NewForecast(50000, date(2019, 3, 1), domain.DateRange{From: date(2019, 3, 5), To: date(2019, 3, 6)}, c)
+	if day, balance := f.LowestBalance(); f.Lowest != nil || !day.Equal(date(2019, 3, 1)) || balance != 50000 {
+		t.Errorf("expected the opening balance to be the lowest, got %d on %s", balance, day.Format(domain.DateFormat))
+	}
+	if _, ok := f.FirstBelowThreshold(); ok {
+		t.Errorf("expected the balance to stay above the threshold")
+	}
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency code, such as GBP.
+type Currency string
+
+// DefaultCurrency is the currency used for profiles that have not chosen one.
+const DefaultCurrency Currency = "GBP"
+
+// currencyInfo describes how amounts in a currency are stored and displayed.
+type currencyInfo struct {
+	// exponent is the number of digits after the decimal point, e.g. 2 for GBP and 0 for JPY.
+	exponent int
+	// symbol is shown before amounts in the currency.
+	// The currency code is shown instead if symbol is empty.
+	symbol string
+}
+
+// currencies contains the currencies that are supported.
+var currencies = map[Currency]currencyInfo{
+	"AUD": {exponent: 2, symbol: "A$"},
+	"BHD": {exponent: 3},
+	"CAD": {exponent: 2, symbol: "C$"},
+	"CHF": {exponent: 2},
+	"CNY": {exponent: 2},
+	"CZK": {exponent: 2},
+	"DKK": {exponent: 2},
+	"EUR": {exponent: 2, symbol: "€"},
+	"GBP": {exponent: 2, symbol: "£"},
+	"HKD": {exponent: 2, symbol: "HK$"},
+	"HUF": {exponent: 2},
+	"INR": {exponent: 2, symbol: "₹"},
+	"ISK": {exponent: 0},
+	"JOD": {exponent: 3},
+	"JPY": {exponent: 0, symbol: "¥"},
+	"KRW": {exponent: 0, symbol: "₩"},
+	"KWD": {exponent: 3},
+	"NOK": {exponent: 2},
+	"NZD": {exponent: 2, symbol: "NZ$"},
+	"OMR": {exponent: 3},
+	"PLN": {exponent: 2},
+	"SEK": {exponent: 2},
+	"SGD": {exponent: 2, symbol: "S$"},
+	"TND": {exponent: 3},
+	"USD": {exponent: 2, symbol: "$"},
+	"ZAR": {exponent: 2},
+}
+
+// Currencies returns the codes of all supported currencies in alphabetical order.
+func Currencies() []Currency {
+	res := make([]Currency, 0, len(currencies))
+	for c := range currencies {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// ParseCurrency returns the Currency for the given code, ignoring case.
+// ok is false if the currency is not supported.
+func ParseCurrency(code string) (currency Currency, ok bool) {
+	currency = Currency(strings.ToUpper(strings.TrimSpace(code)))
+	return currency, currency.Valid()
+}
+
+// Valid returns true if the currency is supported.
+func (x Currency) Valid() bool {
+	_, ok := currencies[x]
+	return ok
+}
+
+// Exponent returns the number of digits after the decimal point in amounts of the currency.
+// Unknown currencies are assumed to have 2.
+func (x Currency) Exponent() int {
+	if info, ok := currencies[x]; ok {
+		return info.exponent
+	}
+	return 2
+}
+
+// Symbol returns the symbol shown before amounts in the currency, such as £.
+// The currency code followed by a space is returned if the currency has no symbol.
+func (x Currency) Symbol() string {
+	if info, ok := currencies[x]; ok && info.symbol != "" {
+		return info.symbol
+	}
+	if x == "" {
+		return ""
+	}
+	return string(x) + " "
+}
+
+// Money is an amount of a currency.
+type Money struct {
+	// Amount is the amount in the minor unit of the currency, such as pence for GBP.
+	Amount int64
+	// Currency is the currency of the amount.
+	Currency Currency
+}
+
+// NewMoney returns a new Money.
+func NewMoney(amount int64, currency Currency) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// Decimal returns the amount in the major unit of the currency without a symbol, such as -435.00.
+func (x Money) Decimal() string {
+	amount := x.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	exponent := x.Currency.Exponent()
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String returns the amount in the major unit of the currency with its symbol, such as -£435.00.
+func (x Money) String() string {
+	decimal := x.Decimal()
+	if strings.HasPrefix(decimal, "-") {
+		return "-" + x.Currency.Symbol() + decimal[1:]
+	}
+	return x.Currency.Symbol() + decimal
+}
+
+// MinorUnitsIn returns the amount as the same number of major units of the given currency, in the minor unit
+// of that currency. For example, £50.00 is 50 in JPY and 50000 in BHD.
+// No exchange rate is applied.
+func (x Money) MinorUnitsIn(currency Currency) float64 {
+	return float64(x.Amount) * math.Pow10(currency.Exponent()-x.Currency.Exponent())
+}
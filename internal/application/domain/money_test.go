@@ -0,0 +1,76 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestMoney_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		money      domain.Money
+		expDecimal string
+		expString  string
+	}{
+		{money: domain.NewMoney(-43500, "GBP"), expDecimal: "-435.00", expString: "-£435.00"},
+		{money: domain.NewMoney(5, "GBP"), expDecimal: "0.05", expString: "£0.05"},
+		{money: domain.NewMoney(0, "EUR"), expDecimal: "0.00", expString: "€0.00"},
+		{money: domain.NewMoney(1250, "EUR"), expDecimal: "12.50", expString: "€12.50"},
+		{money: domain.NewMoney(-1200, "JPY"), expDecimal: "-1200", expString: "-¥1200"},
+		{money: domain.NewMoney(1250, "KWD"), expDecimal: "1.250", expString: "KWD 1.250"},
+		{money: domain.NewMoney(1250, ""), expDecimal: "12.50", expString: "12.50"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.expString, func(t *testing.T) {
+			if got := tc.money.Decimal(); got != tc.expDecimal {
+				t.Errorf("expected decimal %s, got %s", tc.expDecimal, got)
+			}
+			if got := tc.money.String(); got != tc.expString {
+				t.Errorf("expected string %s, got %s", tc.expString, got)
+			}
+		})
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	t.Parallel()
+
+	if c, ok := domain.ParseCurrency(" eur "); !ok || c != "EUR" {
+		t.Errorf("expected EUR, got %s (%v)", c, ok)
+	}
+	if _, ok := domain.ParseCurrency("XXX"); ok {
+		t.Errorf("expected unknown currency")
+	}
+	if domain.Currency("JPY").Exponent() != 0 {
+		t.Errorf("expected JPY to have no minor units")
+	}
+}
+
+func TestTransactionCollection_Totals(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithAmount(-1000).WithCurrency("GBP"),
+		domain.NewTransaction().WithAmount(-500).WithCurrency("EUR"),
+		domain.NewTransaction().WithAmount(2000).WithCurrency("GBP"),
+		domain.NewTransaction().WithAmount(-300).WithCurrency("JPY"),
+	)
+
+	got := c.Totals()
+	exp := []domain.Money{
+		domain.NewMoney(-500, "EUR"),
+		domain.NewMoney(1000, "GBP"),
+		domain.NewMoney(-300, "JPY"),
+	}
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("expected %v, got %v", exp[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"time"
+)
+
+// MonthTotal contains the totals of the transactions in a single calendar month.
+// All amounts are in the same units as Transaction.Amount.
+type MonthTotal struct {
+	// Month is the first day of the month.
+	Month time.Time
+	// LastDay is the last day of the month that is included in the totals.
+	// LastDay is only set on the months within the summary.
+	LastDay time.Time
+	// Count is the number of transactions in the month, excluding transfers.
+	Count int
+	// Incoming is the total of all incoming transactions in the month.
+	Incoming int64
+	// Outgoing is the total of all outgoing transactions in the month.
+	Outgoing int64
+	// Balance is the running balance at the end of the month.
+	// Balance is only set on the months within the summary.
+	Balance int64
+	// PreviousMonth contains the totals of the month before, or nil if it is before both the summary and
+	// the first transaction.
+	PreviousMonth *MonthTotal
+	// LastYear contains the totals of the same month in the previous year, or nil if it is before both
+	// the summary and the first transaction.
+	LastYear *MonthTotal
+}
+
+// Partial returns true if the totals stop before the end of the month.
+func (x *MonthTotal) Partial() bool {
+	return !x.LastDay.IsZero() && x.LastDay.Before(x.Month.AddDate(0, 1, -1))
+}
+
+// Net returns the sum of incoming and outgoing funds in the month.
+func (x *MonthTotal) Net() int64 {
+	return x.Incoming + x.Outgoing
+}
+
+// SavingsRate returns the percentage of incoming funds that were not spent in the month.
+// SavingsRate returns 0 if there were no incoming funds.
+func (x *MonthTotal) SavingsRate() float64 {
+	return percent(x.Net(), x.Incoming)
+}
+
+// MonthlySummary contains the totals of a collection of transactions grouped by calendar month.
+type MonthlySummary struct {
+	// Opening is the balance before the first month.
+	Opening int64
+	// Months contains the totals for each month in order, including months without any transactions.
+	Months []*MonthTotal
+	// Incoming is the total of all incoming transactions in the months.
+	Incoming int64
+	// Outgoing is the total of all outgoing transactions in the months.
+	Outgoing int64
+}
+
+// Net returns the sum of incoming and outgoing funds in the months.
+func (x *MonthlySummary) Net() int64 {
+	return x.Incoming + x.Outgoing
+}
+
+// SavingsRate returns the percentage of incoming funds that were not spent in the months.
+func (x *MonthlySummary) SavingsRate() float64 {
+	return percent(x.Net(), x.Incoming)
+}
+
+// Closing returns the running balance at the end of the last month.
+func (x *MonthlySummary) Closing() int64 {
+	if len(x.Months) == 0 {
+		return x.Opening
+	}
+	return x.Months[len(x.Months)-1].Balance
+}
+
+// MonthlySummary returns the totals of the transactions in the collection for each calendar month in the given
+// date range. The date range must have both a start and an end.
+// The first month always starts on the first day of the month, but transactions after the end of the date range
+// are ignored, so the last month is partial if the date range ends part way through it.
+// Transfers between accounts are ignored in the incoming and outgoing totals, but are included in the running
+// balance, which starts from the sum of all transactions before the first month.
+// Earlier transactions in the collection are used to compare each month with the month before and the same
+// month in the previous year. Months within the date range are always compared, even if they have no transactions.
+func (x *TransactionCollection) MonthlySummary(dateRange DateRange) *MonthlySummary {
+	first := monthStart(dateRange.From)
+	last := monthStart(dateRange.To)
+
+	res := &MonthlySummary{
+		Months: make([]*MonthTotal, 0),
+	}
+
+	totals := make(map[time.Time]*MonthTotal)
+	total := func(month time.Time) *MonthTotal {
+		t, ok := totals[month]
+		if !ok {
+			t = &MonthTotal{Month: month}
+			totals[month] = t
+		}
+		return t
+	}
+
+	var earliest time.Time
+	changes := make(map[time.Time]int64)
+	_ = x.Range(nil, func(t *Transaction) error {
+		if !t.Date.Before(dateRange.End()) {
+			return nil
+		}
+		month := monthStart(t.Date)
+		if earliest.IsZero() || month.Before(earliest) {
+			earliest = month
+		}
+		if month.Before(first) {
+			res.Opening += t.Amount
+		} else {
+			changes[month] += t.Amount
+		}
+		if t.IsTransfer() {
+			return nil
+		}
+		m := total(month)
+		m.Count++
+		if t.Amount > 0 {
+			m.Incoming += t.Amount
+		} else {
+			m.Outgoing += t.Amount
+		}
+		return nil
+	})
+
+	compare := func(month time.Time) *MonthTotal {
+		if month.Before(first) && (earliest.IsZero() || month.Before(earliest)) {
+			return nil
+		}
+		return total(month)
+	}
+
+	balance := res.Opening
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		m := total(month)
+		m.LastDay = month.AddDate(0, 1, -1)
+		if m.LastDay.After(dateRange.To) {
+			m.LastDay = TruncateDay(dateRange.To)
+		}
+		balance += changes[month]
+		m.Balance = balance
+		m.PreviousMonth = compare(month.AddDate(0, -1, 0))
+		m.LastYear = compare(month.AddDate(-1, 0, 0))
+		res.Months = append(res.Months, m)
+		res.Incoming += m.Incoming
+		res.Outgoing += m.Outgoing
+	}
+	return res
+}
+
+// monthStart returns midnight UTC on the first day of the month of t.
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
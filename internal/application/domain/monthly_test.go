@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+)
+
+func TestTransactionCollection_MonthlySummary(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(200000).WithDate(date(2018, 3, 1)),
+		domain.NewTransaction().WithAmount(-150000).WithDate(date(2018, 3, 10)),
+		domain.NewTransaction().WithAmount(-10000).WithDate(date(2019, 1, 20)),
+		domain.NewTransaction().WithAmount(250000).WithDate(date(2019, 2, 28)),
+		domain.NewTransaction().WithAmount(-50000).WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithAmount(200000).WithDate(date(2019, 3, 29)),
+		// Transfers only affect the balance.
+		domain.NewTransaction().WithAmount(-30000).WithDate(date(2019, 3, 5)).WithTransferID("trf:1"),
+		domain.NewTransaction().WithAmount(20000).WithDate(date(2019, 3, 5)).WithTransferID("trf:1"),
+		// After the range.
+		domain.NewTransaction().WithAmount(-5000).WithDate(date(2019, 4, 1)),
+	)
+
+	s := c.MonthlySummary(domain.DateRange{From: date(2019, 2, 14), To: date(2019, 3, 31)})
+
+	if s.Opening != 40000 {
+		t.Errorf("expected opening balance of 40000, got %d", s.Opening)
+	}
+	if len(s.Months) != 2 {
+		t.Fatalf("expected 2 months, got %d", len(s.Months))
+	}
+
+	feb, mar := s.Months[0], s.Months[1]
+	if !feb.Month.Equal(date(2019, 2, 1)) || feb.Incoming != 250000 || feb.Outgoing != 0 || feb.Balance != 290000 {
+		t.Errorf("unexpected totals for february: %+v", feb)
+	}
+	if mar.Count != 2 || mar.Incoming != 200000 || mar.Outgoing != -50000 || mar.Balance != 430000 {
+		t.Errorf("unexpected totals for march: %+v", mar)
+	}
+	if feb.Partial() || mar.Partial() {
+		t.Errorf("expected whole months, got february to %s and march to %s",
+			feb.LastDay.Format(domain.DateFormat), mar.LastDay.Format(domain.DateFormat))
+	}
+	if got := mar.SavingsRate(); got != 75 {
+		t.Errorf("expected savings rate of 75, got %f", got)
+	}
+
+	if feb.PreviousMonth == nil || feb.PreviousMonth.Net() != -10000 {
+		t.Errorf("expected february to be compared with january, got %+v", feb.PreviousMonth)
+	}
+	if mar.PreviousMonth != feb {
+		t.Errorf("expected march to be compared with february, got %+v", mar.PreviousMonth)
+	}
+	if feb.LastYear != nil {
+		t.Errorf("expected no comparison before the first transaction, got %+v", feb.LastYear)
+	}
+	if mar.LastYear == nil || mar.LastYear.Net() != 50000 {
+		t.Errorf("expected march to be compared with march last year, got %+v", mar.LastYear)
+	}
+
+	if s.Net() != 400000 || s.Closing() != 430000 {
+		t.Errorf("expected net of 400000 and closing balance of 430000, got %d and %d", s.Net(), s.Closing())
+	}
+
+	// The last month stops at the end of the date range.
+	s = c.MonthlySummary(domain.DateRange{From: date(2019, 2, 14), To: date(2019, 3, 2)})
+	mar = s.Months[1]
+	if !mar.Partial() || !mar.LastDay.Equal(date(2019, 3, 2)) {
+		t.Errorf("expected march to be partial up to 2019-03-02, got %s", mar.LastDay.Format(domain.DateFormat))
+	}
+	if mar.Count != 1 || mar.Incoming != 0 || mar.Outgoing != -50000 || mar.Balance != 240000 {
+		t.Errorf("expected march to only include transactions up to 2019-03-02, got %+v", mar)
+	}
+
+	// Months within the range are compared even if they are before the first transaction.
+	s = c.MonthlySummary(domain.DateRange{From: date(2017, 2, 1), To: date(2018, 3, 31)})
+	if len(s.Months) != 14 {
+		t.Fatalf("expected 14 months, got %d", len(s.Months))
+	}
+	if s.Months[0].PreviousMonth != nil || s.Months[0].LastYear != nil {
+		t.Errorf("expected no comparison outside the range before the first transaction, got %+v", s.Months[0])
+	}
+	if s.Months[1].PreviousMonth != s.Months[0] {
+		t.Errorf("expected march 2017 to be compared with february 2017, got %+v", s.Months[1].PreviousMonth)
+	}
+	mar = s.Months[13]
+	if mar.PreviousMonth != s.Months[12] || mar.LastYear != s.Months[1] || mar.LastYear.Net() != 0 {
+		t.Errorf("expected march 2018 to be compared with the empty months in the range, got %+v and %+v",
+			mar.PreviousMonth, mar.LastYear)
+	}
+}
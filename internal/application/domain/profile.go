@@ -2,14 +2,19 @@ package domain
 
 // Profile represents a single profile, for which we can add transactions.
 type Profile struct {
-	ID           string
-	Name         string
+	ID       string
+	Name     string
+	Currency Currency
+	// TagCase defines how the case of tags is normalised within the profile.
+	TagCase      TagCase
 	Transactions *TransactionCollection
 }
 
 // NewProfile returns a new profile.
 func NewProfile() *Profile {
 	p := new(Profile)
+	p.Currency = DefaultCurrency
+	p.TagCase = TagCaseLower
 	p.Transactions = NewTransactionCollection()
 	return p
 }
@@ -0,0 +1,517 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QueryField is a property of a transaction that can be compared in a query.
+type QueryField string
+
+const (
+	// QueryFieldTag compares against each of the tags of a transaction.
+	QueryFieldTag QueryField = "tag"
+	// QueryFieldLabel compares against the label of a transaction, ignoring the case of ASCII letters.
+	QueryFieldLabel QueryField = "label"
+	// QueryFieldAmount compares against the signed amount of a transaction in the minor unit of its currency.
+	QueryFieldAmount QueryField = "amount"
+	// QueryFieldCurrency compares against the currency of a transaction.
+	QueryFieldCurrency QueryField = "currency"
+	// QueryFieldDate compares against the day a transaction took place.
+	QueryFieldDate QueryField = "date"
+	// QueryFieldAccount compares against the ID of the account a transaction belongs to.
+	QueryFieldAccount QueryField = "account"
+)
+
+// QueryOperator compares a field to a value.
+type QueryOperator string
+
+const (
+	// QueryEqual matches if the field is equal to the value.
+	// For tags it matches if the transaction has the tag or one of its descendants.
+	QueryEqual QueryOperator = "="
+	// QueryNotEqual matches if the field is not equal to the value.
+	// For tags it matches if the transaction has neither the tag nor any of its descendants.
+	QueryNotEqual QueryOperator = "!="
+	// QueryLess matches if the field is less than the value.
+	QueryLess QueryOperator = "<"
+	// QueryLessOrEqual matches if the field is less than or equal to the value.
+	QueryLessOrEqual QueryOperator = "<="
+	// QueryGreater matches if the field is greater than the value.
+	QueryGreater QueryOperator = ">"
+	// QueryGreaterOrEqual matches if the field is greater than or equal to the value.
+	QueryGreaterOrEqual QueryOperator = ">="
+	// QueryContains matches if the field contains the value, ignoring the case of ASCII letters.
+	QueryContains QueryOperator = "~"
+	// QueryNotContains matches if the field does not contain the value, ignoring the case of ASCII letters.
+	QueryNotContains QueryOperator = "!~"
+)
+
+// queryFieldOperators contains the operators that can be used with each field.
+var queryFieldOperators = map[QueryField][]QueryOperator{
+	QueryFieldTag:      {QueryEqual, QueryNotEqual},
+	QueryFieldLabel:    {QueryEqual, QueryNotEqual, QueryContains, QueryNotContains},
+	QueryFieldAmount:   {QueryEqual, QueryNotEqual, QueryLess, QueryLessOrEqual, QueryGreater, QueryGreaterOrEqual},
+	QueryFieldCurrency: {QueryEqual, QueryNotEqual},
+	QueryFieldDate:     {QueryEqual, QueryNotEqual, QueryLess, QueryLessOrEqual, QueryGreater, QueryGreaterOrEqual},
+	QueryFieldAccount:  {QueryEqual, QueryNotEqual},
+}
+
+// queryFields contains the fields in the order they are listed in error messages.
+var queryFields = []QueryField{
+	QueryFieldTag,
+	QueryFieldLabel,
+	QueryFieldAmount,
+	QueryFieldCurrency,
+	QueryFieldDate,
+	QueryFieldAccount,
+}
+
+// QueryExpr is a node in a parsed query.
+// It is one of *QueryAnd, *QueryOr, *QueryNot or *QueryComparison.
+type QueryExpr interface {
+	// Matches returns true if the given transaction matches the expression.
+	Matches(t *Transaction) bool
+}
+
+// QueryAnd matches transactions that match both Left and Right.
+type QueryAnd struct {
+	Left  QueryExpr
+	Right QueryExpr
+}
+
+// Matches implements QueryExpr.
+func (x *QueryAnd) Matches(t *Transaction) bool {
+	return x.Left.Matches(t) && x.Right.Matches(t)
+}
+
+// QueryOr matches transactions that match either Left or Right.
+type QueryOr struct {
+	Left  QueryExpr
+	Right QueryExpr
+}
+
+// Matches implements QueryExpr.
+func (x *QueryOr) Matches(t *Transaction) bool {
+	return x.Left.Matches(t) || x.Right.Matches(t)
+}
+
+// QueryNot matches transactions that do not match Expr.
+type QueryNot struct {
+	Expr QueryExpr
+}
+
+// Matches implements QueryExpr.
+func (x *QueryNot) Matches(t *Transaction) bool {
+	return !x.Expr.Matches(t)
+}
+
+// QueryComparison compares a field of a transaction to a value.
+type QueryComparison struct {
+	Field    QueryField
+	Operator QueryOperator
+	// Value is the value as written in the query, used for the tag, label, currency and account fields.
+	Value string
+	// Amount is the parsed value of amount comparisons.
+	Amount int64
+	// Date is the parsed value of date comparisons.
+	Date time.Time
+}
+
+// Matches implements QueryExpr.
+func (x *QueryComparison) Matches(t *Transaction) bool {
+	switch x.Field {
+	case QueryFieldTag:
+		return hasTagWithin(t, x.Value) == (x.Operator == QueryEqual)
+	case QueryFieldLabel:
+		label, value := lowerASCII(t.Label), lowerASCII(x.Value)
+		switch x.Operator {
+		case QueryEqual:
+			return label == value
+		case QueryNotEqual:
+			return label != value
+		case QueryContains:
+			return strings.Contains(label, value)
+		case QueryNotContains:
+			return !strings.Contains(label, value)
+		}
+	case QueryFieldAmount:
+		return compareQueryValue(x.Operator, compareInt64(t.Amount, x.Amount))
+	case QueryFieldCurrency:
+		return (t.Currency == Currency(x.Value)) == (x.Operator == QueryEqual)
+	case QueryFieldDate:
+		return compareQueryValue(x.Operator, compareInt64(TruncateDay(t.Date).Unix(), x.Date.Unix()))
+	case QueryFieldAccount:
+		return (t.AccountID == x.Value) == (x.Operator == QueryEqual)
+	}
+	return false
+}
+
+// compareInt64 returns -1, 0 or 1 if a is less than, equal to or greater than b.
+func compareInt64(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareQueryValue returns true if the result of comparing a field to a value satisfies the given operator.
+func compareQueryValue(operator QueryOperator, cmp int) bool {
+	switch operator {
+	case QueryEqual:
+		return cmp == 0
+	case QueryNotEqual:
+		return cmp != 0
+	case QueryLess:
+		return cmp < 0
+	case QueryLessOrEqual:
+		return cmp <= 0
+	case QueryGreater:
+		return cmp > 0
+	case QueryGreaterOrEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+// Query is a parsed transaction search, such as `tag:travel and amount < -5000 and label ~ "train"`.
+type Query struct {
+	// Source is the query as it was written.
+	Source string
+	// Expr is the root of the parsed query.
+	Expr QueryExpr
+}
+
+// Matches returns true if the given transaction matches the query.
+// Matches can be used with TransactionCollection.Subset.
+func (x *Query) Matches(t *Transaction) bool {
+	return x.Expr.Matches(t)
+}
+
+// String returns the query as it was written.
+func (x *Query) String() string {
+	return x.Source
+}
+
+// WithNormalisedTags returns a copy of the query with the value of each tag comparison normalised with
+// NormaliseTag, so they match the tags stored in a profile with the given tag case.
+func (x *Query) WithNormalisedTags(tagCase TagCase) *Query {
+	return &Query{Source: x.Source, Expr: normaliseQueryTags(x.Expr, tagCase)}
+}
+
+// normaliseQueryTags returns a copy of the given expression with the value of each tag comparison normalised.
+func normaliseQueryTags(expr QueryExpr, tagCase TagCase) QueryExpr {
+	switch e := expr.(type) {
+	case *QueryAnd:
+		return &QueryAnd{Left: normaliseQueryTags(e.Left, tagCase), Right: normaliseQueryTags(e.Right, tagCase)}
+	case *QueryOr:
+		return &QueryOr{Left: normaliseQueryTags(e.Left, tagCase), Right: normaliseQueryTags(e.Right, tagCase)}
+	case *QueryNot:
+		return &QueryNot{Expr: normaliseQueryTags(e.Expr, tagCase)}
+	case *QueryComparison:
+		c := *e
+		if c.Field == QueryFieldTag {
+			c.Value = NormaliseTag(c.Value, tagCase)
+		}
+		return &c
+	}
+	return expr
+}
+
+// QueryError is returned when a query cannot be parsed.
+type QueryError struct {
+	// Position is the position of the offending token in the query, starting at 1.
+	Position int
+	// Token is the offending token, or empty at the end of the query.
+	Token string
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements error.
+func (x *QueryError) Error() string {
+	if x.Token == "" {
+		return fmt.Sprintf("%s at end of query", x.Message)
+	}
+	return fmt.Sprintf("%s at position %d: `%s`", x.Message, x.Position, x.Token)
+}
+
+// queryTokenKind is the type of a token in a query.
+type queryTokenKind int
+
+const (
+	queryTokenEOF queryTokenKind = iota
+	// queryTokenWord is an unquoted word, such as a field name, keyword or value.
+	queryTokenWord
+	// queryTokenString is a double quoted value.
+	queryTokenString
+	queryTokenOperator
+	queryTokenOpen
+	queryTokenClose
+)
+
+// queryToken is a single token in a query.
+type queryToken struct {
+	kind queryTokenKind
+	// text is the token as written in the query.
+	text string
+	// value is the unquoted value of strings, and the text of other tokens.
+	value string
+	// position is the position of the token in the query, starting at 1.
+	position int
+}
+
+// isKeyword returns true if the token is the given keyword, ignoring case.
+func (x queryToken) isKeyword(keyword string) bool {
+	return x.kind == queryTokenWord && strings.EqualFold(x.text, keyword)
+}
+
+// queryOperatorRunes contains the characters that make up operators.
+const queryOperatorRunes = ":=!<>~"
+
+// lexQuery splits the given query into tokens.
+func lexQuery(source string) ([]queryToken, error) {
+	runes := []rune(source)
+	tokens := make([]queryToken, 0)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: queryTokenOpen, text: "(", value: "(", position: start + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: queryTokenClose, text: ")", value: ")", position: start + 1})
+			i++
+		case r == '"':
+			var value strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				value.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &QueryError{Position: start + 1, Token: string(runes[start:]), Message: "unterminated string"}
+			}
+			tokens = append(tokens, queryToken{kind: queryTokenString, text: string(runes[start:i]), value: value.String(), position: start + 1})
+		case strings.ContainsRune(queryOperatorRunes, r):
+			for i < len(runes) && strings.ContainsRune(queryOperatorRunes, runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			tokens = append(tokens, queryToken{kind: queryTokenOperator, text: text, value: text, position: start + 1})
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(queryOperatorRunes+`()"`, runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			tokens = append(tokens, queryToken{kind: queryTokenWord, text: text, value: text, position: start + 1})
+		}
+	}
+	tokens = append(tokens, queryToken{kind: queryTokenEOF, position: len(runes) + 1})
+	return tokens, nil
+}
+
+// queryParser parses a list of tokens into a QueryExpr.
+//
+// The grammar, from lowest to highest precedence, is:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | primary
+//	primary    = "(" or ")" | comparison
+//	comparison = field operator value
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+// ParseQuery parses the given query.
+// A *QueryError describing the offending token is returned if the query is not valid.
+func ParseQuery(source string) (*Query, error) {
+	tokens, err := lexQuery(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	if p.peek().kind == queryTokenEOF {
+		return nil, p.errorf(p.peek(), "empty query")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if next := p.peek(); next.kind != queryTokenEOF {
+		return nil, p.errorf(next, "expected and, or or the end of the query")
+	}
+	return &Query{Source: source, Expr: expr}, nil
+}
+
+// peek returns the next token without consuming it.
+func (x *queryParser) peek() queryToken {
+	return x.tokens[x.pos]
+}
+
+// next consumes and returns the next token.
+func (x *queryParser) next() queryToken {
+	t := x.tokens[x.pos]
+	if t.kind != queryTokenEOF {
+		x.pos++
+	}
+	return t
+}
+
+// errorf returns a *QueryError for the given token.
+func (x *queryParser) errorf(t queryToken, format string, args ...interface{}) error {
+	return &QueryError{Position: t.position, Token: t.text, Message: fmt.Sprintf(format, args...)}
+}
+
+func (x *queryParser) parseOr() (QueryExpr, error) {
+	left, err := x.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for x.peek().isKeyword("or") {
+		x.next()
+		right, err := x.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &QueryOr{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (x *queryParser) parseAnd() (QueryExpr, error) {
+	left, err := x.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for x.peek().isKeyword("and") {
+		x.next()
+		right, err := x.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &QueryAnd{Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (x *queryParser) parseUnary() (QueryExpr, error) {
+	if x.peek().isKeyword("not") {
+		x.next()
+		expr, err := x.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &QueryNot{Expr: expr}, nil
+	}
+	return x.parsePrimary()
+}
+
+func (x *queryParser) parsePrimary() (QueryExpr, error) {
+	if x.peek().kind == queryTokenOpen {
+		open := x.next()
+		expr, err := x.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if x.peek().kind != queryTokenClose {
+			if x.peek().kind == queryTokenEOF {
+				return nil, x.errorf(open, "unclosed parenthesis")
+			}
+			return nil, x.errorf(x.peek(), "expected )")
+		}
+		x.next()
+		return expr, nil
+	}
+	return x.parseComparison()
+}
+
+func (x *queryParser) parseComparison() (QueryExpr, error) {
+	fieldToken := x.next()
+	if fieldToken.kind != queryTokenWord || fieldToken.isKeyword("and") || fieldToken.isKeyword("or") {
+		return nil, x.errorf(fieldToken, "expected a field name")
+	}
+	field := QueryField(strings.ToLower(fieldToken.text))
+	operators, ok := queryFieldOperators[field]
+	if !ok {
+		names := make([]string, len(queryFields))
+		for i, f := range queryFields {
+			names[i] = string(f)
+		}
+		return nil, x.errorf(fieldToken, "unknown field: expected one of %s", strings.Join(names, ", "))
+	}
+
+	operatorToken := x.next()
+	if operatorToken.kind != queryTokenOperator {
+		return nil, x.errorf(operatorToken, "expected an operator after %s", field)
+	}
+	operator := QueryOperator(operatorToken.text)
+	if operator == ":" {
+		operator = QueryEqual
+	}
+	valid := false
+	for _, o := range operators {
+		if o == operator {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		names := make([]string, len(operators))
+		for i, o := range operators {
+			names[i] = string(o)
+		}
+		return nil, x.errorf(operatorToken, "invalid operator for %s: expected one of %s", field, strings.Join(names, " "))
+	}
+
+	valueToken := x.next()
+	if valueToken.kind != queryTokenWord && valueToken.kind != queryTokenString {
+		return nil, x.errorf(valueToken, "expected a value after %s %s", field, operator)
+	}
+	res := &QueryComparison{Field: field, Operator: operator, Value: valueToken.value}
+	switch field {
+	case QueryFieldAmount:
+		amount, err := strconv.ParseInt(valueToken.value, 10, 64)
+		if err != nil {
+			return nil, x.errorf(valueToken, "invalid amount: expected a whole number in the minor unit of the currency, such as -5000")
+		}
+		res.Amount = amount
+	case QueryFieldDate:
+		date, err := ParseDate(valueToken.value)
+		if err != nil {
+			return nil, x.errorf(valueToken, "invalid date: expected format %s", DateFormat)
+		}
+		res.Date = date
+	case QueryFieldCurrency:
+		currency, ok := ParseCurrency(valueToken.value)
+		if !ok {
+			return nil, x.errorf(valueToken, "unknown currency")
+		}
+		res.Value = string(currency)
+	}
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		exp   []string
+	}{
+		{query: `tag:travel`, exp: []string{"tra:1", "tra:3", "tra:4"}},
+		{query: `TAG = travel AND amount < -5000`, exp: []string{"tra:1"}},
+		{query: `tag:travel and amount < -5000 and label ~ "train"`, exp: []string{"tra:1"}},
+		{query: `tag != travel`, exp: []string{"tra:2", "tra:5"}},
+		{query: `label = "bus"`, exp: []string{"tra:3"}},
+		{query: `label !~ "a"`, exp: []string{"tra:3", "tra:5"}},
+		{query: `amount >= 0 or currency = jpy`, exp: []string{"tra:2", "tra:4"}},
+		{query: `not tag:travel and amount<0`, exp: []string{"tra:5"}},
+		{query: `not (tag:travel and amount<0)`, exp: []string{"tra:2", "tra:5"}},
+		{query: `tag:food or tag:commute and date > 2019-03-01`, exp: []string{"tra:4", "tra:5"}},
+		{query: `(tag:food or tag:commute) and date <= 2019-03-03`, exp: []string{"tra:1", "tra:4"}},
+		{query: `date = 2019-03-03`, exp: []string{"tra:3", "tra:4"}},
+		{query: `date != 2019-03-03 and date >= 2019-03-02 and date < 2019-03-04`, exp: []string{"tra:2"}},
+		{query: `account = "acc:1"`, exp: []string{"tra:5"}},
+		{query: `label ~ "say \"hi\""`, exp: []string{}},
+	}
+
+	for _, tc := range tests {
+		query, err := domain.ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.query, err)
+			continue
+		}
+		got := ids(filterTestTransactions().Subset(query.Matches))
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("%s: expected %v, got %v", tc.query, tc.exp, got)
+		}
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query string
+		exp   string
+	}{
+		{query: ``, exp: "empty query at end of query"},
+		{query: `amout < 5`, exp: "unknown field: expected one of tag, label, amount, currency, date, account at position 1: `amout`"},
+		{query: `tag:travel and amount ~ 5`, exp: "invalid operator for amount: expected one of = != < <= > >= at position 23: `~`"},
+		{query: `tag:travel and amount < 50.00`, exp: "invalid amount: expected a whole number in the minor unit of the currency, such as -5000 at position 25: `50.00`"},
+		{query: `date > 01/03/2019`, exp: "invalid date: expected format 2006-01-02 at position 8: `01/03/2019`"},
+		{query: `currency = XYZ`, exp: "unknown currency at position 12: `XYZ`"},
+		{query: `tag:travel tag:food`, exp: "expected and, or or the end of the query at position 12: `tag`"},
+		{query: `tag:travel and`, exp: "expected a field name at end of query"},
+		{query: `label ~ "train`, exp: "unterminated string at position 9: `\"train`"},
+		{query: `(tag:travel or tag:food`, exp: "unclosed parenthesis at position 1: `(`"},
+		{query: `tag travel`, exp: "expected an operator after tag at position 5: `travel`"},
+		{query: `tag =`, exp: "expected a value after tag = at end of query"},
+		{query: `account = acc:1`, exp: "expected and, or or the end of the query at position 14: `:`"},
+		{query: `tag:travel)`, exp: "expected and, or or the end of the query at position 11: `)`"},
+	}
+
+	for _, tc := range tests {
+		_, err := domain.ParseQuery(tc.query)
+		if err == nil {
+			t.Errorf("%s: expected error", tc.query)
+			continue
+		}
+		if _, ok := err.(*domain.QueryError); !ok {
+			t.Errorf("%s: expected *domain.QueryError, got %T", tc.query, err)
+		}
+		if got := err.Error(); got != tc.exp {
+			t.Errorf("%s: expected error:\n%s\ngot:\n%s", tc.query, tc.exp, got)
+		}
+	}
+}
+
+func TestQuery_Matches_TagDescendants(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithID("tra:1").WithTags("transport"),
+		domain.NewTransaction().WithID("tra:2").WithTags("transport/rail", "travel/uk"),
+		domain.NewTransaction().WithID("tra:3").WithTags("transportation"),
+	)
+
+	tests := []struct {
+		query string
+		exp   []string
+	}{
+		{query: `tag:transport`, exp: []string{"tra:1", "tra:2"}},
+		{query: `tag = transport/rail`, exp: []string{"tra:2"}},
+		{query: `tag:travel and tag:transport`, exp: []string{"tra:2"}},
+		{query: `tag != transport`, exp: []string{"tra:3"}},
+	}
+	for _, tc := range tests {
+		query, err := domain.ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.query, err)
+			continue
+		}
+		got := ids(c.Subset(query.Matches))
+		if !reflect.DeepEqual(tc.exp, got) {
+			t.Errorf("%s: expected %v, got %v", tc.query, tc.exp, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"time"
+)
+
+// Frequency defines how often a Schedule repeats.
+type Frequency string
+
+const (
+	// FrequencyDaily repeats every Interval days.
+	FrequencyDaily Frequency = "daily"
+	// FrequencyWeekly repeats every Interval weeks, on the same weekday as the start date.
+	FrequencyWeekly Frequency = "weekly"
+	// FrequencyMonthly repeats every Interval months, on DayOfMonth.
+	// If the month is shorter than DayOfMonth the last day of the month is used.
+	FrequencyMonthly Frequency = "monthly"
+	// FrequencyLastWorkingDay repeats every Interval months, on the last weekday of the month.
+	FrequencyLastWorkingDay Frequency = "last-working-day"
+	// FrequencyYearly repeats every Interval years, on the same day as the start date.
+	FrequencyYearly Frequency = "yearly"
+)
+
+// Frequencies contains all of the valid frequencies.
+var Frequencies = []Frequency{
+	FrequencyDaily,
+	FrequencyWeekly,
+	FrequencyMonthly,
+	FrequencyLastWorkingDay,
+	FrequencyYearly,
+}
+
+// Valid returns true if the frequency is known.
+func (x Frequency) Valid() bool {
+	for _, f := range Frequencies {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSchedule returns a new Schedule.
+func NewSchedule() *Schedule {
+	return &Schedule{
+		Tags:     []string{},
+		Interval: 1,
+	}
+}
+
+// Schedule represents a transaction that repeats on a regular basis.
+type Schedule struct {
+	// ID is a unique identifier.
+	ID string
+	// ProfileID is the identifier for the profile the schedule belongs to.
+	ProfileID string
+	// Label is a label for the transactions.
+	Label string
+	// Amount is the amount of funds transferred by each transaction, in the minor unit of Currency.
+	Amount int64
+	// Currency is the currency of Amount.
+	Currency Currency
+	// Tags contains a set of tags that each transaction can be grouped by.
+	Tags []string
+	// Frequency defines how often the schedule repeats.
+	Frequency Frequency
+	// Interval is the number of days, weeks, months or years between each transaction.
+	Interval int
+	// DayOfMonth is the day of the month that monthly schedules repeat on.
+	DayOfMonth int
+	// Start is the first day the schedule can repeat on.
+	Start time.Time
+	// End is the last day the schedule can repeat on.
+	// A zero End means the schedule repeats forever.
+	End time.Time
+	// Paused is true if the schedule should not currently repeat.
+	Paused bool
+	// CreatedAt is the time at which the schedule was created.
+	CreatedAt time.Time
+	// UpdatedAt is the time at which the schedule was last updated.
+	UpdatedAt time.Time
+}
+
+// Occurrences returns the days within the given range on which the schedule repeats.
+// The given range must have an end date.
+func (x *Schedule) Occurrences(dateRange DateRange) []time.Time {
+	res := make([]time.Time, 0)
+	if x.Paused || dateRange.To.IsZero() || x.Interval < 1 {
+		return res
+	}
+
+	start := TruncateDay(x.Start)
+	end := dateRange.End()
+	if !x.End.IsZero() {
+		if scheduleEnd := (DateRange{To: x.End}).End(); scheduleEnd.Before(end) {
+			end = scheduleEnd
+		}
+	}
+
+	for n := 0; ; n++ {
+		day := x.occurrence(start, n)
+		if !day.Before(end) {
+			break
+		}
+		if day.Before(start) {
+			continue
+		}
+		if dateRange.Contains(day) {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+// occurrence returns the day of the n-th repetition of the schedule.
+func (x *Schedule) occurrence(start time.Time, n int) time.Time {
+	switch x.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n*x.Interval)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, n*x.Interval*7)
+	case FrequencyMonthly:
+		year, month := addMonths(start, n*x.Interval)
+		day := x.DayOfMonth
+		if day < 1 {
+			day = start.Day()
+		}
+		if last := daysInMonth(year, month); day > last {
+			day = last
+		}
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	case FrequencyLastWorkingDay:
+		year, month := addMonths(start, n*x.Interval)
+		day := time.Date(year, month, daysInMonth(year, month), 0, 0, 0, 0, time.UTC)
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day
+	case FrequencyYearly:
+		year := start.Year() + n*x.Interval
+		day := start.Day()
+		if last := daysInMonth(year, start.Month()); day > last {
+			day = last
+		}
+		return time.Date(year, start.Month(), day, 0, 0, 0, 0, time.UTC)
+	default:
+		// Unknown frequencies never repeat.
+		return time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Project returns the transactions the schedule will create within the given range.
+// The transactions are not stored and have no ID.
+func (x *Schedule) Project(dateRange DateRange) *TransactionCollection {
+	c := NewTransactionCollection()
+	for _, day := range x.Occurrences(dateRange) {
+		tags := make([]string, len(x.Tags))
+		copy(tags, x.Tags)
+
+		t := NewTransaction().
+			WithProfileID(x.ProfileID).
+			WithLabel(x.Label).
+			WithAmount(x.Amount).
+			WithCurrency(x.Currency).
+			WithTags(tags...).
+			WithDate(day)
+		t.ScheduleID = x.ID
+		t.Projected = true
+		c.Add(t)
+	}
+	return c
+}
+
+// addMonths returns the year and month that is the given number of months after t.
+func addMonths(t time.Time, months int) (int, time.Month) {
+	total := int(t.Month()) - 1 + months
+	return t.Year() + total/12, time.Month(total%12 + 1)
+}
+
+// daysInMonth returns the number of days in the given month.
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,116 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Occurrences(t *testing.T) {
+	t.Parallel()
+
+	year := domain.DateRange{From: date(2019, 1, 1), To: date(2019, 12, 31)}
+
+	tests := []struct {
+		name      string
+		schedule  *domain.Schedule
+		dateRange domain.DateRange
+		exp       []time.Time
+	}{
+		{
+			name: "daily",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyDaily, Interval: 1, Start: date(2019, 1, 30),
+			},
+			dateRange: domain.DateRange{From: date(2019, 2, 1), To: date(2019, 2, 3)},
+			exp:       []time.Time{date(2019, 2, 1), date(2019, 2, 2), date(2019, 2, 3)},
+		},
+		{
+			name: "every 2 weeks",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyWeekly, Interval: 2, Start: date(2019, 1, 4),
+			},
+			dateRange: domain.DateRange{From: date(2019, 1, 1), To: date(2019, 2, 10)},
+			exp:       []time.Time{date(2019, 1, 4), date(2019, 1, 18), date(2019, 2, 1)},
+		},
+		{
+			name: "monthly on day 31",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyMonthly, Interval: 1, DayOfMonth: 31, Start: date(2019, 1, 1),
+			},
+			dateRange: domain.DateRange{From: date(2019, 1, 1), To: date(2019, 4, 30)},
+			exp:       []time.Time{date(2019, 1, 31), date(2019, 2, 28), date(2019, 3, 31), date(2019, 4, 30)},
+		},
+		{
+			name: "last working day",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyLastWorkingDay, Interval: 1, Start: date(2019, 3, 1),
+			},
+			dateRange: domain.DateRange{From: date(2019, 1, 1), To: date(2019, 6, 30)},
+			exp:       []time.Time{date(2019, 3, 29), date(2019, 4, 30), date(2019, 5, 31), date(2019, 6, 28)},
+		},
+		{
+			name: "yearly on leap day",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyYearly, Interval: 1, Start: date(2016, 2, 29),
+			},
+			dateRange: domain.DateRange{From: date(2019, 1, 1), To: date(2020, 12, 31)},
+			exp:       []time.Time{date(2019, 2, 28), date(2020, 2, 29)},
+		},
+		{
+			name: "ended",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyMonthly, Interval: 1, DayOfMonth: 1, Start: date(2019, 1, 1), End: date(2019, 2, 1),
+			},
+			dateRange: year,
+			exp:       []time.Time{date(2019, 1, 1), date(2019, 2, 1)},
+		},
+		{
+			name: "paused",
+			schedule: &domain.Schedule{
+				Frequency: domain.FrequencyDaily, Interval: 1, Start: date(2019, 1, 1), Paused: true,
+			},
+			dateRange: year,
+			exp:       []time.Time{},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.schedule.Occurrences(tc.dateRange)
+			if len(got) != len(tc.exp) {
+				t.Fatalf("expected %d occurrences, got %d: %v", len(tc.exp), len(got), got)
+			}
+			for i := range tc.exp {
+				if !tc.exp[i].Equal(got[i]) {
+					t.Errorf("expected occurrence [%d] to be %s, got %s", i, tc.exp[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSchedule_Project(t *testing.T) {
+	t.Parallel()
+
+	s := domain.NewSchedule()
+	s.ID = "sch:1"
+	s.ProfileID = "pro:1"
+	s.Label = "Rent"
+	s.Amount = -50000
+	s.Tags = []string{"home"}
+	s.Frequency = domain.FrequencyMonthly
+	s.DayOfMonth = 1
+	s.Start = date(2019, 1, 1)
+
+	c := s.Project(domain.DateRange{From: date(2019, 1, 1), To: date(2019, 3, 31)})
+	if exp, got := int64(-150000), c.Sum(); exp != got {
+		t.Errorf("expected sum %d, got %d", exp, got)
+	}
+	for _, tr := range c.All() {
+		if !tr.Projected || tr.ScheduleID != s.ID || tr.ProfileID != s.ProfileID {
+			t.Errorf("unexpected projected transaction: %+v", tr)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// TagSeparator separates the parts of a hierarchical tag, such as transport/rail.
+// A tag without a separator is a top-level tag.
+const TagSeparator = "/"
+
+// TagParts returns the parts of the given tag, such as transport and rail for transport/rail.
+func TagParts(tag string) []string {
+	return strings.Split(tag, TagSeparator)
+}
+
+// TagAncestors returns the given tag preceded by each of its ancestors, starting with the top-level tag.
+// For example, transport/rail/tube returns transport, transport/rail and transport/rail/tube.
+func TagAncestors(tag string) []string {
+	parts := TagParts(tag)
+	res := make([]string, len(parts))
+	for i := range parts {
+		res[i] = strings.Join(parts[:i+1], TagSeparator)
+	}
+	return res
+}
+
+// TagWithin returns true if the given tag is root, or one of its descendants.
+func TagWithin(tag string, root string) bool {
+	return tag == root || strings.HasPrefix(tag, root+TagSeparator)
+}
+
+// TruncateTag returns the ancestor of the given tag at the given depth, where top-level tags are at depth 1.
+// The tag is returned unchanged if depth is 0 or the tag is not that deep.
+func TruncateTag(tag string, depth int) string {
+	if depth <= 0 {
+		return tag
+	}
+	parts := TagParts(tag)
+	if len(parts) <= depth {
+		return tag
+	}
+	return strings.Join(parts[:depth], TagSeparator)
+}
+
+// RenameTag moves the given tag from within one tag to another, keeping the rest of its path.
+// For example, renaming transport/rail from transport to travel returns travel/rail.
+// False is returned if the tag is not within from.
+func RenameTag(tag string, from string, to string) (string, bool) {
+	if !TagWithin(tag, from) {
+		return tag, false
+	}
+	return to + tag[len(from):], true
+}
+
+// TagCase defines how the case of tags is normalised.
+type TagCase string
+
+const (
+	// TagCaseLower converts tags to lower case, so Travel and travel are the same tag.
+	TagCaseLower TagCase = "lower"
+	// TagCasePreserve keeps the case of tags as they are given.
+	TagCasePreserve TagCase = "preserve"
+)
+
+// Valid returns true if the tag case is known.
+func (x TagCase) Valid() bool {
+	return x == TagCaseLower || x == TagCasePreserve
+}
+
+// NormaliseTag returns the given tag with the whitespace around each of its parts removed, runs of whitespace
+// within each part replaced by a single space, and its case converted according to tagCase.
+// For example, " Transport / Rail " becomes transport/rail when converted to lower case.
+func NormaliseTag(tag string, tagCase TagCase) string {
+	parts := TagParts(tag)
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	tag = strings.Join(parts, TagSeparator)
+	if tagCase == TagCaseLower {
+		tag = strings.ToLower(tag)
+	}
+	return tag
+}
+
+// NormaliseTags returns the given tags normalised with NormaliseTag, with any duplicates removed.
+func NormaliseTags(tags []string, tagCase TagCase) []string {
+	res := make([]string, len(tags))
+	for i, t := range tags {
+		res[i] = NormaliseTag(t, tagCase)
+	}
+	return uniqueTags(res)
+}
+
+// TagUsage contains the number of objects in a profile that use a tag.
+type TagUsage struct {
+	// Tag is the tag that is used.
+	Tag string
+	// Transactions is the number of transactions with the tag.
+	Transactions int
+	// Schedules is the number of schedules with the tag.
+	Schedules int
+	// TagRules is the number of tag rules that add the tag.
+	TagRules int
+}
+
+// TagSplit defines how a transaction with multiple tags is counted when grouping by tag.
+type TagSplit string
+
+const (
+	// TagSplitEach counts the full amount of the transaction in each of its tags.
+	TagSplitEach TagSplit = "each"
+	// TagSplitEven splits the amount of the transaction evenly between each of its tags.
+	TagSplitEven TagSplit = "even"
+)
+
+// Valid returns true if the tag split is known.
+func (x TagSplit) Valid() bool {
+	return x == TagSplitEach || x == TagSplitEven
+}
+
+// TagTotal contains the totals of the transactions with a single tag.
+type TagTotal struct {
+	// Tag is the tag the totals belong to.
+	// Tag is empty for transactions without any tags.
+	Tag string
+	// Count is the number of transactions with the tag.
+	Count int
+	// Incoming is the total of all incoming transactions with the tag.
+	Incoming int64
+	// Outgoing is the total of all outgoing transactions with the tag.
+	Outgoing int64
+	// IncomingPercent is the percentage of all incoming funds that Incoming accounts for.
+	IncomingPercent float64
+	// OutgoingPercent is the percentage of all outgoing funds that Outgoing accounts for.
+	OutgoingPercent float64
+}
+
+// Net returns the sum of incoming and outgoing funds with the tag.
+func (x *TagTotal) Net() int64 {
+	return x.Incoming + x.Outgoing
+}
+
+// TagBreakdown contains the totals of a collection of transactions grouped by tag.
+type TagBreakdown struct {
+	// Split is the method used to count transactions with multiple tags.
+	Split TagSplit
+	// Tags contains the totals for each tag, ordered by the most outgoing first.
+	Tags []*TagTotal
+	// Untagged contains the totals for transactions without any tags.
+	Untagged *TagTotal
+	// Incoming is the total of all incoming transactions.
+	Incoming int64
+	// Outgoing is the total of all outgoing transactions.
+	Outgoing int64
+}
+
+// GroupByTag returns the totals of the transactions in the collection grouped by tag.
+// Transactions with multiple tags are counted according to split, and transfers between accounts are ignored.
+func (x *TransactionCollection) GroupByTag(split TagSplit) *TagBreakdown {
+	return x.GroupByTagDepth(split, 0)
+}
+
+// GroupByTagDepth returns the totals of the transactions in the collection grouped by tag, with tags deeper
+// than the given depth rolled up into their ancestor at that depth. A depth of 0 groups by the full tag.
+// Transactions with multiple tags are counted according to split, and transfers between accounts are ignored.
+func (x *TransactionCollection) GroupByTagDepth(split TagSplit, depth int) *TagBreakdown {
+	res := &TagBreakdown{
+		Split:    split,
+		Tags:     make([]*TagTotal, 0),
+		Untagged: &TagTotal{},
+	}
+	byTag := make(map[string]*TagTotal)
+
+	add := func(total *TagTotal, amount int64) {
+		if amount > 0 {
+			total.Incoming += amount
+		} else {
+			total.Outgoing += amount
+		}
+	}
+
+	_ = x.ExcludeTransfers().Range(nil, func(t *Transaction) error {
+		if t.Amount > 0 {
+			res.Incoming += t.Amount
+		} else {
+			res.Outgoing += t.Amount
+		}
+
+		tags := make([]string, len(t.Tags))
+		for i, tag := range t.Tags {
+			tags[i] = TruncateTag(tag, depth)
+		}
+		tags = uniqueTags(tags)
+		if len(tags) == 0 {
+			res.Untagged.Count++
+			add(res.Untagged, t.Amount)
+			return nil
+		}
+
+		for i, tag := range tags {
+			total, ok := byTag[tag]
+			if !ok {
+				total = &TagTotal{Tag: tag}
+				byTag[tag] = total
+				res.Tags = append(res.Tags, total)
+			}
+			total.Count++
+
+			amount := t.Amount
+			if split == TagSplitEven {
+				amount = t.Amount / int64(len(tags))
+				if i == 0 {
+					// Give the remainder to the first tag so that nothing is lost.
+					amount += t.Amount % int64(len(tags))
+				}
+			}
+			add(total, amount)
+		}
+		return nil
+	})
+
+	for _, total := range res.Tags {
+		total.IncomingPercent = percent(total.Incoming, res.Incoming)
+		total.OutgoingPercent = percent(total.Outgoing, res.Outgoing)
+	}
+	res.Untagged.IncomingPercent = percent(res.Untagged.Incoming, res.Incoming)
+	res.Untagged.OutgoingPercent = percent(res.Untagged.Outgoing, res.Outgoing)
+
+	sort.SliceStable(res.Tags, func(i, j int) bool {
+		return lessTagTotal(res.Tags[i], res.Tags[j])
+	})
+
+	return res
+}
+
+// TagNode contains the totals of the transactions with a tag or any of its descendants.
+type TagNode struct {
+	TagTotal
+	// Name is the last part of the tag, such as rail for transport/rail.
+	Name string
+	// Children contains the nodes for the tags directly below this one, ordered by the most outgoing first.
+	Children []*TagNode
+}
+
+// TagTree contains the totals of a collection of transactions arranged by the hierarchy of their tags.
+type TagTree struct {
+	// Tags contains the nodes for each top-level tag, ordered by the most outgoing first.
+	Tags []*TagNode
+	// Untagged contains the totals for transactions without any tags.
+	Untagged *TagTotal
+	// Incoming is the total of all incoming transactions.
+	Incoming int64
+	// Outgoing is the total of all outgoing transactions.
+	Outgoing int64
+}
+
+// Walk calls fn with each node in the tree, parents before their children, along with its depth where
+// top-level tags are at depth 1.
+func (x *TagTree) Walk(fn func(node *TagNode, depth int)) {
+	var walk func(nodes []*TagNode, depth int)
+	walk = func(nodes []*TagNode, depth int) {
+		for _, n := range nodes {
+			fn(n, depth)
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(x.Tags, 1)
+}
+
+// TagTree returns the totals of the transactions in the collection arranged by the hierarchy of their tags.
+// Each transaction is counted in full once against each of its tags and their ancestors, so the totals of a tag
+// include all of its descendants. Transfers between accounts are ignored.
+func (x *TransactionCollection) TagTree() *TagTree {
+	res := &TagTree{
+		Tags:     make([]*TagNode, 0),
+		Untagged: &TagTotal{},
+	}
+	byTag := make(map[string]*TagNode)
+
+	add := func(total *TagTotal, amount int64) {
+		total.Count++
+		if amount > 0 {
+			total.Incoming += amount
+		} else {
+			total.Outgoing += amount
+		}
+	}
+
+	_ = x.ExcludeTransfers().Range(nil, func(t *Transaction) error {
+		if t.Amount > 0 {
+			res.Incoming += t.Amount
+		} else {
+			res.Outgoing += t.Amount
+		}
+
+		if len(t.Tags) == 0 {
+			add(res.Untagged, t.Amount)
+			return nil
+		}
+
+		counted := make(map[string]struct{})
+		for _, tag := range t.Tags {
+			var parent *TagNode
+			for _, path := range TagAncestors(tag) {
+				node, ok := byTag[path]
+				if !ok {
+					parts := TagParts(path)
+					node = &TagNode{TagTotal: TagTotal{Tag: path}, Name: parts[len(parts)-1], Children: make([]*TagNode, 0)}
+					byTag[path] = node
+					if parent == nil {
+						res.Tags = append(res.Tags, node)
+					} else {
+						parent.Children = append(parent.Children, node)
+					}
+				}
+				if _, ok := counted[path]; !ok {
+					counted[path] = struct{}{}
+					add(&node.TagTotal, t.Amount)
+				}
+				parent = node
+			}
+		}
+		return nil
+	})
+
+	var finish func(nodes []*TagNode)
+	finish = func(nodes []*TagNode) {
+		for _, n := range nodes {
+			n.IncomingPercent = percent(n.Incoming, res.Incoming)
+			n.OutgoingPercent = percent(n.Outgoing, res.Outgoing)
+			finish(n.Children)
+		}
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return lessTagTotal(&nodes[i].TagTotal, &nodes[j].TagTotal)
+		})
+	}
+	finish(res.Tags)
+	res.Untagged.IncomingPercent = percent(res.Untagged.Incoming, res.Incoming)
+	res.Untagged.OutgoingPercent = percent(res.Untagged.Outgoing, res.Outgoing)
+
+	return res
+}
+
+// lessTagTotal orders tag totals by the most outgoing first, then the most incoming, then by tag.
+func lessTagTotal(a *TagTotal, b *TagTotal) bool {
+	if a.Outgoing != b.Outgoing {
+		return a.Outgoing < b.Outgoing
+	}
+	if a.Incoming != b.Incoming {
+		return a.Incoming > b.Incoming
+	}
+	return a.Tag < b.Tag
+}
+
+// uniqueTags returns the given tags with any duplicates removed.
+func uniqueTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		res = append(res, t)
+	}
+	return res
+}
+
+// percent returns value as a percentage of total.
+func percent(value int64, total int64) float64 {
+	if value == 0 || total == 0 {
+		return 0
+	}
+	return float64(value) / float64(total) * 100
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// NewTagRule returns a new TagRule.
+func NewTagRule() *TagRule {
+	return &TagRule{
+		Tags: []string{},
+	}
+}
+
+// TagRule adds tags to transactions that match all of its conditions.
+// A rule without any conditions matches every transaction.
+type TagRule struct {
+	// ID is a unique identifier.
+	ID string
+	// ProfileID is the identifier for the profile the rule belongs to.
+	ProfileID string
+	// Position is the order the rule is applied in within the profile, starting at 1.
+	Position int
+	// LabelContains matches transactions with a label containing the given text, ignoring case.
+	LabelContains string
+	// LabelPattern matches transactions with a label matching the given regular expression.
+	LabelPattern string
+	// MinAmount matches transactions with a signed amount of at least the given amount, in the minor unit of Currency.
+	// A nil MinAmount means there is no minimum.
+	MinAmount *int64
+	// MaxAmount matches transactions with a signed amount of at most the given amount, in the minor unit of Currency.
+	// A nil MaxAmount means there is no maximum.
+	MaxAmount *int64
+	// Currency is the currency of MinAmount and MaxAmount.
+	// Transactions in other currencies do not match a rule with an amount range.
+	Currency Currency
+	// AccountID matches transactions in the given account.
+	AccountID string
+	// Tags contains the tags added to matching transactions.
+	Tags []string
+	// Stop is true if no further rules are applied to transactions that match this rule.
+	Stop bool
+	// CreatedAt is the time at which the rule was created.
+	CreatedAt time.Time
+	// UpdatedAt is the time at which the rule was last updated.
+	UpdatedAt time.Time
+
+	pattern       *regexp.Regexp
+	patternSource string
+}
+
+// WithTags sets the rule Tags
+func (x *TagRule) WithTags(tags ...string) *TagRule {
+	if tags == nil {
+		tags = make([]string, 0)
+	}
+	x.Tags = tags
+	return x
+}
+
+// HasAmountRange returns true if the rule matches on the amount of transactions.
+func (x *TagRule) HasAmountRange() bool {
+	return x.MinAmount != nil || x.MaxAmount != nil
+}
+
+// Pattern compiles LabelPattern.
+// A nil regexp is returned if LabelPattern is empty.
+func (x *TagRule) Pattern() (*regexp.Regexp, error) {
+	if x.LabelPattern == "" {
+		return nil, nil
+	}
+	if x.pattern == nil || x.patternSource != x.LabelPattern {
+		pattern, err := regexp.Compile(x.LabelPattern)
+		if err != nil {
+			return nil, err
+		}
+		x.pattern = pattern
+		x.patternSource = x.LabelPattern
+	}
+	return x.pattern, nil
+}
+
+// Matches returns true if the given transaction matches all of the conditions of the rule.
+// A rule with an invalid LabelPattern does not match any transactions.
+func (x *TagRule) Matches(t *Transaction) bool {
+	if x.LabelContains != "" && !strings.Contains(strings.ToLower(t.Label), strings.ToLower(x.LabelContains)) {
+		return false
+	}
+	if x.LabelPattern != "" {
+		pattern, err := x.Pattern()
+		if err != nil || !pattern.MatchString(t.Label) {
+			return false
+		}
+	}
+	if x.HasAmountRange() {
+		if t.Currency != x.Currency {
+			return false
+		}
+		if x.MinAmount != nil && t.Amount < *x.MinAmount {
+			return false
+		}
+		if x.MaxAmount != nil && t.Amount > *x.MaxAmount {
+			return false
+		}
+	}
+	if x.AccountID != "" && t.AccountID != x.AccountID {
+		return false
+	}
+	return true
+}
+
+// ApplyTagRules returns the tags that the given rules add to the given transaction, in the order they are added.
+// Rules are applied in the order given, and tags the transaction already has are not returned.
+// No further rules are applied after a matching rule with Stop set.
+// The transaction is not changed.
+func ApplyTagRules(rules []*TagRule, t *Transaction) []string {
+	added := make([]string, 0)
+	seen := make(map[string]bool, len(t.Tags))
+	for _, tag := range t.Tags {
+		seen[tag] = true
+	}
+	for _, rule := range rules {
+		if !rule.Matches(t) {
+			continue
+		}
+		for _, tag := range rule.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				added = append(added, tag)
+			}
+		}
+		if rule.Stop {
+			break
+		}
+	}
+	return added
+}
+
+// TagChange describes the tags that tag rules add to an existing transaction.
+type TagChange struct {
+	// Transaction is the transaction the tags are added to, with its tags before the change.
+	Transaction *Transaction
+	// Added contains the tags added to the transaction.
+	Added []string
+}
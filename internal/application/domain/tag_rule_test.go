@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"reflect"
+	"testing"
+)
+
+func amountPtr(amount int64) *int64 {
+	return &amount
+}
+
+func TestTagRule_Matches(t *testing.T) {
+	t.Parallel()
+
+	coffee := domain.NewTransaction().WithLabel("Costa Coffee").WithAmount(-350).WithCurrency("GBP").WithAccountID("acc:1")
+	salary := domain.NewTransaction().WithLabel("Salary").WithAmount(250000).WithCurrency("GBP")
+	euroCoffee := domain.NewTransaction().WithLabel("Cafe").WithAmount(-350).WithCurrency("EUR")
+
+	tests := []struct {
+		name string
+		rule *domain.TagRule
+		t    *domain.Transaction
+		exp  bool
+	}{
+		{name: "LabelContains", rule: &domain.TagRule{LabelContains: "coffee"}, t: coffee, exp: true},
+		{name: "LabelContainsMiss", rule: &domain.TagRule{LabelContains: "tea"}, t: coffee, exp: false},
+		{name: "LabelPattern", rule: &domain.TagRule{LabelPattern: "^(Costa|Starbucks) "}, t: coffee, exp: true},
+		{name: "LabelPatternIsCaseSensitive", rule: &domain.TagRule{LabelPattern: "^costa"}, t: coffee, exp: false},
+		{name: "InvalidLabelPattern", rule: &domain.TagRule{LabelPattern: "("}, t: coffee, exp: false},
+		{name: "MinAmount", rule: &domain.TagRule{MinAmount: amountPtr(-500), Currency: "GBP"}, t: coffee, exp: true},
+		{name: "MinAmountMiss", rule: &domain.TagRule{MinAmount: amountPtr(-100), Currency: "GBP"}, t: coffee, exp: false},
+		{name: "MaxAmount", rule: &domain.TagRule{MaxAmount: amountPtr(0), Currency: "GBP"}, t: coffee, exp: true},
+		{name: "MaxAmountMiss", rule: &domain.TagRule{MaxAmount: amountPtr(0), Currency: "GBP"}, t: salary, exp: false},
+		{name: "AmountOtherCurrency", rule: &domain.TagRule{MaxAmount: amountPtr(0), Currency: "GBP"}, t: euroCoffee, exp: false},
+		{name: "Account", rule: &domain.TagRule{AccountID: "acc:1"}, t: coffee, exp: true},
+		{name: "AccountMiss", rule: &domain.TagRule{AccountID: "acc:1"}, t: salary, exp: false},
+		{name: "AllConditions", rule: &domain.TagRule{LabelContains: "costa", MinAmount: amountPtr(-1000),
+			MaxAmount: amountPtr(-100), Currency: "GBP", AccountID: "acc:1"}, t: coffee, exp: true},
+		{name: "OneConditionMisses", rule: &domain.TagRule{LabelContains: "costa", AccountID: "acc:2"}, t: coffee, exp: false},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rule.Matches(tc.t); got != tc.exp {
+				t.Errorf("expected %v, got %v", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestApplyTagRules(t *testing.T) {
+	t.Parallel()
+
+	rules := []*domain.TagRule{
+		domain.NewTagRule().WithTags("coffee", "food"),
+		domain.NewTagRule().WithTags("eating-out"),
+		domain.NewTagRule().WithTags("subscription"),
+		domain.NewTagRule().WithTags("food", "large"),
+	}
+	rules[0].LabelContains = "coffee"
+	rules[1].LabelContains = "costa"
+	rules[1].Stop = true
+	rules[2].LabelContains = "netflix"
+	rules[3].MaxAmount = amountPtr(-1000)
+	rules[3].Currency = "GBP"
+
+	tests := []struct {
+		name string
+		t    *domain.Transaction
+		exp  []string
+	}{
+		{
+			name: "NoMatches",
+			t:    domain.NewTransaction().WithLabel("Salary").WithAmount(250000).WithCurrency("GBP"),
+			exp:  []string{},
+		},
+		{
+			name: "Stop",
+			t:    domain.NewTransaction().WithLabel("Costa Coffee").WithAmount(-5000).WithCurrency("GBP"),
+			exp:  []string{"coffee", "food", "eating-out"},
+		},
+		{
+			name: "DuplicateTagsAddedOnce",
+			t:    domain.NewTransaction().WithLabel("Coffee beans").WithAmount(-5000).WithCurrency("GBP"),
+			exp:  []string{"coffee", "food", "large"},
+		},
+		{
+			name: "ExistingTagsNotAdded",
+			t:    domain.NewTransaction().WithLabel("Netflix").WithAmount(-999).WithCurrency("GBP").WithTags("subscription"),
+			exp:  []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			before := append([]string{}, tc.t.Tags...)
+			got := domain.ApplyTagRules(rules, tc.t)
+			if !reflect.DeepEqual(tc.exp, got) {
+				t.Errorf("expected tags %v, got %v", tc.exp, got)
+			}
+			if !reflect.DeepEqual(before, tc.t.Tags) {
+				t.Errorf("expected transaction tags to be unchanged, got %v", tc.t.Tags)
+			}
+		})
+	}
+}
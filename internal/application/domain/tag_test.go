@@ -0,0 +1,238 @@
+package domain_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionCollection_GroupByTag(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(200000).WithTags("salary"),
+		domain.NewTransaction().WithAmount(-4001).WithTags("commute", "travel"),
+		domain.NewTransaction().WithAmount(-10000).WithTags("travel"),
+		domain.NewTransaction().WithAmount(-5999),
+	)
+
+	t.Run("each", func(t *testing.T) {
+		b := c.GroupByTag(domain.TagSplitEach)
+		if exp, got := int64(-20000), b.Outgoing; exp != got {
+			t.Errorf("expected outgoing %d, got %d", exp, got)
+		}
+		if exp, got := 3, len(b.Tags); exp != got {
+			t.Fatalf("expected %d tags, got %d", exp, got)
+		}
+		travel := b.Tags[0]
+		if travel.Tag != "travel" || travel.Count != 2 || travel.Outgoing != -14001 {
+			t.Errorf("unexpected travel total: %+v", travel)
+		}
+		if exp, got := 70.005, travel.OutgoingPercent; exp != got {
+			t.Errorf("expected travel outgoing percent %v, got %v", exp, got)
+		}
+		if b.Untagged.Count != 1 || b.Untagged.Outgoing != -5999 {
+			t.Errorf("unexpected untagged total: %+v", b.Untagged)
+		}
+		if salary := b.Tags[2]; salary.Tag != "salary" || salary.IncomingPercent != 100 {
+			t.Errorf("unexpected salary total: %+v", salary)
+		}
+	})
+
+	t.Run("even", func(t *testing.T) {
+		b := c.GroupByTag(domain.TagSplitEven)
+		var sum int64
+		for _, total := range b.Tags {
+			sum += total.Net()
+		}
+		sum += b.Untagged.Net()
+		if exp, got := c.Sum(), sum; exp != got {
+			t.Errorf("expected split totals to add up to %d, got %d", exp, got)
+		}
+		// The first tag receives the remainder of an uneven split.
+		if commute := b.Tags[1]; commute.Tag != "commute" || commute.Outgoing != -2001 {
+			t.Errorf("unexpected commute total: %+v", commute)
+		}
+	})
+}
+
+func TestTagAncestors(t *testing.T) {
+	t.Parallel()
+
+	got := domain.TagAncestors("transport/rail/tube")
+	exp := []string{"transport", "transport/rail", "transport/rail/tube"}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	if got := domain.TagAncestors("travel"); !reflect.DeepEqual([]string{"travel"}, got) {
+		t.Errorf("expected top-level tag to be its only ancestor, got %v", got)
+	}
+}
+
+func TestTagWithin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  string
+		root string
+		exp  bool
+	}{
+		{tag: "transport", root: "transport", exp: true},
+		{tag: "transport/rail", root: "transport", exp: true},
+		{tag: "transport/rail/tube", root: "transport/rail", exp: true},
+		{tag: "transportation", root: "transport", exp: false},
+		{tag: "transport", root: "transport/rail", exp: false},
+	}
+	for _, tc := range tests {
+		if got := domain.TagWithin(tc.tag, tc.root); got != tc.exp {
+			t.Errorf("expected TagWithin(%q, %q) to be %v, got %v", tc.tag, tc.root, tc.exp, got)
+		}
+	}
+}
+
+func TestTruncateTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag   string
+		depth int
+		exp   string
+	}{
+		{tag: "transport/rail/tube", depth: 0, exp: "transport/rail/tube"},
+		{tag: "transport/rail/tube", depth: 1, exp: "transport"},
+		{tag: "transport/rail/tube", depth: 2, exp: "transport/rail"},
+		{tag: "transport/rail/tube", depth: 5, exp: "transport/rail/tube"},
+		{tag: "travel", depth: 1, exp: "travel"},
+	}
+	for _, tc := range tests {
+		if got := domain.TruncateTag(tc.tag, tc.depth); got != tc.exp {
+			t.Errorf("expected TruncateTag(%q, %d) to be %q, got %q", tc.tag, tc.depth, tc.exp, got)
+		}
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  string
+		from string
+		to   string
+		exp  string
+		ok   bool
+	}{
+		{tag: "transport", from: "transport", to: "travel", exp: "travel", ok: true},
+		{tag: "transport/rail", from: "transport", to: "travel", exp: "travel/rail", ok: true},
+		{tag: "transport/rail", from: "transport/rail", to: "transport", exp: "transport", ok: true},
+		{tag: "transport", from: "transport", to: "transport/land", exp: "transport/land", ok: true},
+		{tag: "transportation", from: "transport", to: "travel", exp: "transportation", ok: false},
+	}
+	for _, tc := range tests {
+		got, ok := domain.RenameTag(tc.tag, tc.from, tc.to)
+		if got != tc.exp || ok != tc.ok {
+			t.Errorf("expected RenameTag(%q, %q, %q) to be %q, %v, got %q, %v", tc.tag, tc.from, tc.to, tc.exp, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestNormaliseTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		tagCase domain.TagCase
+		exp     string
+	}{
+		{tag: "travel", tagCase: domain.TagCaseLower, exp: "travel"},
+		{tag: " Travel ", tagCase: domain.TagCaseLower, exp: "travel"},
+		{tag: " Travel ", tagCase: domain.TagCasePreserve, exp: "Travel"},
+		{tag: "Eating  Out", tagCase: domain.TagCaseLower, exp: "eating out"},
+		{tag: " Transport / Rail ", tagCase: domain.TagCaseLower, exp: "transport/rail"},
+		{tag: "Transport/\tRail", tagCase: domain.TagCasePreserve, exp: "Transport/Rail"},
+	}
+	for _, test := range tests {
+		if got := domain.NormaliseTag(test.tag, test.tagCase); got != test.exp {
+			t.Errorf("expected %q with %s case to be %q, got %q", test.tag, test.tagCase, test.exp, got)
+		}
+	}
+}
+
+func TestNormaliseTags(t *testing.T) {
+	got := domain.NormaliseTags([]string{"Travel", "travel ", "food", " TRAVEL"}, domain.TagCaseLower)
+	if exp := []string{"travel", "food"}; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	got = domain.NormaliseTags([]string{"Travel", "travel ", "food", " TRAVEL"}, domain.TagCasePreserve)
+	if exp := []string{"Travel", "travel", "food", "TRAVEL"}; !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+}
+
+func TestTransactionCollection_GroupByTagDepth(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(-1000).WithTags("transport/rail"),
+		domain.NewTransaction().WithAmount(-500).WithTags("transport/road", "transport/rail"),
+		domain.NewTransaction().WithAmount(-200).WithTags("transport"),
+		domain.NewTransaction().WithAmount(-300).WithTags("food"),
+	)
+
+	b := c.GroupByTagDepth(domain.TagSplitEach, 1)
+	if exp, got := 2, len(b.Tags); exp != got {
+		t.Fatalf("expected %d tags, got %d", exp, got)
+	}
+	// The transaction with two transport tags is only counted once.
+	if transport := b.Tags[0]; transport.Tag != "transport" || transport.Count != 3 || transport.Outgoing != -1700 {
+		t.Errorf("unexpected transport total: %+v", transport)
+	}
+
+	if exp, got := 4, len(c.GroupByTag(domain.TagSplitEach).Tags); exp != got {
+		t.Errorf("expected %d tags without a depth, got %d", exp, got)
+	}
+}
+
+func TestTransactionCollection_TagTree(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(-1000).WithTags("transport/rail/tube"),
+		domain.NewTransaction().WithAmount(-500).WithTags("transport/road", "transport/rail"),
+		domain.NewTransaction().WithAmount(-200).WithTags("transport"),
+		domain.NewTransaction().WithAmount(-300).WithTags("food"),
+		domain.NewTransaction().WithAmount(200000),
+	)
+
+	tree := c.TagTree()
+
+	type line struct {
+		tag      string
+		depth    int
+		count    int
+		outgoing int64
+	}
+	got := make([]line, 0)
+	tree.Walk(func(node *domain.TagNode, depth int) {
+		got = append(got, line{tag: node.Tag, depth: depth, count: node.Count, outgoing: node.Outgoing})
+	})
+	exp := []line{
+		{tag: "transport", depth: 1, count: 3, outgoing: -1700},
+		{tag: "transport/rail", depth: 2, count: 2, outgoing: -1500},
+		{tag: "transport/rail/tube", depth: 3, count: 1, outgoing: -1000},
+		{tag: "transport/road", depth: 2, count: 1, outgoing: -500},
+		{tag: "food", depth: 1, count: 1, outgoing: -300},
+	}
+	if !reflect.DeepEqual(exp, got) {
+		t.Errorf("expected tree:\n%v\ngot:\n%v", exp, got)
+	}
+	if name := tree.Tags[0].Children[0].Name; name != "rail" {
+		t.Errorf("expected name rail, got %s", name)
+	}
+	if tree.Untagged.Count != 1 || tree.Untagged.Incoming != 200000 {
+		t.Errorf("unexpected untagged total: %+v", tree.Untagged)
+	}
+	if exp, got := int64(-2000), tree.Outgoing; exp != got {
+		t.Errorf("expected outgoing %d, got %d", exp, got)
+	}
+}
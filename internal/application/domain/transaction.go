@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"sort"
 	"sync"
+	"time"
 )
 
 // NewTransaction returns a new Transaction.
@@ -17,12 +19,31 @@ type Transaction struct {
 	ID string
 	// ProfileID is the identifier for the profile the transaction belongs to.
 	ProfileID string
+	// AccountID is the identifier for the account the transaction belongs to.
+	// AccountID is empty if the transaction has not been assigned to an account.
+	AccountID string
+	// TransferID is the identifier for the transfer the transaction is one half of.
+	// TransferID is empty if the transaction is not part of a transfer.
+	TransferID string
 	// Label is a label for the transaction.
 	Label string
-	// Amount is the amount of funds transferred.
+	// Amount is the amount of funds transferred, in the minor unit of Currency.
 	Amount int64
+	// Currency is the currency of Amount.
+	Currency Currency
 	// Tags contains a set of tags that this transaction can be grouped by.
 	Tags []string
+	// Date is the day on which the transaction took place.
+	Date time.Time
+	// CreatedAt is the time at which the transaction was created.
+	CreatedAt time.Time
+	// UpdatedAt is the time at which the transaction was last updated.
+	UpdatedAt time.Time
+	// ScheduleID is the identifier for the schedule the transaction was projected from.
+	ScheduleID string
+	// Projected is true if the transaction is a future occurrence of a schedule
+	// and has not been stored.
+	Projected bool
 }
 
 // WithID sets the transaction ID
@@ -37,6 +58,23 @@ func (x *Transaction) WithProfileID(id string) *Transaction {
 	return x
 }
 
+// WithAccountID sets the transaction AccountID
+func (x *Transaction) WithAccountID(id string) *Transaction {
+	x.AccountID = id
+	return x
+}
+
+// WithTransferID sets the transaction TransferID
+func (x *Transaction) WithTransferID(id string) *Transaction {
+	x.TransferID = id
+	return x
+}
+
+// IsTransfer returns true if the transaction is one half of a transfer between accounts.
+func (x *Transaction) IsTransfer() bool {
+	return x.TransferID != ""
+}
+
 // WithLabel sets the transaction Label
 func (x *Transaction) WithLabel(label string) *Transaction {
 	x.Label = label
@@ -49,6 +87,17 @@ func (x *Transaction) WithAmount(amount int64) *Transaction {
 	return x
 }
 
+// WithCurrency sets the transaction Currency
+func (x *Transaction) WithCurrency(currency Currency) *Transaction {
+	x.Currency = currency
+	return x
+}
+
+// Money returns the amount of the transaction along with its currency.
+func (x *Transaction) Money() Money {
+	return NewMoney(x.Amount, x.Currency)
+}
+
 // WithTags sets the transaction Tags
 func (x *Transaction) WithTags(tags ...string) *Transaction {
 	if tags == nil {
@@ -58,6 +107,12 @@ func (x *Transaction) WithTags(tags ...string) *Transaction {
 	return x
 }
 
+// WithDate sets the transaction Date
+func (x *Transaction) WithDate(date time.Time) *Transaction {
+	x.Date = TruncateDay(date)
+	return x
+}
+
 // TransactionCollection is a collection of Transactions
 type TransactionCollection struct {
 	mu           *sync.RWMutex
@@ -104,6 +159,43 @@ func (x *TransactionCollection) Subset(filterFn func(t *Transaction) bool) *Tran
 	return c
 }
 
+// Between returns a new TransactionCollection containing the transactions in x
+// that took place within the given date range.
+func (x *TransactionCollection) Between(dateRange DateRange) *TransactionCollection {
+	return x.Subset(func(t *Transaction) bool {
+		return dateRange.Contains(t.Date)
+	})
+}
+
+// ForAccount returns a new TransactionCollection containing the transactions in x
+// that belong to the given account.
+func (x *TransactionCollection) ForAccount(accountID string) *TransactionCollection {
+	return x.Subset(func(t *Transaction) bool {
+		return t.AccountID == accountID
+	})
+}
+
+// ExcludeTransfers returns a new TransactionCollection containing the transactions in x
+// that are not part of a transfer between accounts.
+func (x *TransactionCollection) ExcludeTransfers() *TransactionCollection {
+	return x.Subset(func(t *Transaction) bool {
+		return !t.IsTransfer()
+	})
+}
+
+// SortByDate returns a new TransactionCollection containing the transactions in x
+// ordered by date.
+// Transactions on the same day keep their existing order.
+func (x *TransactionCollection) SortByDate() *TransactionCollection {
+	all := x.All()
+	transactions := make([]*Transaction, len(all))
+	copy(transactions, all)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+	return NewTransactionCollection().Add(transactions...)
+}
+
 // RangeFunction defines a function that can be used with Range.
 type RangeFunction func(t *Transaction) error
 
@@ -133,7 +225,28 @@ func (x *TransactionCollection) Range(errCh chan error, rangeFns ...RangeFunctio
 	return nil
 }
 
+// Totals returns the sum of the transactions in the collection for each currency, ordered by currency.
+func (x *TransactionCollection) Totals() []Money {
+	byCurrency := make(map[Currency]int64)
+	currencies := make([]Currency, 0)
+	for _, t := range x.All() {
+		if _, ok := byCurrency[t.Currency]; !ok {
+			currencies = append(currencies, t.Currency)
+		}
+		byCurrency[t.Currency] += t.Amount
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i] < currencies[j]
+	})
+	res := make([]Money, len(currencies))
+	for i, c := range currencies {
+		res[i] = NewMoney(byCurrency[c], c)
+	}
+	return res
+}
+
 // Sum returns the total sum of all the transactions in the collection.
+// Amounts are summed regardless of their currency.
 func (x *TransactionCollection) Sum() int64 {
 	var sum int64 = 0
 	err := x.Range(nil, func(t *Transaction) error {
@@ -145,3 +258,26 @@ func (x *TransactionCollection) Sum() int64 {
 	}
 	return sum
 }
+
+// DailyBalances returns the running balance at the end of each day in the given date range, starting from the
+// sum of all the transactions in the collection before the range. Amounts are summed regardless of their currency.
+// The date range must have both a start and an end.
+func (x *TransactionCollection) DailyBalances(dateRange DateRange) []int64 {
+	from := TruncateDay(dateRange.From)
+	res := make([]int64, dateRange.Days())
+	var opening int64
+	_ = x.Range(nil, func(t *Transaction) error {
+		if t.Date.Before(from) {
+			opening += t.Amount
+		} else if dateRange.Contains(t.Date) {
+			res[int(t.Date.Sub(from).Hours()/24)] += t.Amount
+		}
+		return nil
+	})
+	balance := opening
+	for i, change := range res {
+		balance += change
+		res[i] = balance
+	}
+	return res
+}
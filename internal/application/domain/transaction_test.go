@@ -23,3 +23,45 @@ func TestTransactionCollection_Sum(t *testing.T) {
 		t.Errorf("expected sum %d, got %d", exp, got)
 	}
 }
+
+func TestTransactionCollection_Between(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+	c.Add(
+		domain.NewTransaction().WithAmount(100).WithDate(date(2019, 2, 28)),
+		domain.NewTransaction().WithAmount(-20).WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithAmount(-30).WithDate(date(2019, 3, 31)),
+		domain.NewTransaction().WithAmount(50).WithDate(date(2019, 4, 1)),
+	)
+
+	march := c.Between(domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 31)})
+	if exp, got := int64(-50), march.Sum(); exp != got {
+		t.Errorf("expected sum %d, got %d", exp, got)
+	}
+}
+
+func TestTransactionCollection_DailyBalances(t *testing.T) {
+	t.Parallel()
+
+	c := domain.NewTransactionCollection()
+
+	c.Add(
+		domain.NewTransaction().WithAmount(1000).WithDate(date(2019, 2, 28)),
+		domain.NewTransaction().WithAmount(-200).WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithAmount(-50).WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithAmount(-25).WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithAmount(500).WithDate(date(2019, 3, 4)),
+	)
+
+	got := c.DailyBalances(domain.DateRange{From: date(2019, 3, 1), To: date(2019, 3, 3)})
+	exp := []int64{800, 800, 725}
+	if len(got) != len(exp) {
+		t.Fatalf("expected %d balances, got %d", len(exp), len(got))
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("expected balance %d on day %d, got %d", exp[i], i, got[i])
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+	"time"
+)
+
+// Account allows you to load and save the accounts within a profile, and to transfer money between them.
+type Account interface {
+	// LoadAccountByID loads the given account.
+	LoadAccountByID(id string) (*domain.Account, errs.Error)
+	// LoadAccountByName loads the account with the given name belonging to the given profile.
+	LoadAccountByName(profileID string, name string) (*domain.Account, errs.Error)
+	// LoadAccountsByProfileID loads all accounts belonging to the given profile, ordered by name.
+	LoadAccountsByProfileID(id string) ([]*domain.Account, errs.Error)
+	// CreateAccount creates the given account.
+	CreateAccount(account *domain.Account) errs.Error
+	// UpdateAccount updates the given account.
+	UpdateAccount(account *domain.Account) errs.Error
+
+	// Transfer records the given transfer as a pair of linked transactions.
+	Transfer(transfer *domain.Transfer) errs.Error
+}
+
+// NewAccountService returns a new AccountService.
+func NewAccountService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, accountRepo repository.Account, validator validate.Validator) Account {
+	return &stdAccount{
+		unitOfWork:  unitOfWork,
+		profileRepo: profileRepo,
+		accountRepo: accountRepo,
+		validator:   validator,
+	}
+}
+
+// stdAccount implements Account
+type stdAccount struct {
+	unitOfWork  repository.UnitOfWork
+	profileRepo repository.Profile
+	accountRepo repository.Account
+	validator   validate.Validator
+}
+
+// LoadAccountByID loads the given account.
+func (x *stdAccount) LoadAccountByID(id string) (*domain.Account, errs.Error) {
+	return x.accountRepo.LoadAccountByID(id)
+}
+
+// LoadAccountByName loads the account with the given name belonging to the given profile.
+func (x *stdAccount) LoadAccountByName(profileID string, name string) (*domain.Account, errs.Error) {
+	return x.accountRepo.LoadAccountByName(profileID, name)
+}
+
+// LoadAccountsByProfileID loads all accounts belonging to the given profile, ordered by name.
+func (x *stdAccount) LoadAccountsByProfileID(id string) ([]*domain.Account, errs.Error) {
+	return x.accountRepo.LoadAccountsByProfileID(id)
+}
+
+// CreateAccount creates the given account.
+func (x *stdAccount) CreateAccount(account *domain.Account) errs.Error {
+	if account.ID == "" {
+		account.ID = "acc:" + uuid.New().String()
+	}
+	if account.Currency == "" {
+		// Default to the currency of the profile.
+		profile, err := x.profileRepo.LoadProfileByID(account.ProfileID)
+		if err != nil {
+			return err
+		}
+		account.Currency = profile.Currency
+	}
+	now := time.Now().UTC()
+	account.CreatedAt = now
+	account.UpdatedAt = now
+	if err := x.validator.Account(account); err != nil {
+		return err
+	}
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkAccountNameAvailable(repos, account); err != nil {
+			return err
+		}
+		return repos.Account.CreateAccount(account)
+	})
+}
+
+// UpdateAccount updates the given account.
+func (x *stdAccount) UpdateAccount(account *domain.Account) errs.Error {
+	account.UpdatedAt = time.Now().UTC()
+	if err := x.validator.Account(account); err != nil {
+		return err
+	}
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkAccountNameAvailable(repos, account); err != nil {
+			return err
+		}
+		return repos.Account.UpdateAccount(account)
+	})
+}
+
+// checkAccountNameAvailable returns an error if another account in the same profile has the same name
+// as the given account.
+func checkAccountNameAvailable(repos repository.Repositories, account *domain.Account) errs.Error {
+	existing, err := repos.Account.LoadAccountByName(account.ProfileID, account.Name)
+	if err != nil && err.Code() == errs.ErrUnknownAccount {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != account.ID {
+		return errs.New().
+			WithCode(errs.ErrAccountExists).
+			WithMessage(fmt.Sprintf("account `%s` already exists", account.Name)).
+			WithStatusCode(http.StatusConflict)
+	}
+	return nil
+}
+
+// Transfer records the given transfer as a pair of linked transactions.
+// Received defaults to Amount when both accounts use the same currency.
+func (x *stdAccount) Transfer(transfer *domain.Transfer) errs.Error {
+	if transfer.ID == "" {
+		transfer.ID = "trf:" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if transfer.Date.IsZero() {
+		transfer.Date = domain.TruncateDay(now)
+	}
+	if transfer.Received.Amount == 0 && transfer.From != nil && transfer.To != nil {
+		if transfer.From.Currency != transfer.To.Currency {
+			return errs.New().
+				WithCode(errs.ErrInvalidTransfer).
+				WithMessage("the received amount must be given when the accounts use different currencies").
+				WithStatusCode(http.StatusBadRequest)
+		}
+		transfer.Received = transfer.Amount
+	}
+	if err := x.validator.Transfer(transfer); err != nil {
+		return err
+	}
+	for _, account := range []*domain.Account{transfer.From, transfer.To} {
+		if err := checkAccountOpen(account); err != nil {
+			return err
+		}
+	}
+
+	out, in := transfer.Transactions()
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		for _, t := range []*domain.Transaction{out, in} {
+			t.ID = "tra:" + uuid.New().String()
+			t.CreatedAt = now
+			t.UpdatedAt = now
+			tags, err := normaliseTags(repos, t.ProfileID, t.Tags)
+			if err != nil {
+				return err
+			}
+			t.Tags = tags
+			if err := x.validator.Transaction(t); err != nil {
+				return err
+			}
+			if err := repos.Transaction.CreateTransaction(t); err != nil {
+				return err
+			}
+			if len(t.Tags) > 0 {
+				if err := repos.Transaction.AddTransactionTags(t.ID, t.Tags...); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// checkAccountOpen returns an error if the given account is closed.
+func checkAccountOpen(account *domain.Account) errs.Error {
+	if account.Closed {
+		return errs.New().
+			WithCode(errs.ErrAccountClosed).
+			WithMessage(fmt.Sprintf("account `%s` is closed", account.Name)).
+			WithStatusCode(http.StatusConflict)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+	"time"
+)
+
+// Envelope allows you to load and save the budget envelopes within a profile, and to see how much of each
+// has been spent.
+type Envelope interface {
+	// LoadEnvelopeByTag loads the envelope for the given tag belonging to the given profile.
+	// The tag is normalised according to the profile.
+	LoadEnvelopeByTag(profileID string, tag string) (*domain.Envelope, errs.Error)
+	// LoadEnvelopesByProfileID loads all envelopes belonging to the given profile, ordered by tag.
+	LoadEnvelopesByProfileID(id string) ([]*domain.Envelope, errs.Error)
+	// CreateEnvelope creates the given envelope.
+	CreateEnvelope(envelope *domain.Envelope) errs.Error
+	// UpdateEnvelope updates the given envelope.
+	UpdateEnvelope(envelope *domain.Envelope) errs.Error
+	// DeleteEnvelope deletes the given envelope.
+	DeleteEnvelope(id string) errs.Error
+	// CalculateEnvelopeStatus calculates the status of the given envelope in the period that contains the
+	// given day, using the given transactions which must all be in the currency of the envelope.
+	CalculateEnvelopeStatus(envelope *domain.Envelope, day time.Time, transactions *domain.TransactionCollection) (*domain.EnvelopeStatus, errs.Error)
+}
+
+// NewEnvelopeService returns a new EnvelopeService.
+func NewEnvelopeService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, envelopeRepo repository.Envelope, validator validate.Validator) Envelope {
+	return &stdEnvelope{
+		unitOfWork:   unitOfWork,
+		profileRepo:  profileRepo,
+		envelopeRepo: envelopeRepo,
+		validator:    validator,
+	}
+}
+
+// stdEnvelope implements Envelope
+type stdEnvelope struct {
+	unitOfWork   repository.UnitOfWork
+	profileRepo  repository.Profile
+	envelopeRepo repository.Envelope
+	validator    validate.Validator
+}
+
+// LoadEnvelopeByTag loads the envelope for the given tag belonging to the given profile.
+// The tag is normalised according to the profile.
+func (x *stdEnvelope) LoadEnvelopeByTag(profileID string, tag string) (*domain.Envelope, errs.Error) {
+	profile, err := x.profileRepo.LoadProfileByID(profileID)
+	if err != nil {
+		return nil, err
+	}
+	tag = domain.NormaliseTag(tag, profile.TagCase)
+	envelope, err := x.envelopeRepo.LoadEnvelopeByTag(profileID, tag)
+	if err != nil && err.Code() == errs.ErrUnknownEnvelope {
+		return nil, err.WithMessage(fmt.Sprintf("no envelope for tag `%s`", tag))
+	}
+	return envelope, err
+}
+
+// LoadEnvelopesByProfileID loads all envelopes belonging to the given profile, ordered by tag.
+func (x *stdEnvelope) LoadEnvelopesByProfileID(id string) ([]*domain.Envelope, errs.Error) {
+	return x.envelopeRepo.LoadEnvelopesByProfileID(id)
+}
+
+// CreateEnvelope creates the given envelope.
+// The currency defaults to the currency of the profile, and the envelope starts in the current period
+// unless a start date is given.
+func (x *stdEnvelope) CreateEnvelope(envelope *domain.Envelope) errs.Error {
+	if envelope.ID == "" {
+		envelope.ID = "env:" + uuid.New().String()
+	}
+	if envelope.Currency == "" {
+		// Default to the currency of the profile.
+		profile, err := x.profileRepo.LoadProfileByID(envelope.ProfileID)
+		if err != nil {
+			return err
+		}
+		envelope.Currency = profile.Currency
+	}
+	now := time.Now().UTC()
+	if envelope.Start.IsZero() {
+		envelope.Start = now
+	}
+	envelope.Start = envelope.Period.Range(envelope.Start).From
+	envelope.CreatedAt = now
+	envelope.UpdatedAt = now
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, envelope.ProfileID, []string{envelope.Tag})
+		if err != nil {
+			return err
+		}
+		envelope.Tag = tags[0]
+		if err := x.validator.Envelope(envelope); err != nil {
+			return err
+		}
+		if err := checkEnvelopeTagAvailable(repos, envelope); err != nil {
+			return err
+		}
+		return repos.Envelope.CreateEnvelope(envelope)
+	})
+}
+
+// UpdateEnvelope updates the given envelope.
+func (x *stdEnvelope) UpdateEnvelope(envelope *domain.Envelope) errs.Error {
+	envelope.Start = envelope.Period.Range(envelope.Start).From
+	envelope.UpdatedAt = time.Now().UTC()
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, envelope.ProfileID, []string{envelope.Tag})
+		if err != nil {
+			return err
+		}
+		envelope.Tag = tags[0]
+		if err := x.validator.Envelope(envelope); err != nil {
+			return err
+		}
+		if err := checkEnvelopeTagAvailable(repos, envelope); err != nil {
+			return err
+		}
+		return repos.Envelope.UpdateEnvelope(envelope)
+	})
+}
+
+// DeleteEnvelope deletes the given envelope.
+func (x *stdEnvelope) DeleteEnvelope(id string) errs.Error {
+	return x.envelopeRepo.DeleteEnvelope(id)
+}
+
+// CalculateEnvelopeStatus calculates the status of the given envelope in the period that contains the
+// given day, using the given transactions which must all be in the currency of the envelope.
+func (x *stdEnvelope) CalculateEnvelopeStatus(envelope *domain.Envelope, day time.Time, transactions *domain.TransactionCollection) (*domain.EnvelopeStatus, errs.Error) {
+	transactions = transactions.Subset(func(t *domain.Transaction) bool {
+		return t.ProfileID == envelope.ProfileID
+	})
+	for _, t := range transactions.All() {
+		if t.Currency != envelope.Currency {
+			return nil, errs.New().
+				WithCode(errs.ErrInvalidCurrency).
+				WithMessage(fmt.Sprintf("transaction %s is in %s rather than %s", t.ID, t.Currency, envelope.Currency)).
+				WithStatusCode(http.StatusBadRequest)
+		}
+	}
+	return envelope.Status(day, transactions), nil
+}
+
+// checkEnvelopeTagAvailable returns an error if another envelope in the same profile has the same tag
+// as the given envelope.
+func checkEnvelopeTagAvailable(repos repository.Repositories, envelope *domain.Envelope) errs.Error {
+	existing, err := repos.Envelope.LoadEnvelopeByTag(envelope.ProfileID, envelope.Tag)
+	if err != nil && err.Code() == errs.ErrUnknownEnvelope {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != envelope.ID {
+		return errs.New().
+			WithCode(errs.ErrEnvelopeExists).
+			WithMessage(fmt.Sprintf("an envelope for tag `%s` already exists", envelope.Tag)).
+			WithStatusCode(http.StatusConflict)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+)
+
+// ExchangeRate allows you to load and save exchange rates, and to convert transactions between currencies.
+type ExchangeRate interface {
+	// LoadExchangeRates loads the exchange rates between the given currencies, ordered by date.
+	// An empty currency matches any currency.
+	LoadExchangeRates(from domain.Currency, to domain.Currency) ([]*domain.ExchangeRate, errs.Error)
+	// SaveExchangeRates saves all of the given exchange rates, or none of them if any are invalid.
+	SaveExchangeRates(rates ...*domain.ExchangeRate) errs.Error
+
+	// ConvertTransactions returns copies of the given transactions converted into the given currency
+	// using the exchange rate in effect on the date of each transaction.
+	ConvertTransactions(transactions *domain.TransactionCollection, currency domain.Currency) (*domain.TransactionCollection, errs.Error)
+}
+
+// NewExchangeRateService returns a new ExchangeRateService.
+func NewExchangeRateService(unitOfWork repository.UnitOfWork, exchangeRateRepo repository.ExchangeRate, validator validate.Validator) ExchangeRate {
+	return &stdExchangeRate{
+		unitOfWork:       unitOfWork,
+		exchangeRateRepo: exchangeRateRepo,
+		validator:        validator,
+	}
+}
+
+// stdExchangeRate implements ExchangeRate
+type stdExchangeRate struct {
+	unitOfWork       repository.UnitOfWork
+	exchangeRateRepo repository.ExchangeRate
+	validator        validate.Validator
+}
+
+// LoadExchangeRates loads the exchange rates between the given currencies, ordered by date.
+// An empty currency matches any currency.
+func (x *stdExchangeRate) LoadExchangeRates(from domain.Currency, to domain.Currency) ([]*domain.ExchangeRate, errs.Error) {
+	return x.exchangeRateRepo.LoadExchangeRates(from, to)
+}
+
+// SaveExchangeRates saves all of the given exchange rates, or none of them if any are invalid.
+func (x *stdExchangeRate) SaveExchangeRates(rates ...*domain.ExchangeRate) errs.Error {
+	for _, r := range rates {
+		r.Date = domain.TruncateDay(r.Date)
+		if err := x.validator.ExchangeRate(r); err != nil {
+			return err
+		}
+	}
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		for _, r := range rates {
+			if err := repos.ExchangeRate.SaveExchangeRate(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ConvertTransactions returns copies of the given transactions converted into the given currency
+// using the exchange rate in effect on the date of each transaction.
+func (x *stdExchangeRate) ConvertTransactions(transactions *domain.TransactionCollection, currency domain.Currency) (*domain.TransactionCollection, errs.Error) {
+	needsRates := false
+	for _, t := range transactions.All() {
+		if t.Currency != currency {
+			needsRates = true
+			break
+		}
+	}
+	if !needsRates {
+		return transactions, nil
+	}
+
+	rates, err := x.exchangeRateRepo.LoadExchangeRates("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	converted, convertErr := domain.NewRateTable(rates...).ConvertTransactions(transactions, currency)
+	if missing, ok := convertErr.(*domain.MissingRateError); ok {
+		return nil, errs.New().
+			WithCode(errs.ErrMissingExchangeRate).
+			WithMessage(missing.Error()).
+			WithStatusCode(http.StatusUnprocessableEntity)
+	}
+	if convertErr != nil {
+		return nil, errs.FromErr(convertErr)
+	}
+	return converted, nil
+}
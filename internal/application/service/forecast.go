@@ -0,0 +1,83 @@
+package service
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"time"
+)
+
+// Forecast allows you to project how the balance of a profile, or one of its accounts, will change.
+type Forecast interface {
+	// ForecastBalance projects the balance of the given profile day by day from tomorrow until the end of
+	// the given period, using the transactions already dated in the future and those its schedules will create.
+	// If account is not nil, only the balance of that account is projected, in the currency of the account.
+	// Schedules do not belong to an account, so they are only included when projecting the whole profile.
+	// The transactions of the profile must already be loaded.
+	ForecastBalance(profile *domain.Profile, account *domain.Account, period domain.DateRange, currency domain.Currency, threshold int64) (*domain.Forecast, errs.Error)
+}
+
+// NewForecastService returns a new ForecastService.
+func NewForecastService(scheduleService Schedule, exchangeRateService ExchangeRate) Forecast {
+	return &stdForecast{
+		scheduleService:     scheduleService,
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// stdForecast implements Forecast
+type stdForecast struct {
+	scheduleService     Schedule
+	exchangeRateService ExchangeRate
+}
+
+// ForecastBalance projects the balance of the given profile day by day from tomorrow until the end of
+// the given period, using the transactions already dated in the future and those its schedules will create.
+// If account is not nil, only the balance of that account is projected, in the currency of the account.
+// Schedules do not belong to an account, so they are only included when projecting the whole profile.
+func (x *stdForecast) ForecastBalance(profile *domain.Profile, account *domain.Account, period domain.DateRange, currency domain.Currency, threshold int64) (*domain.Forecast, errs.Error) {
+	today := domain.TruncateDay(time.Now())
+	tomorrow := today.AddDate(0, 0, 1)
+	if period.To.IsZero() || period.To.Before(tomorrow) {
+		return nil, errs.New().
+			WithCode(errs.ErrInvalidPeriod).
+			WithMessage("forecast must end after today").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	period.From = tomorrow
+
+	history := profile.Transactions.Subset(func(t *domain.Transaction) bool {
+		return t.Date.Before(tomorrow)
+	})
+	upcoming := domain.NewTransactionCollection()
+
+	var opening int64
+	if account != nil {
+		// The transactions of an account are always in the currency of the account.
+		currency = account.Currency
+		opening = account.Balance(history).Amount
+		upcoming.Add(profile.Transactions.ForAccount(account.ID).Between(period).All()...)
+	} else {
+		converted, err := x.exchangeRateService.ConvertTransactions(history, currency)
+		if err != nil {
+			return nil, err
+		}
+		opening = converted.Sum()
+
+		projected, err := x.scheduleService.ProjectTransactions(profile.ID, period)
+		if err != nil {
+			return nil, err
+		}
+		upcoming.Add(profile.Transactions.Between(period).All()...).Add(projected.All()...)
+	}
+
+	upcoming, err := x.exchangeRateService.ConvertTransactions(upcoming.SortByDate(), currency)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := domain.NewForecast(opening, today, period, upcoming)
+	forecast.Currency = currency
+	forecast.Threshold = threshold
+	return forecast, nil
+}
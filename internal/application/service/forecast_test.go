@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStdForecast_ForecastBalance(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	profileRepo := repository.NewSQLiteProfile(db)
+	unitOfWork := repository.NewSQLiteUnitOfWork(db)
+	validator := validate.NewValidator(profileRepo, repository.NewSQLiteTransaction(db))
+	scheduleService := service.NewScheduleService(unitOfWork, profileRepo, repository.NewSQLiteSchedule(db), validator)
+	exchangeRateService := service.NewExchangeRateService(unitOfWork, repository.NewSQLiteExchangeRate(db), validator)
+	forecastService := service.NewForecastService(scheduleService, exchangeRateService)
+
+	today := domain.TruncateDay(time.Now())
+	day := func(d int) time.Time {
+		return today.AddDate(0, 0, d)
+	}
+
+	profile := domain.NewProfile()
+	profile.Name = "tom"
+	profile.Currency = "GBP"
+	if err := newProfileService(db).CreateProfile(profile); err != nil {
+		t.Fatalf("could not create profile: %s", err)
+	}
+	account := &domain.Account{ID: "acc:1", ProfileID: profile.ID, Name: "Travel", Currency: "EUR", OpeningBalance: 10000}
+
+	if err := exchangeRateService.SaveExchangeRates(&domain.ExchangeRate{From: "EUR", To: "GBP", Date: day(-30), Rate: 0.5}); err != nil {
+		t.Fatalf("could not save exchange rate: %s", err)
+	}
+	schedule := domain.NewSchedule()
+	schedule.ProfileID = profile.ID
+	schedule.Label = "Lunch"
+	schedule.Amount = -100
+	schedule.Frequency = domain.FrequencyDaily
+	schedule.Start = day(1)
+	if err := scheduleService.CreateSchedule(schedule); err != nil {
+		t.Fatalf("could not create schedule: %s", err)
+	}
+
+	profile.Transactions.Add(
+		domain.NewTransaction().WithLabel("Shopping").WithAmount(-1000).WithCurrency("GBP").WithDate(day(-5)),
+		domain.NewTransaction().WithLabel("Refund").WithAmount(2000).WithCurrency("EUR").WithDate(day(-2)).WithAccountID(account.ID),
+		domain.NewTransaction().WithLabel("Hotel").WithAmount(-4000).WithCurrency("EUR").WithDate(day(2)).WithAccountID(account.ID),
+	)
+
+	tests := []struct {
+		name     string
+		account  *domain.Account
+		currency domain.Currency
+		opening  int64
+		balances []int64
+	}{
+		{
+			name:     "Profile",
+			currency: "GBP",
+			opening:  0,
+			balances: []int64{-100, -2200, -2300},
+		},
+		{
+			name:     "Account",
+			account:  account,
+			currency: "EUR",
+			opening:  12000,
+			balances: []int64{12000, 8000, 8000},
+		},
+	}
+
+	for _, tc := range tests {
+		forecast, err := forecastService.ForecastBalance(profile, tc.account, domain.DateRange{To: day(3)}, "GBP", 0)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+		if forecast.Currency != tc.currency {
+			t.Errorf("%s: expected currency %s, got %s", tc.name, tc.currency, forecast.Currency)
+		}
+		if forecast.Opening != tc.opening {
+			t.Errorf("%s: expected opening balance %d, got %d", tc.name, tc.opening, forecast.Opening)
+		}
+		balances := make([]int64, len(forecast.Days))
+		for i, d := range forecast.Days {
+			balances[i] = d.Balance
+		}
+		if !reflect.DeepEqual(tc.balances, balances) {
+			t.Errorf("%s: expected balances %v, got %v", tc.name, tc.balances, balances)
+		}
+	}
+
+	if _, err := forecastService.ForecastBalance(profile, nil, domain.DateRange{To: today}, "GBP", 0); err == nil || err.Code() != errs.ErrInvalidPeriod {
+		t.Errorf("expected an invalid period error for a forecast ending today, got %v", err)
+	}
+}
@@ -1,37 +1,73 @@
 package service
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/application/validate"
 	"github.com/tomwright/finance-planner/internal/errs"
 	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+	"time"
 )
 
 // Profile allows you to load and save a full profile.
 type Profile interface {
-	// LoadProfile loads the given profile by id, as well as all related transactions.
-	LoadProfileByID(id string) (*domain.Profile, errs.Error)
-	// LoadProfile loads the given profile by name, as well as all related transactions.
-	LoadProfileByName(name string) (*domain.Profile, errs.Error)
+	// LoadProfile loads the given profile by id, as well as all related transactions
+	// within the given date range.
+	LoadProfileByID(id string, dateRange domain.DateRange) (*domain.Profile, errs.Error)
+	// LoadProfile loads the given profile by name, as well as all related transactions
+	// within the given date range.
+	LoadProfileByName(name string, dateRange domain.DateRange) (*domain.Profile, errs.Error)
+	// LoadProfileByIDWithoutTransactions loads the given profile by id, without any transactions.
+	// Use LoadTransactions to load the transactions that match a filter.
+	LoadProfileByIDWithoutTransactions(id string) (*domain.Profile, errs.Error)
+	// LoadProfileByNameWithoutTransactions loads the given profile by name, without any transactions.
+	// Use LoadTransactions to load the transactions that match a filter.
+	LoadProfileByNameWithoutTransactions(name string) (*domain.Profile, errs.Error)
 	// LoadOrCreateProfileByName loads the given profile if it exists, or creates a new one.
 	LoadOrCreateProfileByName(name string) (*domain.Profile, errs.Error)
+	// ListProfiles loads all profiles ordered by name, without their transactions.
+	ListProfiles() ([]*domain.Profile, errs.Error)
 	// CreateProfile creates the given profile, but does not affect transactions.
+	// Profile names must be unique.
 	CreateProfile(profile *domain.Profile) errs.Error
 	// UpdateProfile updates the given profile, but does not affect transactions.
+	// Profile names must be unique.
 	UpdateProfile(profile *domain.Profile) errs.Error
+	// DeleteProfile deletes the given profile along with all of its transactions, schedules, accounts,
+	// tag rules and envelopes.
+	DeleteProfile(id string) errs.Error
 
+	// LoadTransactions loads the transactions belonging to the given profile that match the given filter,
+	// in the order and page given by the filter.
+	LoadTransactions(profileID string, filter domain.TransactionFilter) (*domain.TransactionCollection, errs.Error)
 	// LoadTransactionByID loads the given transaction.
 	LoadTransactionByID(id string) (*domain.Transaction, errs.Error)
 	// CreateTransaction creates the given transaction.
+	// Tags are added to the transaction by any of the profiles tag rules that it matches.
 	CreateTransaction(transaction *domain.Transaction) errs.Error
+	// ImportTransactions creates the given transactions in the given profile within a single unit of work, in the same
+	// way as CreateTransaction. The tag rules of the profile are loaded once for all of the transactions.
+	// Transactions that cannot be created are skipped, and the reason is returned at the same index as the transaction.
+	// If anything else goes wrong none of the transactions are created.
+	ImportTransactions(profileID string, transactions []*domain.Transaction) ([]errs.Error, errs.Error)
 	// UpdateTransaction updates the given transaction.
+	// Only the label and tags of a transaction that is part of a transfer can be changed.
 	UpdateTransaction(transaction *domain.Transaction) errs.Error
+	// DeleteTransaction deletes the given transaction.
+	// Deleting either transaction in a transfer deletes both of them.
+	DeleteTransaction(id string) errs.Error
+
+	// CalculateBudget calculates the budget for the given profile over the given period
+	// using the given transactions, which must all be in the given currency.
+	CalculateBudget(profile *domain.Profile, period domain.DateRange, currency domain.Currency, transactions *domain.TransactionCollection) (*domain.Budget, errs.Error)
 }
 
 // NewProfileService returns a new ProfileService.
-func NewProfileService(profileRepo repository.Profile, transactionRepo repository.Transaction, validator validate.Validator) Profile {
+func NewProfileService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, transactionRepo repository.Transaction, validator validate.Validator) Profile {
 	return &stdProfile{
+		unitOfWork:      unitOfWork,
 		profileRepo:     profileRepo,
 		transactionRepo: transactionRepo,
 		validator:       validator,
@@ -40,51 +76,76 @@ func NewProfileService(profileRepo repository.Profile, transactionRepo repositor
 
 // stdProfile implements Profile
 type stdProfile struct {
+	unitOfWork      repository.UnitOfWork
 	profileRepo     repository.Profile
 	transactionRepo repository.Transaction
 	validator       validate.Validator
 }
 
-// LoadProfile loads the given profile by id, as well as all related transactions.
-func (x *stdProfile) LoadProfileByID(id string) (*domain.Profile, errs.Error) {
+// LoadProfile loads the given profile by id, as well as all related transactions
+// within the given date range.
+func (x *stdProfile) LoadProfileByID(id string, dateRange domain.DateRange) (*domain.Profile, errs.Error) {
 	profile, err := x.profileRepo.LoadProfileByID(id)
 	if err != nil {
 		return nil, err
 	}
-	transactions, err := x.transactionRepo.LoadTransactionsByProfileID(profile.ID)
-	if err != nil {
+	if err := x.loadTransactions(profile, domain.TransactionFilter{DateRange: dateRange}); err != nil {
 		return nil, err
 	}
-	if len(transactions) > 0 {
-		for _, t := range transactions {
-			if err := x.initLoadedTransaction(t); err != nil {
-				return nil, err
-			}
-			profile.Transactions.Add(t)
-		}
-	}
 	return profile, nil
 }
 
-// LoadProfile loads the given profile by name, as well as all related transactions.
-func (x *stdProfile) LoadProfileByName(name string) (*domain.Profile, errs.Error) {
+// LoadProfile loads the given profile by name, as well as all related transactions
+// within the given date range.
+func (x *stdProfile) LoadProfileByName(name string, dateRange domain.DateRange) (*domain.Profile, errs.Error) {
 	profile, err := x.profileRepo.LoadProfileByName(name)
 	if err != nil {
 		return nil, err
 	}
-	transactions, err := x.transactionRepo.LoadTransactionsByProfileID(profile.ID)
+	if err := x.loadTransactions(profile, domain.TransactionFilter{DateRange: dateRange}); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// LoadProfileByIDWithoutTransactions loads the given profile by id, without any transactions.
+// Use LoadTransactions to load the transactions that match a filter.
+func (x *stdProfile) LoadProfileByIDWithoutTransactions(id string) (*domain.Profile, errs.Error) {
+	return x.profileRepo.LoadProfileByID(id)
+}
+
+// LoadProfileByNameWithoutTransactions loads the given profile by name, without any transactions.
+// Use LoadTransactions to load the transactions that match a filter.
+func (x *stdProfile) LoadProfileByNameWithoutTransactions(name string) (*domain.Profile, errs.Error) {
+	return x.profileRepo.LoadProfileByName(name)
+}
+
+// loadTransactions adds the transactions belonging to the given profile that match the given filter
+// to the profile.
+func (x *stdProfile) loadTransactions(profile *domain.Profile, filter domain.TransactionFilter) errs.Error {
+	transactions, err := x.LoadTransactions(profile.ID, filter)
+	if err != nil {
+		return err
+	}
+	profile.Transactions.Add(transactions.All()...)
+	return nil
+}
+
+// LoadTransactions loads the transactions belonging to the given profile that match the given filter,
+// in the order and page given by the filter.
+func (x *stdProfile) LoadTransactions(profileID string, filter domain.TransactionFilter) (*domain.TransactionCollection, errs.Error) {
+	transactions, err := x.transactionRepo.LoadTransactionsByFilter(profileID, filter)
 	if err != nil {
 		return nil, err
 	}
-	if len(transactions) > 0 {
-		for _, t := range transactions {
-			if err := x.initLoadedTransaction(t); err != nil {
-				return nil, err
-			}
-			profile.Transactions.Add(t)
+	res := domain.NewTransactionCollection()
+	for _, t := range transactions {
+		if err := x.initLoadedTransaction(t); err != nil {
+			return nil, err
 		}
+		res.Add(t)
 	}
-	return profile, nil
+	return res, nil
 }
 
 func (x *stdProfile) initLoadedTransaction(transaction *domain.Transaction) errs.Error {
@@ -110,23 +171,90 @@ func (x *stdProfile) LoadOrCreateProfileByName(name string) (*domain.Profile, er
 	return p, nil
 }
 
+// ListProfiles loads all profiles ordered by name, without their transactions.
+func (x *stdProfile) ListProfiles() ([]*domain.Profile, errs.Error) {
+	return x.profileRepo.ListProfiles()
+}
+
 // CreateProfile creates the given profile, but does not affect transactions.
+// Profile names must be unique.
 func (x *stdProfile) CreateProfile(profile *domain.Profile) errs.Error {
 	if profile.ID == "" {
 		profile.ID = "pro:" + uuid.New().String()
 	}
+	if profile.Currency == "" {
+		profile.Currency = domain.DefaultCurrency
+	}
+	if profile.TagCase == "" {
+		profile.TagCase = domain.TagCaseLower
+	}
 	if err := x.validator.Profile(profile); err != nil {
 		return err
 	}
-	return x.profileRepo.CreateProfile(profile)
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkProfileNameAvailable(repos, profile); err != nil {
+			return err
+		}
+		return repos.Profile.CreateProfile(profile)
+	})
 }
 
 // UpdateProfile updates the given profile, but does not affect transactions.
+// Profile names must be unique.
 func (x *stdProfile) UpdateProfile(profile *domain.Profile) errs.Error {
 	if err := x.validator.Profile(profile); err != nil {
 		return err
 	}
-	return x.profileRepo.UpdateProfile(profile)
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkProfileNameAvailable(repos, profile); err != nil {
+			return err
+		}
+		return repos.Profile.UpdateProfile(profile)
+	})
+}
+
+// checkProfileNameAvailable returns an error if another profile has the same name as the given profile.
+func checkProfileNameAvailable(repos repository.Repositories, profile *domain.Profile) errs.Error {
+	existing, err := repos.Profile.LoadProfileByName(profile.Name)
+	if err != nil && err.Code() == errs.ErrUnknownProfile {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != profile.ID {
+		return errs.New().
+			WithCode(errs.ErrProfileExists).
+			WithMessage(fmt.Sprintf("profile `%s` already exists", profile.Name)).
+			WithStatusCode(http.StatusConflict)
+	}
+	return nil
+}
+
+// DeleteProfile deletes the given profile along with all of its transactions, schedules, accounts,
+// tag rules and envelopes.
+func (x *stdProfile) DeleteProfile(id string) errs.Error {
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if _, err := repos.Profile.LoadProfileByID(id); err != nil {
+			return err
+		}
+		if err := repos.Transaction.DeleteTransactionsByProfileID(id); err != nil {
+			return err
+		}
+		if err := repos.Schedule.DeleteSchedulesByProfileID(id); err != nil {
+			return err
+		}
+		if err := repos.Account.DeleteAccountsByProfileID(id); err != nil {
+			return err
+		}
+		if err := repos.TagRule.DeleteTagRulesByProfileID(id); err != nil {
+			return err
+		}
+		if err := repos.Envelope.DeleteEnvelopesByProfileID(id); err != nil {
+			return err
+		}
+		return repos.Profile.DeleteProfile(id)
+	})
 }
 
 func (x *stdProfile) LoadTransactionByID(id string) (*domain.Transaction, errs.Error) {
@@ -141,18 +269,96 @@ func (x *stdProfile) LoadTransactionByID(id string) (*domain.Transaction, errs.E
 }
 
 // CreateTransaction creates the given transaction.
+// Tags are added to the transaction by any of the profiles tag rules that it matches.
 func (x *stdProfile) CreateTransaction(transaction *domain.Transaction) errs.Error {
+	initNewTransaction(transaction, time.Now().UTC())
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		profile, err := repos.Profile.LoadProfileByID(transaction.ProfileID)
+		if err != nil {
+			return err
+		}
+		rules, err := loadTagRules(repos.TagRule, profile.ID)
+		if err != nil {
+			return err
+		}
+		if err := x.prepareTransaction(repos, profile, rules, transaction); err != nil {
+			return err
+		}
+		return createTransaction(repos, transaction)
+	})
+}
+
+// ImportTransactions creates the given transactions in the given profile within a single unit of work, in the same
+// way as CreateTransaction. The tag rules of the profile are loaded once for all of the transactions.
+// Transactions that cannot be created are skipped, and the reason is returned at the same index as the transaction.
+// If anything else goes wrong none of the transactions are created.
+func (x *stdProfile) ImportTransactions(profileID string, transactions []*domain.Transaction) ([]errs.Error, errs.Error) {
+	now := time.Now().UTC()
+	var skipped []errs.Error
+	err := x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		profile, err := repos.Profile.LoadProfileByID(profileID)
+		if err != nil {
+			return err
+		}
+		rules, err := loadTagRules(repos.TagRule, profile.ID)
+		if err != nil {
+			return err
+		}
+		skipped = make([]errs.Error, len(transactions))
+		for i, transaction := range transactions {
+			transaction.ProfileID = profile.ID
+			initNewTransaction(transaction, now)
+			if err := x.prepareTransaction(repos, profile, rules, transaction); err != nil {
+				skipped[i] = err
+				continue
+			}
+			if err := createTransaction(repos, transaction); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skipped, nil
+}
+
+// initNewTransaction sets the ID, date and timestamps of a transaction that is about to be created.
+func initNewTransaction(transaction *domain.Transaction, now time.Time) {
 	if transaction.ID == "" {
 		transaction.ID = "tra:" + uuid.New().String()
 	}
-	if err := x.validator.Transaction(transaction); err != nil {
-		return err
+	if transaction.Date.IsZero() {
+		transaction.Date = domain.TruncateDay(now)
 	}
-	if err := x.transactionRepo.CreateTransaction(transaction); err != nil {
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+}
+
+// prepareTransaction defaults the currency of the given new transaction, applies the given tag rules of its profile
+// and validates it.
+func (x *stdProfile) prepareTransaction(repos repository.Repositories, profile *domain.Profile, rules []*domain.TagRule, transaction *domain.Transaction) errs.Error {
+	if transaction.AccountID != "" {
+		if err := checkTransactionAccount(repos, transaction); err != nil {
+			return err
+		}
+	}
+	if transaction.Currency == "" {
+		// Default to the currency of the profile.
+		transaction.Currency = profile.Currency
+	}
+	transaction.Tags = domain.NormaliseTags(append(transaction.Tags, domain.ApplyTagRules(rules, transaction)...), profile.TagCase)
+	return x.validator.Transaction(transaction)
+}
+
+// createTransaction stores the given prepared transaction along with its tags.
+func createTransaction(repos repository.Repositories, transaction *domain.Transaction) errs.Error {
+	if err := repos.Transaction.CreateTransaction(transaction); err != nil {
 		return err
 	}
 	if len(transaction.Tags) > 0 {
-		if err := x.transactionRepo.AddTransactionTags(transaction.ID, transaction.Tags...); err != nil {
+		if err := repos.Transaction.AddTransactionTags(transaction.ID, transaction.Tags...); err != nil {
 			return err
 		}
 	}
@@ -160,20 +366,128 @@ func (x *stdProfile) CreateTransaction(transaction *domain.Transaction) errs.Err
 }
 
 // UpdateTransaction updates the given transaction.
+// Only the label and tags of a transaction that is part of a transfer can be changed.
 func (x *stdProfile) UpdateTransaction(transaction *domain.Transaction) errs.Error {
-	if err := x.validator.Transaction(transaction); err != nil {
+	transaction.UpdatedAt = time.Now().UTC()
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, transaction.ProfileID, transaction.Tags)
+		if err != nil {
+			return err
+		}
+		transaction.Tags = tags
+		if err := x.validator.Transaction(transaction); err != nil {
+			return err
+		}
+		existing, err := repos.Transaction.LoadTransactionByID(transaction.ID)
+		if err != nil {
+			return err
+		}
+		if existing.IsTransfer() && (transaction.Amount != existing.Amount || transaction.Currency != existing.Currency ||
+			transaction.AccountID != existing.AccountID || !transaction.Date.Equal(existing.Date)) {
+			return errs.New().
+				WithCode(errs.ErrInvalidTransfer).
+				WithMessage("the amount, currency, account and date of a transfer cannot be changed: delete the transfer and create it again").
+				WithStatusCode(http.StatusBadRequest)
+		}
+		if transaction.AccountID != "" && (transaction.AccountID != existing.AccountID || transaction.Currency != existing.Currency) {
+			if err := checkTransactionAccount(repos, transaction); err != nil {
+				return err
+			}
+		}
+		if err := repos.Transaction.UpdateTransaction(transaction); err != nil {
+			return err
+		}
+		if err := repos.Transaction.ClearTransactionTags(transaction.ID); err != nil {
+			return err
+		}
+		if len(transaction.Tags) > 0 {
+			if err := repos.Transaction.AddTransactionTags(transaction.ID, transaction.Tags...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteTransaction deletes the given transaction.
+// Deleting either transaction in a transfer deletes both of them.
+func (x *stdProfile) DeleteTransaction(id string) errs.Error {
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		t, err := repos.Transaction.LoadTransactionByID(id)
+		if err != nil {
+			return err
+		}
+		transactions := []*domain.Transaction{t}
+		if t.IsTransfer() {
+			if transactions, err = repos.Transaction.LoadTransactionsByTransferID(t.TransferID); err != nil {
+				return err
+			}
+		}
+		for _, t := range transactions {
+			if err := repos.Transaction.DeleteTransaction(t.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// checkTransactionAccount checks that the given transaction can be assigned to its account.
+// The currency of the transaction defaults to the currency of the account.
+func checkTransactionAccount(repos repository.Repositories, transaction *domain.Transaction) errs.Error {
+	account, err := repos.Account.LoadAccountByID(transaction.AccountID)
+	if err != nil {
 		return err
 	}
-	if err := x.transactionRepo.UpdateTransaction(transaction); err != nil {
-		return err
+	if account.ProfileID != transaction.ProfileID {
+		return errs.New().
+			WithCode(errs.ErrUnknownAccount).
+			WithMessage("account does not belong to the profile").
+			WithStatusCode(http.StatusBadRequest)
 	}
-	if err := x.transactionRepo.ClearTransactionTags(transaction.ID); err != nil {
+	if err := checkAccountOpen(account); err != nil {
 		return err
 	}
-	if len(transaction.Tags) > 0 {
-		if err := x.transactionRepo.AddTransactionTags(transaction.ID, transaction.Tags...); err != nil {
-			return err
-		}
+	if transaction.Currency == "" {
+		transaction.Currency = account.Currency
+	}
+	if transaction.Currency != account.Currency {
+		return errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("transaction must be in %s, the currency of account `%s`", account.Currency, account.Name)).
+			WithStatusCode(http.StatusBadRequest)
 	}
 	return nil
 }
+
+// CalculateBudget calculates the budget for the given profile over the given period
+// using the given transactions, which must all be in the given currency.
+func (x *stdProfile) CalculateBudget(profile *domain.Profile, period domain.DateRange, currency domain.Currency, transactions *domain.TransactionCollection) (*domain.Budget, errs.Error) {
+	if period.From.IsZero() || period.To.IsZero() {
+		return nil, errs.New().
+			WithCode(errs.ErrInvalidPeriod).
+			WithMessage("budget period must have a start and end date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if period.To.Before(period.From) {
+		return nil, errs.New().
+			WithCode(errs.ErrInvalidPeriod).
+			WithMessage("budget period must not end before it starts").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	transactions = transactions.Subset(func(t *domain.Transaction) bool {
+		return t.ProfileID == profile.ID
+	})
+	for _, t := range transactions.All() {
+		if t.Currency != currency {
+			return nil, errs.New().
+				WithCode(errs.ErrInvalidCurrency).
+				WithMessage(fmt.Sprintf("transaction %s is in %s rather than %s", t.ID, t.Currency, currency)).
+				WithStatusCode(http.StatusBadRequest)
+		}
+	}
+	budget := domain.NewBudget(period, time.Now(), transactions)
+	budget.ProfileID = profile.ID
+	budget.Currency = currency
+	return budget, nil
+}
@@ -0,0 +1,266 @@
+package service_test
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testDB returns a migrated database in a temporary directory, and a func to remove it.
+func testDB(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "finance-planner")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	db, err := repository.ConnectSQLite(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatalf("could not connect: %s", err)
+	}
+	cleanup := func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	}
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		cleanup()
+		t.Fatalf("could not migrate: %s", err)
+	}
+	return db, cleanup
+}
+
+// newProfileService returns a profile service using the given database.
+func newProfileService(db *sql.DB) service.Profile {
+	profileRepo := repository.NewSQLiteProfile(db)
+	transactionRepo := repository.NewSQLiteTransaction(db)
+	validator := validate.NewValidator(profileRepo, transactionRepo)
+	return service.NewProfileService(repository.NewSQLiteUnitOfWork(db), profileRepo, transactionRepo, validator)
+}
+
+func TestStdProfile_CreateTransaction_NormalisesTags(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	profileService := newProfileService(db)
+
+	for _, tc := range []struct {
+		tagCase domain.TagCase
+		exp     []string
+	}{
+		{tagCase: domain.TagCaseLower, exp: []string{"transport/rail", "travel"}},
+		{tagCase: domain.TagCasePreserve, exp: []string{"Transport/Rail", "Travel", "travel"}},
+	} {
+		profile := domain.NewProfile()
+		profile.Name = string(tc.tagCase)
+		profile.Currency = "GBP"
+		profile.TagCase = tc.tagCase
+		if err := profileService.CreateProfile(profile); err != nil {
+			t.Fatalf("%s: could not create profile: %s", tc.tagCase, err)
+		}
+
+		transaction := domain.NewTransaction().WithProfileID(profile.ID).WithLabel("Train").WithAmount(-4000).
+			WithDate(time.Now().UTC()).WithTags(" Transport / Rail ", "Travel ", "travel")
+		if err := profileService.CreateTransaction(transaction); err != nil {
+			t.Fatalf("%s: could not create transaction: %s", tc.tagCase, err)
+		}
+		if !reflect.DeepEqual(tc.exp, transaction.Tags) {
+			t.Errorf("%s: expected tags %v, got %v", tc.tagCase, tc.exp, transaction.Tags)
+		}
+
+		got, err := profileService.LoadTransactionByID(transaction.ID)
+		if err != nil {
+			t.Fatalf("%s: could not load transaction: %s", tc.tagCase, err)
+		}
+		if !reflect.DeepEqual(tc.exp, got.Tags) {
+			t.Errorf("%s: expected stored tags %v, got %v", tc.tagCase, tc.exp, got.Tags)
+		}
+
+		got.Tags = []string{"Food ", " food"}
+		if err := profileService.UpdateTransaction(got); err != nil {
+			t.Fatalf("%s: could not update transaction: %s", tc.tagCase, err)
+		}
+		exp := []string{"food"}
+		if tc.tagCase == domain.TagCasePreserve {
+			exp = []string{"Food", "food"}
+		}
+		if !reflect.DeepEqual(exp, got.Tags) {
+			t.Errorf("%s: expected updated tags %v, got %v", tc.tagCase, exp, got.Tags)
+		}
+	}
+}
+
+func TestStdProfile_ImportTransactions(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	profileService := newProfileService(db)
+
+	profile := domain.NewProfile()
+	profile.Name = "tom"
+	profile.Currency = "GBP"
+	if err := profileService.CreateProfile(profile); err != nil {
+		t.Fatalf("could not create profile: %s", err)
+	}
+
+	rule := domain.NewTagRule()
+	rule.ProfileID = profile.ID
+	rule.LabelContains = "rent"
+	rule.Tags = []string{"Home"}
+	tagRuleRepo := repository.NewSQLiteTagRule(db)
+	if err := tagRuleRepo.CreateTagRule(rule); err != nil {
+		t.Fatalf("could not create tag rule: %s", err)
+	}
+	if err := tagRuleRepo.AddTagRuleTags(rule.ID, rule.Tags...); err != nil {
+		t.Fatalf("could not add tag rule tags: %s", err)
+	}
+
+	day := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []*domain.Transaction{
+		domain.NewTransaction().WithLabel("Rent").WithAmount(-80000).WithDate(day),
+		domain.NewTransaction().WithLabel("Nothing").WithDate(day),
+		domain.NewTransaction().WithLabel("Salary").WithAmount(200000).WithDate(day),
+	}
+	skipped, err := profileService.ImportTransactions(profile.ID, transactions)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(skipped) != 3 || skipped[0] != nil || skipped[1] == nil || skipped[2] != nil {
+		t.Fatalf("expected only the second transaction to be skipped, got %v", skipped)
+	}
+
+	got, err := profileService.LoadTransactions(profile.ID, domain.TransactionFilter{})
+	if err != nil {
+		t.Fatalf("could not load transactions: %s", err)
+	}
+	if totals := got.Totals(); len(got.All()) != 2 || len(totals) != 1 || totals[0].Amount != 120000 {
+		t.Errorf("expected the 2 valid transactions to be created, got %v", got.All())
+	}
+	if exp := []string{"home"}; !reflect.DeepEqual(exp, transactions[0].Tags) {
+		t.Errorf("expected the tag rule to add %v, got %v", exp, transactions[0].Tags)
+	}
+	if len(transactions[2].Tags) != 0 {
+		t.Errorf("expected no tags on a transaction the tag rule does not match, got %v", transactions[2].Tags)
+	}
+}
+
+func TestStdProfile_DeleteProfile(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	profileService := newProfileService(db)
+	transactionRepo := repository.NewSQLiteTransaction(db)
+	scheduleRepo := repository.NewSQLiteSchedule(db)
+	accountRepo := repository.NewSQLiteAccount(db)
+	tagRuleRepo := repository.NewSQLiteTagRule(db)
+	envelopeRepo := repository.NewSQLiteEnvelope(db)
+
+	profiles := make([]*domain.Profile, 2)
+	for i, name := range []string{"tom", "jess"} {
+		profile := domain.NewProfile()
+		profile.Name = name
+		profile.Currency = "GBP"
+		if err := profileService.CreateProfile(profile); err != nil {
+			t.Fatalf("could not create profile: %s", err)
+		}
+		profiles[i] = profile
+
+		transaction := domain.NewTransaction().WithProfileID(profile.ID).WithLabel("Rent").WithAmount(-80000).
+			WithDate(time.Now().UTC()).WithTags("home")
+		if err := profileService.CreateTransaction(transaction); err != nil {
+			t.Fatalf("could not create transaction: %s", err)
+		}
+
+		schedule := domain.NewSchedule()
+		schedule.ID = "sch:" + name
+		schedule.ProfileID = profile.ID
+		if err := scheduleRepo.CreateSchedule(schedule); err != nil {
+			t.Fatalf("could not create schedule: %s", err)
+		}
+		if err := scheduleRepo.AddScheduleTags(schedule.ID, "home"); err != nil {
+			t.Fatalf("could not add schedule tags: %s", err)
+		}
+
+		account := domain.NewAccount()
+		account.ID = "acc:" + name
+		account.ProfileID = profile.ID
+		account.Name = "Current"
+		if err := accountRepo.CreateAccount(account); err != nil {
+			t.Fatalf("could not create account: %s", err)
+		}
+
+		rule := domain.NewTagRule()
+		rule.ID = "tru:" + name
+		rule.ProfileID = profile.ID
+		if err := tagRuleRepo.CreateTagRule(rule); err != nil {
+			t.Fatalf("could not create tag rule: %s", err)
+		}
+		if err := tagRuleRepo.AddTagRuleTags(rule.ID, "home"); err != nil {
+			t.Fatalf("could not add tag rule tags: %s", err)
+		}
+
+		envelope := domain.NewEnvelope()
+		envelope.ID = "env:" + name
+		envelope.ProfileID = profile.ID
+		envelope.Tag = "home"
+		if err := envelopeRepo.CreateEnvelope(envelope); err != nil {
+			t.Fatalf("could not create envelope: %s", err)
+		}
+	}
+
+	if err := profileService.DeleteProfile(profiles[0].ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := profileService.DeleteProfile(profiles[0].ID); err == nil || err.Code() != errs.ErrUnknownProfile {
+		t.Errorf("expected %s error when deleting the profile again, got %v", errs.ErrUnknownProfile, err)
+	}
+
+	counts := func(profileID string) []int {
+		transactions, err := transactionRepo.LoadTransactionsByProfileID(profileID, domain.DateRange{})
+		if err != nil {
+			t.Fatalf("could not load transactions: %s", err)
+		}
+		schedules, err := scheduleRepo.LoadSchedulesByProfileID(profileID)
+		if err != nil {
+			t.Fatalf("could not load schedules: %s", err)
+		}
+		accounts, err := accountRepo.LoadAccountsByProfileID(profileID)
+		if err != nil {
+			t.Fatalf("could not load accounts: %s", err)
+		}
+		rules, err := tagRuleRepo.LoadTagRulesByProfileID(profileID)
+		if err != nil {
+			t.Fatalf("could not load tag rules: %s", err)
+		}
+		envelopes, err := envelopeRepo.LoadEnvelopesByProfileID(profileID)
+		if err != nil {
+			t.Fatalf("could not load envelopes: %s", err)
+		}
+		return []int{len(transactions), len(schedules), len(accounts), len(rules), len(envelopes)}
+	}
+	if got := counts(profiles[0].ID); !reflect.DeepEqual([]int{0, 0, 0, 0, 0}, got) {
+		t.Errorf("expected everything belonging to the deleted profile to be deleted, got counts %v", got)
+	}
+	if got := counts(profiles[1].ID); !reflect.DeepEqual([]int{1, 1, 1, 1, 1}, got) {
+		t.Errorf("expected nothing belonging to the other profile to be deleted, got counts %v", got)
+	}
+	if _, err := profileService.LoadProfileByID(profiles[1].ID, domain.DateRange{}); err != nil {
+		t.Errorf("could not load the other profile: %s", err)
+	}
+
+	var rows int
+	for _, table := range []string{"transaction_tags", "schedule_tags", "tag_rule_tags"} {
+		if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table + `;`).Scan(&rows); err != nil {
+			t.Fatalf("could not count %s: %s", table, err)
+		}
+		if rows != 1 {
+			t.Errorf("expected only the other profile's row in %s, got %d rows", table, rows)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+	"time"
+)
+
+// Schedule allows you to load and save recurring transactions.
+type Schedule interface {
+	// LoadScheduleByID loads the given schedule.
+	LoadScheduleByID(id string) (*domain.Schedule, errs.Error)
+	// LoadSchedulesByProfileID loads all schedules belonging to the given profile.
+	LoadSchedulesByProfileID(id string) ([]*domain.Schedule, errs.Error)
+	// CreateSchedule creates the given schedule.
+	CreateSchedule(schedule *domain.Schedule) errs.Error
+	// UpdateSchedule updates the given schedule.
+	UpdateSchedule(schedule *domain.Schedule) errs.Error
+
+	// ProjectTransactions returns the transactions that the given profiles schedules
+	// will create within the given date range.
+	ProjectTransactions(profileID string, dateRange domain.DateRange) (*domain.TransactionCollection, errs.Error)
+}
+
+// NewScheduleService returns a new ScheduleService.
+func NewScheduleService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, scheduleRepo repository.Schedule, validator validate.Validator) Schedule {
+	return &stdSchedule{
+		unitOfWork:   unitOfWork,
+		profileRepo:  profileRepo,
+		scheduleRepo: scheduleRepo,
+		validator:    validator,
+	}
+}
+
+// stdSchedule implements Schedule
+type stdSchedule struct {
+	unitOfWork   repository.UnitOfWork
+	profileRepo  repository.Profile
+	scheduleRepo repository.Schedule
+	validator    validate.Validator
+}
+
+func (x *stdSchedule) initLoadedSchedule(schedule *domain.Schedule) errs.Error {
+	tags, err := x.scheduleRepo.LoadScheduleTagsByID(schedule.ID)
+	if err != nil {
+		return err
+	}
+	schedule.Tags = tags
+	return nil
+}
+
+// LoadScheduleByID loads the given schedule.
+func (x *stdSchedule) LoadScheduleByID(id string) (*domain.Schedule, errs.Error) {
+	s, err := x.scheduleRepo.LoadScheduleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.initLoadedSchedule(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSchedulesByProfileID loads all schedules belonging to the given profile.
+func (x *stdSchedule) LoadSchedulesByProfileID(id string) ([]*domain.Schedule, errs.Error) {
+	schedules, err := x.scheduleRepo.LoadSchedulesByProfileID(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range schedules {
+		if err := x.initLoadedSchedule(s); err != nil {
+			return nil, err
+		}
+	}
+	return schedules, nil
+}
+
+// CreateSchedule creates the given schedule.
+func (x *stdSchedule) CreateSchedule(schedule *domain.Schedule) errs.Error {
+	if schedule.ID == "" {
+		schedule.ID = "sch:" + uuid.New().String()
+	}
+	now := time.Now().UTC()
+	if schedule.Start.IsZero() {
+		schedule.Start = domain.TruncateDay(now)
+	}
+	if schedule.Frequency == domain.FrequencyMonthly && schedule.DayOfMonth == 0 {
+		schedule.DayOfMonth = schedule.Start.Day()
+	}
+	if schedule.Currency == "" {
+		// Default to the currency of the profile.
+		profile, err := x.profileRepo.LoadProfileByID(schedule.ProfileID)
+		if err != nil {
+			return err
+		}
+		schedule.Currency = profile.Currency
+	}
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, schedule.ProfileID, schedule.Tags)
+		if err != nil {
+			return err
+		}
+		schedule.Tags = tags
+		if err := x.validator.Schedule(schedule); err != nil {
+			return err
+		}
+		if err := repos.Schedule.CreateSchedule(schedule); err != nil {
+			return err
+		}
+		if len(schedule.Tags) > 0 {
+			if err := repos.Schedule.AddScheduleTags(schedule.ID, schedule.Tags...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateSchedule updates the given schedule.
+func (x *stdSchedule) UpdateSchedule(schedule *domain.Schedule) errs.Error {
+	schedule.UpdatedAt = time.Now().UTC()
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, schedule.ProfileID, schedule.Tags)
+		if err != nil {
+			return err
+		}
+		schedule.Tags = tags
+		if err := x.validator.Schedule(schedule); err != nil {
+			return err
+		}
+		if err := repos.Schedule.UpdateSchedule(schedule); err != nil {
+			return err
+		}
+		if err := repos.Schedule.ClearScheduleTags(schedule.ID); err != nil {
+			return err
+		}
+		if len(schedule.Tags) > 0 {
+			if err := repos.Schedule.AddScheduleTags(schedule.ID, schedule.Tags...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ProjectTransactions returns the transactions that the given profiles schedules
+// will create within the given date range.
+func (x *stdSchedule) ProjectTransactions(profileID string, dateRange domain.DateRange) (*domain.TransactionCollection, errs.Error) {
+	if dateRange.To.IsZero() {
+		return nil, errs.New().
+			WithCode(errs.ErrInvalidPeriod).
+			WithMessage("projecting transactions requires an end date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	schedules, err := x.LoadSchedulesByProfileID(profileID)
+	if err != nil {
+		return nil, err
+	}
+	res := domain.NewTransactionCollection()
+	for _, s := range schedules {
+		res.Add(s.Project(dateRange).All()...)
+	}
+	return res, nil
+}
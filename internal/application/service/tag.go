@@ -0,0 +1,288 @@
+package service
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"net/http"
+	"time"
+)
+
+// Tag allows you to view and change the tags used across a profile.
+// Changes to a tag also apply to its descendants, so renaming transport renames transport/rail.
+// Renaming or merging a tag also moves its envelopes.
+// Each change is made within a single unit of work.
+type Tag interface {
+	// ListTags loads every tag used in the given profile along with how many times it is used, ordered by tag.
+	ListTags(profileID string) ([]*domain.TagUsage, errs.Error)
+	// CountTaggedTransactions returns the number of transactions in the given profile with the given tag.
+	CountTaggedTransactions(profileID string, tag string) (int, errs.Error)
+	// RenameTag renames the given tag in the transactions, schedules and tag rules of the given profile,
+	// and returns the number of transactions changed.
+	// The new tag is normalised according to the profile, and must not already be in use or have an envelope:
+	// use MergeTag to combine two tags.
+	RenameTag(profileID string, from string, to string) (int, errs.Error)
+	// MergeTag replaces the given tag with another that may already be in use, in the transactions,
+	// schedules and tag rules of the given profile, and returns the number of transactions changed.
+	// The tag merged into is normalised according to the profile.
+	// Envelopes that are moved onto a tag that already has an envelope are deleted, and returned with
+	// the tag they had before the merge.
+	MergeTag(profileID string, from string, into string) (int, []*domain.Envelope, errs.Error)
+	// DeleteTag removes the given tag from the transactions, schedules and tag rules of the given profile,
+	// and returns the number of transactions changed. Tag rules that no longer add any tags are deleted,
+	// along with the envelopes of the tag and its descendants.
+	DeleteTag(profileID string, tag string) (int, errs.Error)
+}
+
+// NewTagService returns a new TagService.
+func NewTagService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, tagRepo repository.Tag, validator validate.Validator) Tag {
+	return &stdTag{
+		unitOfWork:  unitOfWork,
+		profileRepo: profileRepo,
+		tagRepo:     tagRepo,
+		validator:   validator,
+	}
+}
+
+// stdTag implements Tag
+type stdTag struct {
+	unitOfWork  repository.UnitOfWork
+	profileRepo repository.Profile
+	tagRepo     repository.Tag
+	validator   validate.Validator
+}
+
+// ListTags loads every tag used in the given profile along with how many times it is used, ordered by tag.
+func (x *stdTag) ListTags(profileID string) ([]*domain.TagUsage, errs.Error) {
+	return x.tagRepo.LoadTagUsage(profileID)
+}
+
+// CountTaggedTransactions returns the number of transactions in the given profile with the given tag.
+func (x *stdTag) CountTaggedTransactions(profileID string, tag string) (int, errs.Error) {
+	return x.tagRepo.CountTaggedTransactions(profileID, tag)
+}
+
+// RenameTag renames the given tag in the transactions, schedules and tag rules of the given profile,
+// and returns the number of transactions changed.
+// The new tag is normalised according to the profile, and must not already be in use or have an envelope:
+// use MergeTag to combine two tags.
+func (x *stdTag) RenameTag(profileID string, from string, to string) (int, errs.Error) {
+	changed, _, err := x.replaceTag(profileID, from, to, false)
+	return changed, err
+}
+
+// MergeTag replaces the given tag with another that may already be in use, in the transactions,
+// schedules and tag rules of the given profile, and returns the number of transactions changed.
+// The tag merged into is normalised according to the profile.
+// Envelopes that are moved onto a tag that already has an envelope are deleted, and returned with
+// the tag they had before the merge.
+func (x *stdTag) MergeTag(profileID string, from string, into string) (int, []*domain.Envelope, errs.Error) {
+	return x.replaceTag(profileID, from, into, true)
+}
+
+// replaceTag replaces the from tag with the to tag within a single unit of work, and returns the number of
+// transactions changed along with any envelopes that were dropped.
+// The from tag is used exactly as given so that tags saved before normalisation can still be replaced.
+// An error is returned if the to tag is already in use or has an envelope, unless merge is true.
+func (x *stdTag) replaceTag(profileID string, from string, to string, merge bool) (int, []*domain.Envelope, errs.Error) {
+	if err := checkTagGiven(from); err != nil {
+		return 0, nil, err
+	}
+	profile, err := x.profileRepo.LoadProfileByID(profileID)
+	if err != nil {
+		return 0, nil, err
+	}
+	to = domain.NormaliseTag(to, profile.TagCase)
+	if err := x.validator.Tag(to); err != nil {
+		return 0, nil, err
+	}
+	if from == to {
+		return 0, nil, errs.New().
+			WithCode(errs.ErrInvalidTag).
+			WithMessage(fmt.Sprintf("cannot replace tag `%s` with itself", from)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+
+	changed := 0
+	var dropped []*domain.Envelope
+	err = x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkTagInUse(repos, profileID, from); err != nil {
+			return err
+		}
+		// Envelopes are moved first so that a rename is refused before anything else changes.
+		var err errs.Error
+		dropped, err = renameEnvelopeTags(repos, profileID, from, to, merge)
+		if err != nil {
+			return err
+		}
+		if !merge {
+			count, err := repos.Tag.CountTaggedTransactions(profileID, to)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return errs.New().
+					WithCode(errs.ErrTagExists).
+					WithMessage(fmt.Sprintf("%d transactions are already tagged `%s`: merge the tags instead", count, to)).
+					WithStatusCode(http.StatusConflict)
+			}
+		}
+		changed, err = repos.Tag.RenameTag(profileID, from, to)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return changed, dropped, nil
+}
+
+// DeleteTag removes the given tag from the transactions, schedules and tag rules of the given profile,
+// and returns the number of transactions changed. Tag rules that no longer add any tags are deleted,
+// along with the envelopes of the tag and its descendants.
+func (x *stdTag) DeleteTag(profileID string, tag string) (int, errs.Error) {
+	if err := checkTagGiven(tag); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	err := x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		if err := checkTagInUse(repos, profileID, tag); err != nil {
+			return err
+		}
+		var err errs.Error
+		changed, err = repos.Tag.DeleteTag(profileID, tag)
+		if err != nil {
+			return err
+		}
+
+		rules, err := loadTagRules(repos.TagRule, profileID)
+		if err != nil {
+			return err
+		}
+		remaining := make([]*domain.TagRule, 0, len(rules))
+		for _, r := range rules {
+			if len(r.Tags) > 0 {
+				remaining = append(remaining, r)
+				continue
+			}
+			if err := repos.TagRule.DeleteTagRule(r.ID); err != nil {
+				return err
+			}
+		}
+		if err := renumberTagRules(repos, remaining); err != nil {
+			return err
+		}
+
+		envelopes, err := repos.Envelope.LoadEnvelopesByProfileID(profileID)
+		if err != nil {
+			return err
+		}
+		for _, e := range envelopes {
+			if !domain.TagWithin(e.Tag, tag) {
+				continue
+			}
+			if err := repos.Envelope.DeleteEnvelope(e.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// renameEnvelopeTags moves the envelopes of the from tag and its descendants to the to tag.
+// When the renamed tag already has an envelope an error is returned, unless merge is true in which case the
+// existing envelope is kept and the renamed one is deleted and returned with its original tag.
+func renameEnvelopeTags(repos repository.Repositories, profileID string, from string, to string, merge bool) ([]*domain.Envelope, errs.Error) {
+	envelopes, err := repos.Envelope.LoadEnvelopesByProfileID(profileID)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]struct{})
+	renamed := make([]*domain.Envelope, 0)
+	for _, e := range envelopes {
+		if domain.TagWithin(e.Tag, from) {
+			renamed = append(renamed, e)
+		} else {
+			taken[e.Tag] = struct{}{}
+		}
+	}
+	if !merge {
+		for _, e := range renamed {
+			tag, _ := domain.RenameTag(e.Tag, from, to)
+			if _, ok := taken[tag]; ok {
+				return nil, errs.New().
+					WithCode(errs.ErrEnvelopeExists).
+					WithMessage(fmt.Sprintf("an envelope for tag `%s` already exists: merge the tags instead", tag)).
+					WithStatusCode(http.StatusConflict)
+			}
+		}
+	}
+
+	// Delete every renamed envelope first so that renaming a tag into its own subtree cannot conflict
+	// with envelopes that have not been renamed yet.
+	for _, e := range renamed {
+		if err := repos.Envelope.DeleteEnvelope(e.ID); err != nil {
+			return nil, err
+		}
+	}
+	dropped := make([]*domain.Envelope, 0)
+	for _, e := range renamed {
+		tag, _ := domain.RenameTag(e.Tag, from, to)
+		if _, ok := taken[tag]; ok {
+			dropped = append(dropped, e)
+			continue
+		}
+		e.Tag = tag
+		e.UpdatedAt = time.Now().UTC()
+		if err := repos.Envelope.CreateEnvelope(e); err != nil {
+			return nil, err
+		}
+	}
+	return dropped, nil
+}
+
+// normaliseTags returns the given tags normalised according to the tag case of the given profile.
+func normaliseTags(repos repository.Repositories, profileID string, tags []string) ([]string, errs.Error) {
+	if len(tags) == 0 {
+		return tags, nil
+	}
+	profile, err := repos.Profile.LoadProfileByID(profileID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NormaliseTags(tags, profile.TagCase), nil
+}
+
+// checkTagGiven returns an error if the given tag is empty.
+func checkTagGiven(tag string) errs.Error {
+	if tag == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidTag).
+			WithMessage("tag must not be empty").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return nil
+}
+
+// checkTagInUse returns an error if no transactions, schedules or tag rules in the given profile use the given tag.
+func checkTagInUse(repos repository.Repositories, profileID string, tag string) errs.Error {
+	usage, err := repos.Tag.LoadTagUsage(profileID)
+	if err != nil {
+		return err
+	}
+	for _, u := range usage {
+		if domain.TagWithin(u.Tag, tag) {
+			return nil
+		}
+	}
+	return errs.New().
+		WithCode(errs.ErrUnknownTag).
+		WithMessage(fmt.Sprintf("tag `%s` is not used", tag)).
+		WithStatusCode(http.StatusNotFound)
+}
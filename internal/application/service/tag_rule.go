@@ -0,0 +1,257 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"time"
+)
+
+// TagRule allows you to load and save the rules that tag transactions automatically.
+type TagRule interface {
+	// LoadTagRuleByID loads the given tag rule.
+	LoadTagRuleByID(id string) (*domain.TagRule, errs.Error)
+	// LoadTagRulesByProfileID loads all tag rules belonging to the given profile, in the order they are applied.
+	LoadTagRulesByProfileID(id string) ([]*domain.TagRule, errs.Error)
+	// CreateTagRule creates the given tag rule after the existing rules in its profile.
+	CreateTagRule(rule *domain.TagRule) errs.Error
+	// UpdateTagRule updates the given tag rule, but does not change its position.
+	UpdateTagRule(rule *domain.TagRule) errs.Error
+	// MoveTagRule moves the given tag rule to the given position, starting at 1, shifting the rules in between.
+	MoveTagRule(id string, position int) errs.Error
+	// DeleteTagRule deletes the given tag rule.
+	DeleteTagRule(id string) errs.Error
+
+	// ApplyTagRules applies the given profiles tag rules to its existing transactions within the given date range,
+	// and returns the tags added to each transaction that changed.
+	// Nothing is saved if dryRun is true.
+	ApplyTagRules(profileID string, dateRange domain.DateRange, dryRun bool) ([]*domain.TagChange, errs.Error)
+}
+
+// NewTagRuleService returns a new TagRuleService.
+func NewTagRuleService(unitOfWork repository.UnitOfWork, profileRepo repository.Profile, tagRuleRepo repository.TagRule, validator validate.Validator) TagRule {
+	return &stdTagRule{
+		unitOfWork:  unitOfWork,
+		profileRepo: profileRepo,
+		tagRuleRepo: tagRuleRepo,
+		validator:   validator,
+	}
+}
+
+// stdTagRule implements TagRule
+type stdTagRule struct {
+	unitOfWork  repository.UnitOfWork
+	profileRepo repository.Profile
+	tagRuleRepo repository.TagRule
+	validator   validate.Validator
+}
+
+// loadTagRules loads the given profiles tag rules along with their tags, in the order they are applied.
+func loadTagRules(tagRuleRepo repository.TagRule, profileID string) ([]*domain.TagRule, errs.Error) {
+	rules, err := tagRuleRepo.LoadTagRulesByProfileID(profileID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		tags, err := tagRuleRepo.LoadTagRuleTagsByID(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.Tags = tags
+	}
+	return rules, nil
+}
+
+// LoadTagRuleByID loads the given tag rule.
+func (x *stdTagRule) LoadTagRuleByID(id string) (*domain.TagRule, errs.Error) {
+	r, err := x.tagRuleRepo.LoadTagRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := x.tagRuleRepo.LoadTagRuleTagsByID(r.ID)
+	if err != nil {
+		return nil, err
+	}
+	r.Tags = tags
+	return r, nil
+}
+
+// LoadTagRulesByProfileID loads all tag rules belonging to the given profile, in the order they are applied.
+func (x *stdTagRule) LoadTagRulesByProfileID(id string) ([]*domain.TagRule, errs.Error) {
+	return loadTagRules(x.tagRuleRepo, id)
+}
+
+// CreateTagRule creates the given tag rule after the existing rules in its profile.
+func (x *stdTagRule) CreateTagRule(rule *domain.TagRule) errs.Error {
+	if rule.ID == "" {
+		rule.ID = "rul:" + uuid.New().String()
+	}
+	if rule.HasAmountRange() && rule.Currency == "" {
+		// Default to the currency of the profile.
+		profile, err := x.profileRepo.LoadProfileByID(rule.ProfileID)
+		if err != nil {
+			return err
+		}
+		rule.Currency = profile.Currency
+	}
+	now := time.Now().UTC()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, rule.ProfileID, rule.Tags)
+		if err != nil {
+			return err
+		}
+		rule.Tags = tags
+		if err := x.validator.TagRule(rule); err != nil {
+			return err
+		}
+		existing, err := repos.TagRule.LoadTagRulesByProfileID(rule.ProfileID)
+		if err != nil {
+			return err
+		}
+		rule.Position = len(existing) + 1
+		if err := repos.TagRule.CreateTagRule(rule); err != nil {
+			return err
+		}
+		return repos.TagRule.AddTagRuleTags(rule.ID, rule.Tags...)
+	})
+}
+
+// UpdateTagRule updates the given tag rule, but does not change its position.
+func (x *stdTagRule) UpdateTagRule(rule *domain.TagRule) errs.Error {
+	rule.UpdatedAt = time.Now().UTC()
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		tags, err := normaliseTags(repos, rule.ProfileID, rule.Tags)
+		if err != nil {
+			return err
+		}
+		rule.Tags = tags
+		if err := x.validator.TagRule(rule); err != nil {
+			return err
+		}
+		existing, err := repos.TagRule.LoadTagRuleByID(rule.ID)
+		if err != nil {
+			return err
+		}
+		rule.Position = existing.Position
+		if err := repos.TagRule.UpdateTagRule(rule); err != nil {
+			return err
+		}
+		if err := repos.TagRule.ClearTagRuleTags(rule.ID); err != nil {
+			return err
+		}
+		return repos.TagRule.AddTagRuleTags(rule.ID, rule.Tags...)
+	})
+}
+
+// MoveTagRule moves the given tag rule to the given position, starting at 1, shifting the rules in between.
+// Positions past the last rule move the rule to the end.
+func (x *stdTagRule) MoveTagRule(id string, position int) errs.Error {
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		rule, err := repos.TagRule.LoadTagRuleByID(id)
+		if err != nil {
+			return err
+		}
+		rules, err := repos.TagRule.LoadTagRulesByProfileID(rule.ProfileID)
+		if err != nil {
+			return err
+		}
+
+		ordered := make([]*domain.TagRule, 0, len(rules))
+		for _, r := range rules {
+			if r.ID != id {
+				ordered = append(ordered, r)
+			}
+		}
+		index := position - 1
+		if index < 0 {
+			index = 0
+		}
+		if index > len(ordered) {
+			index = len(ordered)
+		}
+		ordered = append(ordered[:index], append([]*domain.TagRule{rule}, ordered[index:]...)...)
+
+		return renumberTagRules(repos, ordered)
+	})
+}
+
+// DeleteTagRule deletes the given tag rule.
+func (x *stdTagRule) DeleteTagRule(id string) errs.Error {
+	return x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		rule, err := repos.TagRule.LoadTagRuleByID(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.TagRule.DeleteTagRule(id); err != nil {
+			return err
+		}
+		rules, err := repos.TagRule.LoadTagRulesByProfileID(rule.ProfileID)
+		if err != nil {
+			return err
+		}
+		return renumberTagRules(repos, rules)
+	})
+}
+
+// renumberTagRules updates the position of each of the given rules to match their order.
+func renumberTagRules(repos repository.Repositories, rules []*domain.TagRule) errs.Error {
+	now := time.Now().UTC()
+	for i, r := range rules {
+		if r.Position == i+1 {
+			continue
+		}
+		r.Position = i + 1
+		r.UpdatedAt = now
+		if err := repos.TagRule.UpdateTagRule(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyTagRules applies the given profiles tag rules to its existing transactions within the given date range,
+// and returns the tags added to each transaction that changed.
+// Nothing is saved if dryRun is true.
+func (x *stdTagRule) ApplyTagRules(profileID string, dateRange domain.DateRange, dryRun bool) ([]*domain.TagChange, errs.Error) {
+	changes := make([]*domain.TagChange, 0)
+	err := x.unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+		rules, err := loadTagRules(repos.TagRule, profileID)
+		if err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		transactions, err := repos.Transaction.LoadTransactionsByProfileID(profileID, dateRange)
+		if err != nil {
+			return err
+		}
+		for _, t := range transactions {
+			tags, err := repos.Transaction.LoadTransactionTagsByID(t.ID)
+			if err != nil {
+				return err
+			}
+			t.Tags = tags
+			added := domain.ApplyTagRules(rules, t)
+			if len(added) == 0 {
+				continue
+			}
+			changes = append(changes, &domain.TagChange{Transaction: t, Added: added})
+			if dryRun {
+				continue
+			}
+			if err := repos.Transaction.AddTransactionTags(t.ID, added...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStdTag_RenameTag_Envelopes(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	profileRepo := repository.NewSQLiteProfile(db)
+	unitOfWork := repository.NewSQLiteUnitOfWork(db)
+	validator := validate.NewValidator(profileRepo, repository.NewSQLiteTransaction(db))
+	profileService := newProfileService(db)
+	envelopeService := service.NewEnvelopeService(unitOfWork, profileRepo, repository.NewSQLiteEnvelope(db), validator)
+	tagService := service.NewTagService(unitOfWork, profileRepo, repository.NewSQLiteTag(db), validator)
+
+	profile := domain.NewProfile()
+	profile.Name = "tom"
+	profile.Currency = "GBP"
+	if err := profileService.CreateProfile(profile); err != nil {
+		t.Fatalf("could not create profile: %s", err)
+	}
+	for _, tag := range []string{"transport/rail", "travel"} {
+		transaction := domain.NewTransaction().WithProfileID(profile.ID).WithLabel("Ticket").WithAmount(-1000).
+			WithDate(time.Now().UTC()).WithTags(tag)
+		if err := profileService.CreateTransaction(transaction); err != nil {
+			t.Fatalf("could not create transaction: %s", err)
+		}
+	}
+	for _, tag := range []string{"transport", "transport/rail", "travel/rail"} {
+		envelope := domain.NewEnvelope()
+		envelope.ProfileID = profile.ID
+		envelope.Tag = tag
+		envelope.Amount = 10000
+		if err := envelopeService.CreateEnvelope(envelope); err != nil {
+			t.Fatalf("could not create envelope: %s", err)
+		}
+	}
+	envelopeTags := func() []string {
+		envelopes, err := envelopeService.LoadEnvelopesByProfileID(profile.ID)
+		if err != nil {
+			t.Fatalf("could not load envelopes: %s", err)
+		}
+		tags := make([]string, len(envelopes))
+		for i, e := range envelopes {
+			tags[i] = e.Tag
+		}
+		return tags
+	}
+
+	_, err := tagService.RenameTag(profile.ID, "transport", "travel")
+	if err == nil || err.Code() != errs.ErrEnvelopeExists {
+		t.Fatalf("expected an envelope exists error, got %v", err)
+	}
+	if exp := []string{"transport", "transport/rail", "travel/rail"}; !reflect.DeepEqual(exp, envelopeTags()) {
+		t.Errorf("expected envelopes to be unchanged, got %v", envelopeTags())
+	}
+	if count, err := tagService.CountTaggedTransactions(profile.ID, "transport"); err != nil || count != 1 {
+		t.Errorf("expected transactions to be unchanged, got %d: %v", count, err)
+	}
+
+	changed, dropped, err := tagService.MergeTag(profile.ID, "transport", "travel")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if changed != 1 {
+		t.Errorf("expected 1 transaction to change, got %d", changed)
+	}
+	if len(dropped) != 1 || dropped[0].Tag != "transport/rail" {
+		t.Errorf("expected the transport/rail envelope to be dropped, got %v", dropped)
+	}
+	if exp := []string{"travel", "travel/rail"}; !reflect.DeepEqual(exp, envelopeTags()) {
+		t.Errorf("expected envelopes %v, got %v", exp, envelopeTags())
+	}
+}
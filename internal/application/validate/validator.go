@@ -5,6 +5,7 @@ import (
 	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/errs"
 	"github.com/tomwright/finance-planner/internal/repository"
+	"math"
 	"net/http"
 )
 
@@ -13,6 +14,20 @@ type Validator interface {
 	Profile(profile *domain.Profile) errs.Error
 	// Transaction validates the given transaction
 	Transaction(transaction *domain.Transaction) errs.Error
+	// Schedule validates the given schedule
+	Schedule(schedule *domain.Schedule) errs.Error
+	// ExchangeRate validates the given exchange rate
+	ExchangeRate(rate *domain.ExchangeRate) errs.Error
+	// Account validates the given account
+	Account(account *domain.Account) errs.Error
+	// Transfer validates the given transfer
+	Transfer(transfer *domain.Transfer) errs.Error
+	// TagRule validates the given tag rule
+	TagRule(rule *domain.TagRule) errs.Error
+	// Tag validates the given tag
+	Tag(tag string) errs.Error
+	// Envelope validates the given envelope
+	Envelope(envelope *domain.Envelope) errs.Error
 }
 
 func NewValidator(profileRepo repository.Profile, transactionRepo repository.Transaction) Validator {
@@ -41,6 +56,15 @@ func (x *stdValidator) Profile(profile *domain.Profile) errs.Error {
 			WithMessage("missing profile name").
 			WithStatusCode(http.StatusBadRequest)
 	}
+	if err := x.currency(profile.Currency, "profile"); err != nil {
+		return err
+	}
+	if !profile.TagCase.Valid() {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagCase).
+			WithMessage(fmt.Sprintf("unknown profile tag case `%s`: expected %s or %s", profile.TagCase, domain.TagCaseLower, domain.TagCasePreserve)).
+			WithStatusCode(http.StatusBadRequest)
+	}
 	return nil
 }
 
@@ -64,15 +88,313 @@ func (x *stdValidator) Transaction(transaction *domain.Transaction) errs.Error {
 			WithMessage("transaction amount must not be 0").
 			WithStatusCode(http.StatusBadRequest)
 	}
-	if len(transaction.Tags) > 0 {
-		for i, t := range transaction.Tags {
-			if t == "" {
-				return errs.New().
-					WithCode(errs.ErrInvalidTag).
-					WithMessage(fmt.Sprintf("transaction tag [%d] must not be empty", i)).
-					WithStatusCode(http.StatusBadRequest)
-			}
+	if err := x.currency(transaction.Currency, "transaction"); err != nil {
+		return err
+	}
+	if transaction.Date.IsZero() {
+		return errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("missing transaction date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return x.tags(transaction.Tags, "transaction")
+}
+
+// Schedule validates the given schedule
+func (x *stdValidator) Schedule(schedule *domain.Schedule) errs.Error {
+	if schedule.ID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidScheduleID).
+			WithMessage("missing schedule id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.ProfileID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidProfileID).
+			WithMessage("missing schedule profile id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.Label == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidLabel).
+			WithMessage("missing schedule label").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.Amount == 0 {
+		return errs.New().
+			WithCode(errs.ErrInvalidAmount).
+			WithMessage("schedule amount must not be 0").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if err := x.currency(schedule.Currency, "schedule"); err != nil {
+		return err
+	}
+	if !schedule.Frequency.Valid() {
+		return errs.New().
+			WithCode(errs.ErrInvalidFrequency).
+			WithMessage(fmt.Sprintf("unknown schedule frequency `%s`", schedule.Frequency)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.Interval < 1 {
+		return errs.New().
+			WithCode(errs.ErrInvalidInterval).
+			WithMessage("schedule interval must be at least 1").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.Frequency == domain.FrequencyMonthly && (schedule.DayOfMonth < 1 || schedule.DayOfMonth > 31) {
+		return errs.New().
+			WithCode(errs.ErrInvalidDayOfMonth).
+			WithMessage("schedule day of month must be between 1 and 31").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if schedule.Start.IsZero() {
+		return errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("missing schedule start date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if !schedule.End.IsZero() && schedule.End.Before(schedule.Start) {
+		return errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("schedule end date must not be before the start date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return x.tags(schedule.Tags, "schedule")
+}
+
+// ExchangeRate validates the given exchange rate
+func (x *stdValidator) ExchangeRate(rate *domain.ExchangeRate) errs.Error {
+	if err := x.currency(rate.From, "exchange rate from"); err != nil {
+		return err
+	}
+	if err := x.currency(rate.To, "exchange rate to"); err != nil {
+		return err
+	}
+	if rate.From == rate.To {
+		return errs.New().
+			WithCode(errs.ErrInvalidExchangeRate).
+			WithMessage("exchange rate must be between different currencies").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if rate.Date.IsZero() {
+		return errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("missing exchange rate date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if !(rate.Rate > 0) || math.IsInf(rate.Rate, 0) {
+		return errs.New().
+			WithCode(errs.ErrInvalidExchangeRate).
+			WithMessage("exchange rate must be a positive number").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return nil
+}
+
+// Account validates the given account
+func (x *stdValidator) Account(account *domain.Account) errs.Error {
+	if account.ID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidAccountID).
+			WithMessage("missing account id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if account.ProfileID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidProfileID).
+			WithMessage("missing account profile id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if account.Name == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidName).
+			WithMessage("missing account name").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if err := x.currency(account.Currency, "account"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Transfer validates the given transfer
+func (x *stdValidator) Transfer(transfer *domain.Transfer) errs.Error {
+	if transfer.From == nil || transfer.To == nil {
+		return errs.New().
+			WithCode(errs.ErrInvalidTransfer).
+			WithMessage("transfer must have a from and to account").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.From.ID == transfer.To.ID {
+		return errs.New().
+			WithCode(errs.ErrInvalidTransfer).
+			WithMessage("transfer must be between different accounts").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.From.ProfileID != transfer.To.ProfileID {
+		return errs.New().
+			WithCode(errs.ErrInvalidTransfer).
+			WithMessage("transfer must be between accounts in the same profile").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.Amount.Amount <= 0 || transfer.Received.Amount <= 0 {
+		return errs.New().
+			WithCode(errs.ErrInvalidAmount).
+			WithMessage("transfer amount must be greater than 0").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.Amount.Currency != transfer.From.Currency {
+		return errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("transfer amount must be in %s, the currency of %s", transfer.From.Currency, transfer.From.Name)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.Received.Currency != transfer.To.Currency {
+		return errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("transfer received amount must be in %s, the currency of %s", transfer.To.Currency, transfer.To.Name)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if transfer.Date.IsZero() {
+		return errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("missing transfer date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return x.tags(transfer.Tags, "transfer")
+}
+
+// TagRule validates the given tag rule
+func (x *stdValidator) TagRule(rule *domain.TagRule) errs.Error {
+	if rule.ID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagRuleID).
+			WithMessage("missing tag rule id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if rule.ProfileID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidProfileID).
+			WithMessage("missing tag rule profile id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if rule.LabelContains == "" && rule.LabelPattern == "" && !rule.HasAmountRange() && rule.AccountID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagRule).
+			WithMessage("tag rule must have at least one condition").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if _, err := rule.Pattern(); err != nil {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagRule).
+			WithMessage(fmt.Sprintf("invalid tag rule label pattern: %s", err.Error())).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if rule.HasAmountRange() {
+		if err := x.currency(rule.Currency, "tag rule"); err != nil {
+			return err
+		}
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagRule).
+			WithMessage("tag rule minimum amount must not be more than the maximum amount").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if len(rule.Tags) == 0 {
+		return errs.New().
+			WithCode(errs.ErrInvalidTagRule).
+			WithMessage("tag rule must add at least one tag").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return x.tags(rule.Tags, "tag rule")
+}
+
+// Tag validates the given tag
+func (x *stdValidator) Tag(tag string) errs.Error {
+	if tag == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidTag).
+			WithMessage("tag must not be empty").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	for _, part := range domain.TagParts(tag) {
+		if part == "" {
+			return errs.New().
+				WithCode(errs.ErrInvalidTag).
+				WithMessage(fmt.Sprintf("tag `%s` must not have empty parts between %s", tag, domain.TagSeparator)).
+				WithStatusCode(http.StatusBadRequest)
 		}
 	}
 	return nil
 }
+
+// Envelope validates the given envelope
+func (x *stdValidator) Envelope(envelope *domain.Envelope) errs.Error {
+	if envelope.ID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidEnvelopeID).
+			WithMessage("missing envelope id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if envelope.ProfileID == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidProfileID).
+			WithMessage("missing envelope profile id").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if err := x.Tag(envelope.Tag); err != nil {
+		return err
+	}
+	if envelope.Amount <= 0 {
+		return errs.New().
+			WithCode(errs.ErrInvalidEnvelope).
+			WithMessage("envelope amount must be more than zero").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if err := x.currency(envelope.Currency, "envelope"); err != nil {
+		return err
+	}
+	if !envelope.Period.Valid() {
+		return errs.New().
+			WithCode(errs.ErrInvalidEnvelope).
+			WithMessage(fmt.Sprintf("unknown envelope period `%s`: expected %s, %s or %s", envelope.Period,
+				domain.EnvelopePeriodWeekly, domain.EnvelopePeriodMonthly, domain.EnvelopePeriodYearly)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if envelope.Start.IsZero() {
+		return errs.New().
+			WithCode(errs.ErrInvalidEnvelope).
+			WithMessage("missing envelope start date").
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return nil
+}
+
+// tags validates the tags of the given type of object.
+// Hierarchical tags must not have any empty parts, such as transport//rail or transport/.
+func (x *stdValidator) tags(tags []string, of string) errs.Error {
+	for i, t := range tags {
+		if err := x.Tag(t); err != nil {
+			return err.PrefixMessage(fmt.Sprintf("%s tag [%d]: ", of, i))
+		}
+	}
+	return nil
+}
+
+// currency validates the currency of the given type of object.
+func (x *stdValidator) currency(currency domain.Currency, of string) errs.Error {
+	if currency == "" {
+		return errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("missing %s currency", of)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	if !currency.Valid() {
+		return errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("unknown %s currency `%s`", of, currency)).
+			WithStatusCode(http.StatusBadRequest)
+	}
+	return nil
+}
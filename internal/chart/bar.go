@@ -0,0 +1,76 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Bar is a single bar in a BarChart.
+type Bar struct {
+	// Label is shown before the bar.
+	Label string
+	// Value is the length of the bar. Negative values are drawn as an empty bar.
+	Value int64
+	// Text is shown after the bar, such as the formatted value.
+	Text string
+}
+
+// BarChart draws a horizontal bar for each of its bars, scaled so the longest bar fills the chart.
+type BarChart struct {
+	// Title is shown above the chart if it is not empty.
+	Title string
+	// Bars contains the bars in the order they are drawn.
+	Bars []Bar
+}
+
+// Render writes the chart to w, fitting it within the given number of columns where possible.
+func (x *BarChart) Render(w io.Writer, width int, charset Charset) error {
+	labels := make([]string, len(x.Bars))
+	texts := make([]string, len(x.Bars))
+	var max int64
+	for i, b := range x.Bars {
+		labels[i] = b.Label
+		texts[i] = b.Text
+		if b.Value > max {
+			max = b.Value
+		}
+	}
+	labelWidth := maxWidth(labels...)
+	barWidth := width - labelWidth - maxWidth(texts...) - 3
+	if barWidth < minPlotWidth {
+		barWidth = minPlotWidth
+	}
+
+	lines := make([]string, 0, len(x.Bars)+1)
+	if x.Title != "" {
+		lines = append(lines, x.Title)
+	}
+	for _, b := range x.Bars {
+		bar := drawBar(b.Value, max, barWidth, charset)
+		line := padRight(b.Label, labelWidth) + " " + string(charset.Vertical) + padRight(bar, barWidth) + " " + b.Text
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// drawBar returns a bar for value that is width columns long when value is max.
+func drawBar(value int64, max int64, width int, charset Charset) string {
+	if value <= 0 || max <= 0 {
+		return ""
+	}
+	steps := len(charset.Bar)
+	units := int(math.Round(float64(value) / float64(max) * float64(width*steps)))
+	if units == 0 {
+		// Always show something for values that are not zero.
+		units = 1
+	}
+	bar := strings.Repeat(string(charset.Bar[steps-1]), units/steps)
+	if rem := units % steps; rem > 0 {
+		bar += string(charset.Bar[rem-1])
+	}
+	return bar
+}
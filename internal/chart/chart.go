@@ -0,0 +1,171 @@
+// Package chart draws simple charts as text for display in a terminal, using Unicode block characters
+// or plain ASCII.
+package chart
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWidth is the width used when the width of the terminal cannot be detected.
+const DefaultWidth = 80
+
+// minPlotWidth is the fewest columns used to plot values, even if the chart is wider than requested.
+const minPlotWidth = 10
+
+// Charset contains the characters used to draw charts.
+type Charset struct {
+	// Name is used to choose the charset.
+	Name string
+	// Bar contains the characters used to draw the end of a horizontal bar, from the smallest
+	// fraction of a column to a full column.
+	Bar []rune
+	// Levels contains the characters used to draw a value within a single row, from the lowest to the highest.
+	Levels []rune
+	// Horizontal is used to draw the horizontal axis.
+	Horizontal rune
+	// Vertical is used to draw the vertical axis.
+	Vertical rune
+	// Corner is used where the axes meet.
+	Corner rune
+}
+
+var (
+	// Unicode draws charts using Unicode block and box drawing characters.
+	Unicode = Charset{
+		Name:       "unicode",
+		Bar:        []rune("▏▎▍▌▋▊▉█"),
+		Levels:     []rune("▁▂▃▄▅▆▇█"),
+		Horizontal: '─',
+		Vertical:   '│',
+		Corner:     '└',
+	}
+	// ASCII draws charts using plain ASCII characters, for terminals that cannot show Unicode.
+	ASCII = Charset{
+		Name:       "ascii",
+		Bar:        []rune("#"),
+		Levels:     []rune("_.-~^"),
+		Horizontal: '-',
+		Vertical:   '|',
+		Corner:     '+',
+	}
+)
+
+// Charsets contains all of the available charsets.
+var Charsets = []Charset{
+	Unicode,
+	ASCII,
+}
+
+// CharsetByName returns the charset with the given name.
+// False is returned if there is no charset with the name.
+func CharsetByName(name string) (Charset, bool) {
+	for _, c := range Charsets {
+		if c.Name == strings.ToLower(name) {
+			return c, true
+		}
+	}
+	return Charset{}, false
+}
+
+// DetectCharset returns Unicode if the locale given in the environment uses UTF-8, otherwise ASCII.
+// The locale is read from LC_ALL, LC_CTYPE and LANG, in that order.
+func DetectCharset() Charset {
+	for _, key := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		value := strings.ToLower(os.Getenv(key))
+		if value == "" {
+			continue
+		}
+		if strings.Contains(value, "utf-8") || strings.Contains(value, "utf8") {
+			return Unicode
+		}
+		return ASCII
+	}
+	return ASCII
+}
+
+// TerminalWidth returns the number of columns in the terminal attached to f.
+// The COLUMNS environment variable is used if f is not a terminal, and DefaultWidth if that is not set.
+func TerminalWidth(f *os.File) int {
+	if columns := terminalColumns(f); columns > 0 {
+		return columns
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return DefaultWidth
+}
+
+// textWidth returns the number of columns used to show s.
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s)
+}
+
+// padRight pads s with spaces up to the given width.
+func padRight(s string, width int) string {
+	if n := width - textWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// padLeft pads s with leading spaces up to the given width.
+func padLeft(s string, width int) string {
+	if n := width - textWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// maxWidth returns the width of the widest of the given strings.
+func maxWidth(values ...string) int {
+	res := 0
+	for _, v := range values {
+		if w := textWidth(v); w > res {
+			res = w
+		}
+	}
+	return res
+}
+
+// scale returns the position of value between min and max on a scale from 0 to steps-1.
+// 0 is returned if min and max are the same.
+func scale(value int64, min int64, max int64, steps int) int {
+	if max <= min || steps < 2 {
+		return 0
+	}
+	return int(math.Round(float64(value-min) / float64(max-min) * float64(steps-1)))
+}
+
+// bounds returns the lowest and highest of the given values.
+func bounds(values []int64) (int64, int64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	min, max := values[0], values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+// resample returns the given values stretched or shrunk to exactly n values.
+// When shrinking, each value is the last of the values it replaces.
+func resample(values []int64, n int) []int64 {
+	if len(values) == 0 || n <= 0 {
+		return []int64{}
+	}
+	res := make([]int64, n)
+	for i := range res {
+		res[i] = values[((i+1)*len(values)-1)/n]
+	}
+	return res
+}
@@ -0,0 +1,106 @@
+package chart_test
+
+import (
+	"bytes"
+	"github.com/tomwright/finance-planner/internal/chart"
+	"testing"
+)
+
+func TestSparkline(t *testing.T) {
+	t.Parallel()
+
+	values := []int64{-100, 0, 250, 600, 600}
+	if exp, got := "▁▂▅██", chart.Sparkline(values, chart.Unicode); exp != got {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+	if exp, got := "_.-^^", chart.Sparkline(values, chart.ASCII); exp != got {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+	if exp, got := "▁▁▁", chart.Sparkline([]int64{5, 5, 5}, chart.Unicode); exp != got {
+		t.Errorf("expected %q for equal values, got %q", exp, got)
+	}
+}
+
+func TestBarChart_Render(t *testing.T) {
+	t.Parallel()
+
+	c := &chart.BarChart{
+		Title: "Spending",
+		Bars: []chart.Bar{
+			{Label: "bills", Value: 1000, Text: "£10.00"},
+			{Label: "food", Value: 270, Text: "£2.70"},
+			{Label: "refunds", Value: -100, Text: "-£1.00"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.Render(buf, 28, chart.Unicode); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := "Spending\n" +
+		"bills   │████████████ £10.00\n" +
+		"food    │███▎         £2.70\n" +
+		"refunds │             -£1.00\n"
+	if got := buf.String(); exp != got {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+
+	buf.Reset()
+	if err := c.Render(buf, 28, chart.ASCII); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp = "Spending\n" +
+		"bills   |############ £10.00\n" +
+		"food    |###          £2.70\n" +
+		"refunds |             -£1.00\n"
+	if got := buf.String(); exp != got {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
+
+func TestSparklineChart_Render(t *testing.T) {
+	t.Parallel()
+
+	c := &chart.SparklineChart{
+		Series: []chart.Series{
+			{Label: "In", Values: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, Text: "12"},
+			{Label: "Out", Values: []int64{3, 2, 1}, Text: "1"},
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.Render(buf, 18, chart.ASCII); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Only the most recent values fit.
+	exp := "In  __..---~~^^ 12\n" +
+		"Out ^-_         1\n"
+	if got := buf.String(); exp != got {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
+
+func TestLineChart_Render(t *testing.T) {
+	t.Parallel()
+
+	c := &chart.LineChart{
+		Title:  "Balance",
+		Values: []int64{0, 10, 20, 30, 40, 50},
+		Height: 2,
+		Start:  "Mon",
+		End:    "Sat",
+	}
+
+	buf := &bytes.Buffer{}
+	if err := c.Render(buf, 15, chart.Unicode); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	exp := "Balance\n" +
+		"50 │     ▂▂▅▅██\n" +
+		" 0 │▁▄▄▇▇\n" +
+		"   └───────────\n" +
+		"   Mon      Sat\n"
+	if got := buf.String(); exp != got {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, got)
+	}
+}
@@ -0,0 +1,85 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultHeight is the number of rows used by a LineChart without a Height.
+const DefaultHeight = 10
+
+// LineChart draws a sequence of values as a line, scaled between the lowest and highest value.
+type LineChart struct {
+	// Title is shown above the chart if it is not empty.
+	Title string
+	// Values contains the values in order.
+	Values []int64
+	// Height is the number of rows used to draw the line. DefaultHeight is used if it is 0.
+	Height int
+	// Start and End label the first and last values on the horizontal axis.
+	Start string
+	End   string
+	// Format formats the lowest and highest values on the vertical axis. Values are shown as they are if it is nil.
+	Format func(value int64) string
+}
+
+// Render writes the chart to w, fitting it within the given number of columns where possible.
+// The values are stretched or shrunk to fill the width of the chart.
+func (x *LineChart) Render(w io.Writer, width int, charset Charset) error {
+	format := x.Format
+	if format == nil {
+		format = func(value int64) string {
+			return fmt.Sprint(value)
+		}
+	}
+	height := x.Height
+	if height <= 0 {
+		height = DefaultHeight
+	}
+
+	min, max := bounds(x.Values)
+	top, bottom := format(max), format(min)
+	labelWidth := maxWidth(top, bottom)
+	plotWidth := width - labelWidth - 2
+	if plotWidth < minPlotWidth {
+		plotWidth = minPlotWidth
+	}
+
+	// Each row is split into a level for each character, so the line can rise and fall within a row.
+	levels := len(charset.Levels)
+	rows := make([][]rune, height)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+	if len(x.Values) > 0 {
+		for col, v := range resample(x.Values, plotWidth) {
+			pos := scale(v, min, max, height*levels)
+			rows[height-1-pos/levels][col] = charset.Levels[pos%levels]
+		}
+	}
+
+	lines := make([]string, 0, height+3)
+	if x.Title != "" {
+		lines = append(lines, x.Title)
+	}
+	for i, row := range rows {
+		label := ""
+		switch i {
+		case 0:
+			label = top
+		case height - 1:
+			label = bottom
+		}
+		lines = append(lines, strings.TrimRight(padLeft(label, labelWidth)+" "+string(charset.Vertical)+string(row), " "))
+	}
+	indent := strings.Repeat(" ", labelWidth+1)
+	lines = append(lines, indent+string(charset.Corner)+strings.Repeat(string(charset.Horizontal), plotWidth))
+	if x.Start != "" || x.End != "" {
+		axis := x.Start + padLeft(x.End, plotWidth+1-textWidth(x.Start))
+		lines = append(lines, strings.TrimRight(indent+axis, " "))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,75 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Sparkline returns a single character for each of the given values, with the height of each character scaled
+// between the lowest and highest value.
+func Sparkline(values []int64, charset Charset) string {
+	min, max := bounds(values)
+	res := make([]rune, len(values))
+	for i, v := range values {
+		res[i] = charset.Levels[scale(v, min, max, len(charset.Levels))]
+	}
+	return string(res)
+}
+
+// Series is a labelled sequence of values.
+type Series struct {
+	// Label is shown before the values.
+	Label string
+	// Values contains the values in order.
+	Values []int64
+	// Text is shown after the values, such as the range they cover.
+	Text string
+}
+
+// SparklineChart draws a sparkline for each of its series, one per line.
+type SparklineChart struct {
+	// Title is shown above the chart if it is not empty.
+	Title string
+	// Series contains the series in the order they are drawn.
+	Series []Series
+}
+
+// Render writes the chart to w, fitting it within the given number of columns where possible.
+// Only the most recent values of each series are drawn if there are more values than columns available.
+func (x *SparklineChart) Render(w io.Writer, width int, charset Charset) error {
+	labels := make([]string, len(x.Series))
+	texts := make([]string, len(x.Series))
+	longest := 0
+	for i, s := range x.Series {
+		labels[i] = s.Label
+		texts[i] = s.Text
+		if len(s.Values) > longest {
+			longest = len(s.Values)
+		}
+	}
+	labelWidth := maxWidth(labels...)
+	plotWidth := width - labelWidth - maxWidth(texts...) - 2
+	if plotWidth < minPlotWidth {
+		plotWidth = minPlotWidth
+	}
+	if longest < plotWidth {
+		plotWidth = longest
+	}
+
+	lines := make([]string, 0, len(x.Series)+1)
+	if x.Title != "" {
+		lines = append(lines, x.Title)
+	}
+	for _, s := range x.Series {
+		values := s.Values
+		if len(values) > plotWidth {
+			values = values[len(values)-plotWidth:]
+		}
+		line := padRight(s.Label, labelWidth) + " " + padRight(Sparkline(values, charset), plotWidth) + " " + s.Text
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package chart
+
+import (
+	"os"
+)
+
+// terminalColumns returns 0 as the size of the terminal cannot be detected on this platform.
+func terminalColumns(f *os.File) int {
+	return 0
+}
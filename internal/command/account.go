@@ -0,0 +1,230 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+)
+
+func Account(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "account",
+		Short: "Manage the accounts within a profile",
+	}
+
+	cmd.AddCommand(AddAccount(profileService, accountService))
+	cmd.AddCommand(ListAccounts(profileService, accountService))
+	cmd.AddCommand(RenameAccount(profileService, accountService))
+	cmd.AddCommand(CloseAccount(profileService, accountService))
+
+	return cmd
+}
+
+func AddAccount(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add an account to the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+
+			profile, err := loadOrCreateProfileFlag(cmd, profileService)
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", profile.Currency)
+			if err != nil {
+				return err
+			}
+			openingBalance, err := getMoneyFlag(cmd, "opening-balance", currency)
+			if err != nil {
+				return err
+			}
+
+			account := domain.NewAccount()
+			account.ProfileID = profile.ID
+			account.Name = name
+			account.Currency = currency
+			account.OpeningBalance = openingBalance.Amount
+
+			if err := accountService.CreateAccount(account); err != nil {
+				return err
+			}
+
+			fmt.Printf("added account %s with an opening balance of %s\n", account.Name, openingBalance)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("name", "", "Account name, such as Current or Savings")
+	cmd.Flags().String("currency", "", "Account currency, defaults to the profile currency")
+	cmd.Flags().String("opening-balance", "", "Balance of the account before any transactions, such as 1250.00")
+	addCreateProfileFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func ListAccounts(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the accounts in the profile along with their balances",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			all, _ := cmd.Flags().GetBool("all")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			accounts, err := accountService.LoadAccountsByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable("Accounts",
+				render.Column{Key: "id", Header: "ID"},
+				render.Column{Key: "name", Header: "Name"},
+				render.Column{Key: "currency", Header: "Currency"},
+				render.Column{Key: "opening_balance", Header: "Opening balance"},
+				render.Column{Key: "balance", Header: "Balance"},
+				render.Column{Key: "status", Header: "Status"},
+			)
+
+			balances := domain.NewTransactionCollection()
+			for _, a := range accounts {
+				if a.Closed && !all {
+					continue
+				}
+				status := "open"
+				if a.Closed {
+					status = "closed"
+				}
+				balance := a.Balance(profile.Transactions)
+				balances.Add(domain.NewTransaction().WithAmount(balance.Amount).WithCurrency(balance.Currency))
+				table.Append(
+					render.Text(a.ID),
+					render.Text(a.Name),
+					render.Text(string(a.Currency)),
+					render.Money(domain.NewMoney(a.OpeningBalance, a.Currency)),
+					render.Money(balance),
+					render.Text(status),
+				)
+			}
+			table.Footer = []string{"", "", "", "Total", formatTotals(balances.Totals()), ""}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addOutputFlag(cmd)
+	cmd.Flags().Bool("all", false, "Include closed accounts")
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func RenameAccount(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Rename an account in the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+
+			account, err := loadAccountFromFlags(cmd, profileService, accountService, "account")
+			if err != nil {
+				return err
+			}
+
+			oldName := account.Name
+			account.Name = name
+			if err := accountService.UpdateAccount(account); err != nil {
+				return err
+			}
+
+			fmt.Printf("renamed account %s to %s\n", oldName, account.Name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("account", "", "Name of the account to rename")
+	cmd.Flags().String("name", "", "New account name")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("account")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func CloseAccount(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close",
+		Short: "Close an account in the profile",
+		Long: `Close an account in the profile.
+
+Closed accounts keep their transactions, but new transactions and transfers cannot use them.
+Closed accounts are hidden from account list unless --all is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			account, err := loadAccountFromFlags(cmd, profileService, accountService, "account")
+			if err != nil {
+				return err
+			}
+
+			account.Closed = true
+			if err := accountService.UpdateAccount(account); err != nil {
+				return err
+			}
+
+			fmt.Printf("closed account %s\n", account.Name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("account", "", "Name of the account to close")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("account")
+
+	return cmd
+}
+
+// loadAccountFromFlags loads the account named in the given flag that belongs to the profile named
+// in the --profile flag.
+func loadAccountFromFlags(cmd *cobra.Command, profileService service.Profile, accountService service.Account, name string) (*domain.Account, errs.Error) {
+	profileName, _ := cmd.Flags().GetString("profile")
+	profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+	if err != nil {
+		return nil, err
+	}
+	return loadAccountFlag(cmd, accountService, profile, name)
+}
+
+// loadAccountFlag loads the account named in the given flag that belongs to the given profile.
+// nil is returned if the flag is empty.
+func loadAccountFlag(cmd *cobra.Command, accountService service.Account, profile *domain.Profile, name string) (*domain.Account, errs.Error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return nil, nil
+	}
+	account, err := accountService.LoadAccountByName(profile.ID, value)
+	if err != nil && err.Code() == errs.ErrUnknownAccount {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownAccount).
+			WithMessage(fmt.Sprintf("unknown %s `%s` in profile %s", name, value, profile.Name))
+	}
+	return account, err
+}
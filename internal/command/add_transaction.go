@@ -6,17 +6,37 @@ import (
 	"github.com/tomwright/finance-planner/internal/application/service"
 )
 
-func AddTransaction(profileService service.Profile) *cobra.Command {
+func AddTransaction(profileService service.Profile, accountService service.Account) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add-transaction",
 		Short: "Add a transaction to the profile",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			profileName, _ := cmd.Flags().GetString("profile")
 			label, _ := cmd.Flags().GetString("label")
-			amount, _ := cmd.Flags().GetInt64("amount")
 			tags, _ := cmd.Flags().GetStringArray("tags")
+			date, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
 
-			profile, err := profileService.LoadOrCreateProfileByName(profileName)
+			profile, err := loadOrCreateProfileFlag(cmd, profileService)
+			if err != nil {
+				return err
+			}
+
+			account, err := loadAccountFlag(cmd, accountService, profile, "account")
+			if err != nil {
+				return err
+			}
+
+			defaultCurrency := profile.Currency
+			if account != nil {
+				defaultCurrency = account.Currency
+			}
+			currency, err := getCurrencyFlag(cmd, "currency", defaultCurrency)
+			if err != nil {
+				return err
+			}
+			amount, err := getMoneyFlag(cmd, "amount", currency)
 			if err != nil {
 				return err
 			}
@@ -24,9 +44,16 @@ func AddTransaction(profileService service.Profile) *cobra.Command {
 			// create the transaction.
 			t := domain.NewTransaction()
 			t.Label = label
-			t.Amount = amount
+			t.Amount = amount.Amount
+			t.Currency = amount.Currency
 			t.ProfileID = profile.ID
+			if account != nil {
+				t.AccountID = account.ID
+			}
 			t.Tags = tags
+			if !date.IsZero() {
+				t.WithDate(date)
+			}
 
 			// save the profile.
 			if err := profileService.CreateTransaction(t); err != nil {
@@ -39,8 +66,12 @@ func AddTransaction(profileService service.Profile) *cobra.Command {
 
 	cmd.Flags().String("profile", "", "Profile to interact with")
 	cmd.Flags().String("label", "", "Transaction label")
-	cmd.Flags().Int64("amount", 0, "Transaction amount")
+	cmd.Flags().String("amount", "", "Transaction amount, such as -435.00")
+	cmd.Flags().String("currency", "", "Transaction currency, defaults to the account or profile currency")
+	cmd.Flags().String("account", "", "Name of the account the transaction belongs to")
 	cmd.Flags().StringArray("tags", []string{}, "Tags to group the transaction")
+	cmd.Flags().String("date", "", "Transaction date ("+domain.DateFormat+"), defaults to today")
+	addCreateProfileFlag(cmd)
 
 	_ = cmd.MarkFlagRequired("profile")
 	_ = cmd.MarkFlagRequired("label")
@@ -0,0 +1,116 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/render"
+)
+
+func Budget(profileService service.Profile, scheduleService service.Schedule, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Calculate a daily and weekly budget for the profile",
+		Long: `Calculate how much money is left to spend each day and week over a period, such as from one payday to the next.
+
+Every transaction in the period counts towards the budget. Incoming transactions add to the money available, and outgoing transactions dated after today are treated as upcoming commitments.
+
+Use --projected to also count the future transactions from recurring schedules.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			period, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, period)
+			if err != nil {
+				return err
+			}
+
+			transactions := profile.Transactions
+			if projected, _ := cmd.Flags().GetBool("projected"); projected {
+				transactions, err = includeProjected(scheduleService, profile, period, transactions)
+				if err != nil {
+					return err
+				}
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, transactions)
+			if err != nil {
+				return err
+			}
+
+			budget, err := profileService.CalculateBudget(profile, period, currency, transactions)
+			if err != nil {
+				return err
+			}
+
+			return outputBudget(cmd, budget)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("from", "", "First day of the budget period ("+domain.DateFormat+")")
+	cmd.Flags().String("to", "", "Last day of the budget period ("+domain.DateFormat+")")
+	cmd.Flags().Bool("projected", false, "Include future transactions from recurring schedules")
+	addReportingCurrencyFlag(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func outputBudget(cmd *cobra.Command, budget *domain.Budget) error {
+	pace := "ahead"
+	if !budget.Ahead() {
+		pace = "behind"
+	}
+	money := func(amount int64) render.Cell {
+		return render.Money(domain.NewMoney(amount, budget.Currency))
+	}
+
+	table := render.NewRecord("Budget", fmt.Sprintf("%s to %s",
+		budget.Period.From.Format(domain.DateFormat), budget.Period.To.Format(domain.DateFormat)),
+		[]render.Column{
+			{Key: "from"},
+			{Key: "to"},
+			{Key: "currency"},
+			{Key: "days_total"},
+			{Key: "days_remaining", Header: "Days"},
+			{Key: "income", Header: "Income"},
+			{Key: "spent", Header: "Spent so far"},
+			{Key: "upcoming", Header: "Upcoming"},
+			{Key: "remaining", Header: "Remaining"},
+			{Key: "per_day", Header: "Per day"},
+			{Key: "per_week", Header: "Per week"},
+			{Key: "pace", Header: "Pace"},
+		},
+		render.Date(budget.Period.From),
+		render.Date(budget.Period.To),
+		render.Text(string(budget.Currency)),
+		render.Int(budget.DaysTotal),
+		render.Cell{Text: fmt.Sprintf("%d of %d remaining", budget.DaysRemaining, budget.DaysTotal), Value: budget.DaysRemaining},
+		money(budget.Income),
+		money(budget.Spent),
+		money(budget.Upcoming),
+		money(budget.Remaining),
+		money(budget.PerDay),
+		money(budget.PerWeek),
+		render.Cell{Text: fmt.Sprintf("%s %s", formatAmount(abs(budget.Pace), budget.Currency), pace), Value: budget.Pace},
+	)
+	return renderOutput(cmd, table)
+}
+
+func abs(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
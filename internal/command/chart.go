@@ -0,0 +1,274 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/chart"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"os"
+	"strings"
+	"time"
+)
+
+func Chart(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chart",
+		Short: "Draw charts of the transactions in a profile in the terminal",
+		Long: `Draw charts of the transactions in a profile in the terminal.
+
+Charts fill the width of the terminal unless --width is given. Unicode block characters are used if the locale
+uses UTF-8, otherwise charts are drawn in plain ASCII. Use --charset to choose explicitly.`,
+	}
+
+	cmd.AddCommand(ChartTags(profileService, exchangeRateService))
+	cmd.AddCommand(ChartMonthly(profileService, exchangeRateService))
+	cmd.AddCommand(ChartBalance(profileService, exchangeRateService))
+
+	return cmd
+}
+
+// addChartFlags adds the --width and --charset flags used to choose how a chart is drawn.
+func addChartFlags(cmd *cobra.Command) {
+	names := make([]string, len(chart.Charsets))
+	for i, c := range chart.Charsets {
+		names[i] = c.Name
+	}
+	cmd.Flags().Int("width", 0, "Number of columns to fill, defaults to the width of the terminal")
+	cmd.Flags().String("charset", "", "Characters to draw with, one of "+strings.Join(names, ", ")+", defaults to the locale")
+}
+
+// getChartFlags parses the --width and --charset flags.
+func getChartFlags(cmd *cobra.Command) (int, chart.Charset, errs.Error) {
+	width, _ := cmd.Flags().GetInt("width")
+	if width <= 0 {
+		width = chart.TerminalWidth(os.Stdout)
+	}
+	name, _ := cmd.Flags().GetString("charset")
+	if name == "" {
+		return width, chart.DetectCharset(), nil
+	}
+	charset, ok := chart.CharsetByName(name)
+	if !ok {
+		return 0, chart.Charset{}, errs.New().
+			WithCode(errs.ErrInvalidCharset).
+			WithMessage(fmt.Sprintf("unknown charset `%s`", name))
+	}
+	return width, charset, nil
+}
+
+func ChartTags(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "Draw a bar chart of the spending in the profile by tag",
+		Long: `Draw a bar chart of the spending in the profile by tag, with the tags that were spent the most first.
+
+Transactions with multiple tags are counted in full against each tag, and transfers between accounts are ignored,
+in the same way as report tags.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			limit, _ := cmd.Flags().GetInt("limit")
+			depth, err := getTagDepthFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+			width, charset, err := getChartFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, dateRange)
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+			breakdown := transactions.GroupByTagDepth(domain.TagSplitEach, depth)
+
+			bar := func(label string, total *domain.TagTotal) chart.Bar {
+				return chart.Bar{
+					Label: label,
+					Value: -total.Outgoing,
+					Text:  fmt.Sprintf("%s (%s)", formatAmount(-total.Outgoing, currency), formatPercent(total.OutgoingPercent)),
+				}
+			}
+			c := &chart.BarChart{
+				Title: fmt.Sprintf("Spending by tag: %s in total", formatAmount(-breakdown.Outgoing, currency)),
+				Bars:  make([]chart.Bar, 0),
+			}
+			for _, total := range breakdown.Tags {
+				if total.Outgoing == 0 || (limit > 0 && len(c.Bars) == limit) {
+					break
+				}
+				c.Bars = append(c.Bars, bar(total.Tag, total))
+			}
+			if breakdown.Untagged.Outgoing < 0 {
+				c.Bars = append(c.Bars, bar("(untagged)", breakdown.Untagged))
+			}
+			return c.Render(os.Stdout, width, charset)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().Int("limit", 0, "Only show this many of the tags that were spent the most")
+	addTagDepthFlag(cmd, "Roll tags up into their ancestor at this depth, such as 1 to count transport/rail as transport")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addChartFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func ChartMonthly(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monthly",
+		Short: "Draw sparklines of the incoming, outgoing and net totals of the profile for each month",
+		Long: `Draw sparklines of the incoming, outgoing and net totals of the profile for each month.
+
+The range given by --from and --to starts at the beginning of the month of --from, and defaults to the last 12
+months including the current one up to today. The totals are the same as those in report monthly.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			dateRange, err := getMonthRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+			width, charset, err := getChartFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, dateRange)
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+			summary := transactions.MonthlySummary(dateRange)
+
+			incoming := make([]int64, len(summary.Months))
+			outgoing := make([]int64, len(summary.Months))
+			net := make([]int64, len(summary.Months))
+			for i, m := range summary.Months {
+				incoming[i] = m.Incoming
+				outgoing[i] = -m.Outgoing
+				net[i] = m.Net()
+			}
+			first, last := summary.Months[0], summary.Months[len(summary.Months)-1]
+			series := func(label string, values []int64) chart.Series {
+				return chart.Series{
+					Label:  label,
+					Values: values,
+					Text:   fmt.Sprintf("%s in %s", formatAmount(values[len(values)-1], currency), last.Month.Format(monthFormat)),
+				}
+			}
+
+			c := &chart.SparklineChart{
+				Title: fmt.Sprintf("Monthly totals: %s to %s", first.Month.Format(monthFormat), last.Month.Format(monthFormat)),
+				Series: []chart.Series{
+					series("Incoming", incoming),
+					series("Outgoing", outgoing),
+					series("Net", net),
+				},
+			}
+			return c.Render(os.Stdout, width, charset)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addChartFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func ChartBalance(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "balance",
+		Short: "Draw a line chart of the balance of the profile over time",
+		Long: `Draw a line chart of the balance of the profile over time.
+
+The balance is the running total of every transaction in the profile at the end of each day. The range given by
+--from and --to defaults to the year up to and including today.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			height, _ := cmd.Flags().GetInt("height")
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+			if dateRange.To.IsZero() {
+				dateRange.To = domain.TruncateDay(time.Now())
+			}
+			if dateRange.From.IsZero() {
+				dateRange.From = dateRange.To.AddDate(-1, 0, 1)
+			}
+			if dateRange.To.Before(dateRange.From) {
+				return errs.New().
+					WithCode(errs.ErrInvalidDate).
+					WithMessage("from date must not be after the to date")
+			}
+			width, charset, err := getChartFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Earlier transactions are needed for the opening balance.
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{To: dateRange.To})
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+			balances := transactions.DailyBalances(dateRange)
+
+			c := &chart.LineChart{
+				Title: fmt.Sprintf("Balance: %s on %s",
+					formatAmount(balances[len(balances)-1], currency), dateRange.To.Format(domain.DateFormat)),
+				Values: balances,
+				Height: height,
+				Start:  dateRange.From.Format(domain.DateFormat),
+				End:    dateRange.To.Format(domain.DateFormat),
+				Format: func(value int64) string {
+					return formatAmount(value, currency)
+				},
+			}
+			return c.Render(os.Stdout, width, charset)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().Int("height", chart.DefaultHeight, "Number of rows to draw the chart in")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addChartFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
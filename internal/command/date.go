@@ -0,0 +1,49 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"time"
+)
+
+// getDateFlag parses the value of the given flag as a date.
+// A zero time is returned if the flag is empty.
+func getDateFlag(cmd *cobra.Command, name string) (time.Time, errs.Error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := domain.ParseDate(value)
+	if err != nil {
+		return time.Time{}, errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage(fmt.Sprintf("invalid %s date `%s`: expected format %s", name, value, domain.DateFormat))
+	}
+	return date, nil
+}
+
+// addDateRangeFlags adds the --from and --to flags to the given command.
+func addDateRangeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("from", "", "Only include transactions on or after this date ("+domain.DateFormat+")")
+	cmd.Flags().String("to", "", "Only include transactions on or before this date ("+domain.DateFormat+")")
+}
+
+// getDateRangeFlags parses the --from and --to flags into a date range.
+func getDateRangeFlags(cmd *cobra.Command) (domain.DateRange, errs.Error) {
+	from, err := getDateFlag(cmd, "from")
+	if err != nil {
+		return domain.DateRange{}, err
+	}
+	to, err := getDateFlag(cmd, "to")
+	if err != nil {
+		return domain.DateRange{}, err
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return domain.DateRange{}, errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("to date must not be before from date")
+	}
+	return domain.DateRange{From: from, To: to}, nil
+}
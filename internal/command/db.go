@@ -0,0 +1,105 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/render"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"time"
+)
+
+func DB(migrator repository.Migrator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the database schema",
+		// Override the root command so that the schema can be inspected even when it
+		// is newer than this binary supports.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	cmd.AddCommand(DBMigrate(migrator))
+	cmd.AddCommand(DBStatus(migrator))
+
+	return cmd
+}
+
+func DBMigrate(migrator repository.Migrator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply any pending database migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applied, err := migrator.Migrate()
+			for _, m := range applied {
+				fmt.Printf("applied migration %d: %s\n", m.Version, m.Description)
+			}
+			if err != nil {
+				return err
+			}
+
+			status, err := migrator.Status()
+			if err != nil {
+				return err
+			}
+			if len(applied) == 0 {
+				fmt.Printf("database is up to date at version %d\n", status.Version)
+			} else {
+				fmt.Printf("database migrated to version %d\n", status.Version)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func DBStatus(migrator repository.Migrator) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the database schema version and any pending migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := migrator.Status()
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable(describeMigrationStatus(status),
+				render.Column{Key: "version", Header: "Version"},
+				render.Column{Key: "description", Header: "Description"},
+				render.Column{Key: "applied_at", Header: "Applied At"},
+			)
+			for _, m := range status.Migrations {
+				appliedAt := render.Cell{Text: "pending"}
+				if m.Applied() {
+					appliedAt = render.Cell{
+						Text:  m.AppliedAt.Local().Format("2006-01-02 15:04:05"),
+						Value: m.AppliedAt.UTC().Format(time.RFC3339),
+					}
+				}
+				description := m.Description
+				if m.Version > status.Latest {
+					description += " (unknown)"
+				}
+				table.Append(render.Int(m.Version), render.Text(description), appliedAt)
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+// describeMigrationStatus returns a summary of the given schema status.
+func describeMigrationStatus(status *repository.MigrationStatus) string {
+	switch {
+	case status.Version > status.Latest:
+		return fmt.Sprintf("Schema version %d is newer than this binary supports (%d): please upgrade", status.Version, status.Latest)
+	case status.Pending() > 0:
+		return fmt.Sprintf("Schema version %d of %d, %d pending", status.Version, status.Latest, status.Pending())
+	default:
+		return fmt.Sprintf("Schema version %d is up to date", status.Version)
+	}
+}
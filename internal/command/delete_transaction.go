@@ -0,0 +1,50 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+)
+
+func DeleteTransaction(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete-transaction",
+		Short: "Delete a transaction from the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			id, _ := cmd.Flags().GetString("id")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			t, err := profileService.LoadTransactionByID(id)
+			if err != nil {
+				return err
+			}
+
+			if t.ProfileID != profile.ID {
+				return errs.New().
+					WithCode(errs.ErrUnknownTransaction).
+					WithMessage("unknown transaction")
+			}
+
+			// delete the transaction.
+			if err := profileService.DeleteTransaction(t.ID); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("id", "", "Transaction ID")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
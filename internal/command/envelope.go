@@ -0,0 +1,379 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"time"
+)
+
+func Budgets(profileService service.Profile, exchangeRateService service.ExchangeRate, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "budgets",
+		Short: "Manage the budget envelopes that cap spending on each tag",
+		Long: `Manage the budget envelopes that cap spending on each tag.
+
+Each envelope sets the amount that can be spent on a tag, and its descendants, in every week, month or year.
+With rollover, the amount left over at the end of each period is added to the next, and any overspend is taken from it.`,
+	}
+
+	cmd.AddCommand(AddEnvelope(profileService, envelopeService))
+	cmd.AddCommand(UpdateEnvelope(profileService, envelopeService))
+	cmd.AddCommand(ListEnvelopes(profileService, envelopeService))
+	cmd.AddCommand(DeleteEnvelope(profileService, envelopeService))
+	cmd.AddCommand(EnvelopeStatus(profileService, exchangeRateService, envelopeService))
+
+	return cmd
+}
+
+// addEnvelopeFlags adds the flags used to describe an envelope.
+func addEnvelopeFlags(cmd *cobra.Command) {
+	cmd.Flags().String("amount", "", "Amount that can be spent in each period, such as 400.00")
+	cmd.Flags().String("currency", "", "Currency of the amount, defaults to the profile currency")
+	cmd.Flags().String("period", string(domain.EnvelopePeriodMonthly), "How often the amount is renewed: weekly, monthly or yearly")
+	cmd.Flags().Bool("rollover", false, "Carry the amount left over, or overspent, into the next period")
+	cmd.Flags().String("start", "", "A day in the first period to roll amounts over from ("+domain.DateFormat+"), defaults to today")
+}
+
+// getEnvelopePeriodFlag parses the value of the --period flag.
+func getEnvelopePeriodFlag(cmd *cobra.Command) (domain.EnvelopePeriod, errs.Error) {
+	value, _ := cmd.Flags().GetString("period")
+	period := domain.EnvelopePeriod(value)
+	if !period.Valid() {
+		return "", errs.New().
+			WithCode(errs.ErrInvalidEnvelope).
+			WithMessage(fmt.Sprintf("unknown period `%s`: expected %s, %s or %s", value,
+				domain.EnvelopePeriodWeekly, domain.EnvelopePeriodMonthly, domain.EnvelopePeriodYearly))
+	}
+	return period, nil
+}
+
+func AddEnvelope(profileService service.Profile, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a budget envelope for a tag",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			tag, _ := cmd.Flags().GetString("tag")
+			rollover, _ := cmd.Flags().GetBool("rollover")
+			period, err := getEnvelopePeriodFlag(cmd)
+			if err != nil {
+				return err
+			}
+			start, err := getDateFlag(cmd, "start")
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", profile.Currency)
+			if err != nil {
+				return err
+			}
+			amount, err := getMoneyFlag(cmd, "amount", currency)
+			if err != nil {
+				return err
+			}
+
+			envelope := domain.NewEnvelope()
+			envelope.ProfileID = profile.ID
+			envelope.Tag = tag
+			envelope.Amount = amount.Amount
+			envelope.Currency = currency
+			envelope.Period = period
+			envelope.Rollover = rollover
+			envelope.Start = start
+
+			if err := envelopeService.CreateEnvelope(envelope); err != nil {
+				return err
+			}
+
+			fmt.Printf("added %s budget of %s for %s\n", envelope.Period, amount, envelope.Tag)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("tag", "", "Tag to budget for, including its descendants")
+	addEnvelopeFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tag")
+	_ = cmd.MarkFlagRequired("amount")
+
+	return cmd
+}
+
+func UpdateEnvelope(profileService service.Profile, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the budget envelope for a tag",
+		Long: `Update the budget envelope for a tag.
+
+Only the flags that are given are changed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			envelope, err := loadEnvelopeFromFlags(cmd, profileService, envelopeService)
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", envelope.Currency)
+			if err != nil {
+				return err
+			}
+			if currency != envelope.Currency && !cmd.Flags().Changed("amount") {
+				return errs.New().
+					WithCode(errs.ErrInvalidAmount).
+					WithMessage("--amount must be given when changing the currency")
+			}
+			if cmd.Flags().Changed("amount") {
+				amount, err := getMoneyFlag(cmd, "amount", currency)
+				if err != nil {
+					return err
+				}
+				envelope.Amount = amount.Amount
+				envelope.Currency = amount.Currency
+			}
+			if cmd.Flags().Changed("period") {
+				envelope.Period, err = getEnvelopePeriodFlag(cmd)
+				if err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed("rollover") {
+				envelope.Rollover, _ = cmd.Flags().GetBool("rollover")
+			}
+			if cmd.Flags().Changed("start") {
+				envelope.Start, err = getDateFlag(cmd, "start")
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := envelopeService.UpdateEnvelope(envelope); err != nil {
+				return err
+			}
+
+			fmt.Printf("updated %s budget of %s for %s\n", envelope.Period,
+				formatAmount(envelope.Amount, envelope.Currency), envelope.Tag)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("tag", "", "Tag of the envelope to update")
+	addEnvelopeFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tag")
+
+	return cmd
+}
+
+func ListEnvelopes(profileService service.Profile, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the budget envelopes in the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			envelopes, err := envelopeService.LoadEnvelopesByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable("Budgets",
+				render.Column{Key: "id"},
+				render.Column{Key: "tag", Header: "Tag"},
+				render.Column{Key: "amount", Header: "Amount"},
+				render.Column{Key: "currency"},
+				render.Column{Key: "period", Header: "Period"},
+				render.Column{Key: "rollover", Header: "Rollover"},
+				render.Column{Key: "start", Header: "Start"},
+			)
+			for _, e := range envelopes {
+				table.Append(
+					render.Text(e.ID),
+					render.Text(e.Tag),
+					render.Money(domain.NewMoney(e.Amount, e.Currency)),
+					render.Text(string(e.Currency)),
+					render.Text(string(e.Period)),
+					render.Bool(e.Rollover),
+					render.Date(e.Start),
+				)
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func DeleteEnvelope(profileService service.Profile, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete the budget envelope for a tag",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			envelope, err := loadEnvelopeFromFlags(cmd, profileService, envelopeService)
+			if err != nil {
+				return err
+			}
+
+			if err := envelopeService.DeleteEnvelope(envelope.ID); err != nil {
+				return err
+			}
+
+			fmt.Printf("deleted budget for %s\n", envelope.Tag)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("tag", "", "Tag of the envelope to delete")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tag")
+
+	return cmd
+}
+
+func EnvelopeStatus(profileService service.Profile, exchangeRateService service.ExchangeRate, envelopeService service.Envelope) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show how much of each budget envelope has been spent",
+		Long: `Show how much of each budget envelope has been spent in the current period.
+
+Spending is the total of the outgoing transactions with the tag of the envelope or any of its descendants,
+less any incoming transactions with those tags such as refunds. Transfers between accounts are ignored.
+Transactions in other currencies are converted into the currency of the envelope using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			day, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
+			if day.IsZero() {
+				day = domain.TruncateDay(time.Now())
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			envelopes, err := envelopeService.LoadEnvelopesByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			statuses := make([]*domain.EnvelopeStatus, len(envelopes))
+			for i, e := range envelopes {
+				// Only convert the transactions that count towards the envelope, so that exchange rates
+				// are not needed for anything else.
+				transactions := profile.Transactions.Subset(func(t *domain.Transaction) bool {
+					for _, tag := range t.Tags {
+						if domain.TagWithin(tag, e.Tag) {
+							return true
+						}
+					}
+					return false
+				})
+				transactions, err := exchangeRateService.ConvertTransactions(transactions, e.Currency)
+				if err != nil {
+					return err
+				}
+				statuses[i], err = envelopeService.CalculateEnvelopeStatus(e, day, transactions)
+				if err != nil {
+					return err
+				}
+			}
+
+			return outputEnvelopeStatus(cmd, statuses)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("date", "", "Show the periods that contain this day ("+domain.DateFormat+"), defaults to today")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func outputEnvelopeStatus(cmd *cobra.Command, statuses []*domain.EnvelopeStatus) error {
+	table := render.NewTable("Budget status",
+		render.Column{Key: "tag", Header: "Tag"},
+		render.Column{Key: "from", Header: "From"},
+		render.Column{Key: "to", Header: "To"},
+		render.Column{Key: "budgeted", Header: "Budgeted"},
+		render.Column{Key: "carried_over", Header: "Carried over"},
+		render.Column{Key: "available", Header: "Available"},
+		render.Column{Key: "spent", Header: "Spent"},
+		render.Column{Key: "remaining", Header: "Remaining"},
+		render.Column{Key: "spent_percent", Header: "% Spent"},
+		render.Column{Key: "overspent", Header: "Status"},
+		render.Column{Key: "currency"},
+	)
+	for _, s := range statuses {
+		money := func(amount int64) render.Cell {
+			return render.Money(domain.NewMoney(amount, s.Envelope.Currency))
+		}
+		carriedOver := money(s.CarriedOver)
+		if !s.Envelope.Rollover {
+			carriedOver.Text = "-"
+		}
+		status := "ok"
+		if s.Overspent() {
+			status = "overspent"
+		}
+		table.Append(
+			render.Text(s.Envelope.Tag),
+			render.Date(s.Period.From),
+			render.Date(s.Period.To),
+			money(s.Budgeted),
+			carriedOver,
+			money(s.Available),
+			money(s.Spent),
+			money(s.Remaining),
+			render.Cell{Text: formatPercent(s.SpentPercent()), Value: s.SpentPercent()},
+			render.Cell{Text: status, Value: s.Overspent()},
+			render.Text(string(s.Envelope.Currency)),
+		)
+	}
+	return renderOutput(cmd, table)
+}
+
+// loadEnvelopeFromFlags loads the envelope for the tag given by the --tag flag in the profile given by the
+// --profile flag.
+func loadEnvelopeFromFlags(cmd *cobra.Command, profileService service.Profile, envelopeService service.Envelope) (*domain.Envelope, errs.Error) {
+	profileName, _ := cmd.Flags().GetString("profile")
+	tag, _ := cmd.Flags().GetString("tag")
+
+	profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return envelopeService.LoadEnvelopeByTag(profile.ID, tag)
+}
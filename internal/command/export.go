@@ -0,0 +1,62 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/export"
+	"io"
+	"os"
+	"strings"
+)
+
+func Export(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the transactions in the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			format, _ := cmd.Flags().GetString("format")
+			filePath, _ := cmd.Flags().GetString("file")
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			writer, writerErr := export.NewWriter(format)
+			if writerErr != nil {
+				return writerErr
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, dateRange)
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer = os.Stdout
+			if filePath != "" {
+				f, err := os.Create(filePath)
+				if err != nil {
+					return fmt.Errorf("could not create export file: %s", err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			if err := writer.Write(out, profile, profile.Transactions.SortByDate()); err != nil {
+				return fmt.Errorf("could not write export: %s", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("format", "csv", "Export format: "+strings.Join(export.Formats(), ", "))
+	cmd.Flags().String("file", "", "File to write the export to, defaults to stdout")
+	addDateRangeFlags(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
@@ -0,0 +1,118 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"strings"
+)
+
+// addTransactionFilterFlags adds the flags used to filter, sort and page transactions to the given command.
+func addTransactionFilterFlags(cmd *cobra.Command) {
+	sorts := make([]string, len(domain.TransactionSorts))
+	for i, s := range domain.TransactionSorts {
+		sorts[i] = string(s)
+	}
+
+	cmd.Flags().Bool("in", false, "Only include incoming transactions")
+	cmd.Flags().Bool("out", false, "Only include outgoing transactions")
+	cmd.Flags().StringSlice("tag", []string{}, "Only include transactions with any of these tags or their descendants")
+	cmd.Flags().String("tag-match", "any", "Whether transactions must have any or all of the --tag tags")
+	cmd.Flags().StringSlice("exclude-tag", []string{}, "Exclude transactions with any of these tags or their descendants")
+	cmd.Flags().Bool("untagged", false, "Only include transactions without any tags")
+	cmd.Flags().String("label-contains", "", "Only include transactions with a label containing this text, ignoring the case of ASCII letters")
+	cmd.Flags().String("min-amount", "", "Only include transactions of at least this size, regardless of direction")
+	cmd.Flags().String("max-amount", "", "Only include transactions of at most this size, regardless of direction")
+	cmd.Flags().String("query", "", "Only include transactions matching this search, such as \"tag:travel and amount < -5000\"")
+	cmd.Flags().String("sort", string(domain.TransactionSortDate), "Order transactions by one of "+strings.Join(sorts, ", "))
+	cmd.Flags().Bool("desc", false, "Reverse the order of --sort")
+	cmd.Flags().Int("limit", 0, "Only include this many transactions")
+	cmd.Flags().Int("offset", 0, "Skip this many transactions")
+	addDateRangeFlags(cmd)
+}
+
+// getTransactionFilterFlags parses the flags added by addTransactionFilterFlags.
+// Amounts are parsed in the given currency, and tags are normalised with the given tag case so they match the
+// tags stored in the profile.
+func getTransactionFilterFlags(cmd *cobra.Command, currency domain.Currency, tagCase domain.TagCase) (domain.TransactionFilter, errs.Error) {
+	filter := domain.TransactionFilter{}
+	invalid := func(message string) (domain.TransactionFilter, errs.Error) {
+		return domain.TransactionFilter{}, errs.New().
+			WithCode(errs.ErrInvalidFilter).
+			WithMessage(message)
+	}
+
+	dateRange, err := getDateRangeFlags(cmd)
+	if err != nil {
+		return filter, err
+	}
+	filter.DateRange = dateRange
+
+	in, _ := cmd.Flags().GetBool("in")
+	out, _ := cmd.Flags().GetBool("out")
+	switch {
+	case in && !out:
+		filter.Direction = domain.DirectionIn
+	case out && !in:
+		filter.Direction = domain.DirectionOut
+	}
+
+	filter.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	filter.ExcludeTags, _ = cmd.Flags().GetStringSlice("exclude-tag")
+	filter.Untagged, _ = cmd.Flags().GetBool("untagged")
+	if filter.Untagged && len(filter.Tags) > 0 {
+		return invalid("--untagged cannot be used with --tag")
+	}
+	switch match, _ := cmd.Flags().GetString("tag-match"); strings.ToLower(match) {
+	case "any":
+	case "all":
+		filter.MatchAllTags = true
+	default:
+		return invalid(fmt.Sprintf("unknown tag-match `%s`: expected any or all", match))
+	}
+
+	filter.LabelContains, _ = cmd.Flags().GetString("label-contains")
+
+	if filter.MinAmount, err = getMoneyFlag(cmd, "min-amount", currency); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = getMoneyFlag(cmd, "max-amount", currency); err != nil {
+		return filter, err
+	}
+	if filter.MinAmount.Amount < 0 || filter.MaxAmount.Amount < 0 {
+		return invalid("--min-amount and --max-amount must not be negative: use --in or --out to choose a direction")
+	}
+	if filter.MaxAmount.Amount != 0 && filter.MinAmount.Amount > filter.MaxAmount.Amount {
+		return invalid("--min-amount must not be more than --max-amount")
+	}
+
+	if query, _ := cmd.Flags().GetString("query"); query != "" {
+		parsed, parseErr := domain.ParseQuery(query)
+		if parseErr != nil {
+			return domain.TransactionFilter{}, errs.New().
+				WithCode(errs.ErrInvalidQuery).
+				WithMessage("invalid query: " + parseErr.Error())
+		}
+		filter.Query = parsed
+	}
+
+	sort, _ := cmd.Flags().GetString("sort")
+	filter.Sort = domain.TransactionSort(strings.ToLower(sort))
+	if !filter.Sort.Valid() {
+		sorts := make([]string, len(domain.TransactionSorts))
+		for i, s := range domain.TransactionSorts {
+			sorts[i] = string(s)
+		}
+		return invalid(fmt.Sprintf("unknown sort `%s`: expected one of %s", sort, strings.Join(sorts, ", ")))
+	}
+	filter.Descending, _ = cmd.Flags().GetBool("desc")
+
+	filter.Limit, _ = cmd.Flags().GetInt("limit")
+	filter.Offset, _ = cmd.Flags().GetInt("offset")
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return invalid("--limit and --offset must not be negative")
+	}
+
+	return filter.WithNormalisedTags(tagCase), nil
+}
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"strings"
+	"time"
+)
+
+func Forecast(profileService service.Profile, accountService service.Account, forecastService service.Forecast) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "forecast",
+		Short: "Project the balance of the profile, or one of its accounts, day by day",
+		Long: `Project the balance of the profile, or one of its accounts, day by day.
+
+The forecast starts from the current balance, which is the total of every transaction up to and including today,
+plus the opening balance when forecasting an account. Transactions dated after today and the transactions that
+schedules will create are then applied on the day they are expected. Schedules do not belong to an account, so
+they are left out when forecasting an account with --account.
+
+The lowest point of the forecast is highlighted, along with any days on which the balance falls below --threshold.
+
+Transactions in other currencies are converted into the reporting currency, or the currency of the account, using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			days, _ := cmd.Flags().GetInt("days")
+			changesOnly, _ := cmd.Flags().GetBool("changes-only")
+			to, err := getDateFlag(cmd, "to")
+			if err != nil {
+				return err
+			}
+			if to.IsZero() {
+				if days < 1 {
+					return errs.New().
+						WithCode(errs.ErrInvalidPeriod).
+						WithMessage("--days must be at least 1")
+				}
+				to = domain.TruncateDay(time.Now()).AddDate(0, 0, days)
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			account, err := loadAccountFlag(cmd, accountService, profile, "account")
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", profile.Currency)
+			if err != nil {
+				return err
+			}
+			if account != nil {
+				currency = account.Currency
+			}
+			threshold, err := getMoneyFlag(cmd, "threshold", currency)
+			if err != nil {
+				return err
+			}
+
+			forecast, err := forecastService.ForecastBalance(profile, account, domain.DateRange{To: to}, currency, threshold.Amount)
+			if err != nil {
+				return err
+			}
+
+			return outputForecast(cmd, forecast, changesOnly)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("account", "", "Name of the account to forecast, defaults to the whole profile")
+	cmd.Flags().Int("days", 31, "Number of days after today to forecast")
+	cmd.Flags().String("to", "", "Last day of the forecast ("+domain.DateFormat+"), instead of --days")
+	cmd.Flags().String("threshold", "", "Warn about any day on which the balance falls below this amount, such as 100.00 (default 0)")
+	cmd.Flags().Bool("changes-only", false, "Only show the days on which the balance changes")
+	addReportingCurrencyFlag(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func outputForecast(cmd *cobra.Command, forecast *domain.Forecast, changesOnly bool) error {
+	money := func(amount int64) render.Cell {
+		return render.Money(domain.NewMoney(amount, forecast.Currency))
+	}
+
+	lowestDate, lowestBalance := forecast.LowestBalance()
+	title := fmt.Sprintf("Forecast: lowest %s on %s",
+		formatAmount(lowestBalance, forecast.Currency), lowestDate.Format(domain.DateFormat))
+	if below, ok := forecast.FirstBelowThreshold(); ok {
+		title += fmt.Sprintf(", below %s from %s",
+			formatAmount(forecast.Threshold, forecast.Currency), below.Format(domain.DateFormat))
+	}
+
+	table := render.NewTable(title,
+		render.Column{Key: "date", Header: "Date"},
+		render.Column{Key: "transactions", Header: "Transactions"},
+		render.Column{Key: "change", Header: "Change"},
+		render.Column{Key: "balance", Header: "Balance"},
+		render.Column{Key: "currency"},
+		render.Column{Key: "note", Header: "Note"},
+		render.Column{Key: "lowest"},
+		render.Column{Key: "below_threshold"},
+	)
+
+	row := func(date time.Time, labels []string, change render.Cell, balance int64, lowest bool) {
+		below := forecast.BelowThreshold(balance)
+		notes := make([]string, 0, 2)
+		if lowest {
+			notes = append(notes, "<< lowest")
+		}
+		if below {
+			notes = append(notes, "below threshold")
+		}
+		table.Append(
+			render.Date(date),
+			render.List(labels),
+			change,
+			money(balance),
+			render.Text(string(forecast.Currency)),
+			render.Text(strings.Join(notes, ", ")),
+			render.Bool(lowest),
+			render.Bool(below),
+		)
+	}
+
+	row(forecast.Today, []string{"Current balance"}, render.Cell{Value: int64(0)}, forecast.Opening, forecast.Lowest == nil)
+	for _, d := range forecast.Days {
+		lowest := d == forecast.Lowest
+		if changesOnly && len(d.Transactions) == 0 && !lowest {
+			continue
+		}
+		labels := make([]string, len(d.Transactions))
+		for i, t := range d.Transactions {
+			labels[i] = t.Label
+		}
+		row(d.Date, labels, money(d.Change), d.Balance, lowest)
+	}
+	table.Footer = []string{"", "Closing balance", "", formatAmount(forecast.Closing(), forecast.Currency), ""}
+	return renderOutput(cmd, table)
+}
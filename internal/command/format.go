@@ -0,0 +1,29 @@
+package command
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"strings"
+)
+
+// formatAmount formats the given amount in the minor unit of the given currency.
+func formatAmount(amount int64, currency domain.Currency) string {
+	return domain.NewMoney(amount, currency).String()
+}
+
+// formatTotals formats the given totals, which contain one amount per currency.
+func formatTotals(totals []domain.Money) string {
+	if len(totals) == 0 {
+		return "0"
+	}
+	res := make([]string, len(totals))
+	for i, total := range totals {
+		res[i] = total.String()
+	}
+	return strings.Join(res, ", ")
+}
+
+// formatPercent formats the given percentage to 1 decimal place.
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.1f%%", percent)
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/importer"
+	"os"
+	"strings"
+)
+
+func Import(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import transactions into a profile",
+	}
+
+	cmd.AddCommand(ImportCSV(profileService, accountService))
+
+	return cmd
+}
+
+func ImportCSV(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "csv <file>",
+		Short: "Import transactions from a bank statement CSV file",
+		Long: `Import transactions from a bank statement CSV file.
+
+Columns are referenced by their header name, or by their position starting at 1 if the file has no header row.
+Use either --amount-column for a single signed amount, or --debit-column and/or --credit-column.
+
+The column mapping can be saved to a file with --save-mapping and reused with --mapping.
+Any flags given alongside --mapping override the values in the file.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			tags, _ := cmd.Flags().GetStringArray("tags")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			mappingPath, _ := cmd.Flags().GetString("mapping")
+			saveMappingPath, _ := cmd.Flags().GetString("save-mapping")
+
+			mapping := importer.NewMapping()
+			if mappingPath != "" {
+				var err error
+				if mapping, err = importer.LoadMapping(mappingPath); err != nil {
+					return err
+				}
+			}
+			if err := applyMappingFlags(cmd, &mapping); err != nil {
+				return err
+			}
+			if err := mapping.Validate(); err != nil {
+				return fmt.Errorf("invalid mapping: %s", err)
+			}
+			if saveMappingPath != "" {
+				if err := mapping.Save(saveMappingPath); err != nil {
+					return err
+				}
+			}
+			if mapping.Currency == "" {
+				// Default to the currency of the account or profile if they already exist.
+				mapping.Currency = domain.DefaultCurrency
+				if profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{}); err == nil {
+					mapping.Currency = profile.Currency
+					if account, err := loadAccountFlag(cmd, accountService, profile, "account"); err == nil && account != nil {
+						mapping.Currency = account.Currency
+					}
+				}
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("could not open file: %s", err)
+			}
+			defer f.Close()
+
+			result, err := importer.ParseCSV(f, mapping)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				outputImportPreview(result)
+				outputImportErrors(result.Errors)
+				return nil
+			}
+
+			profile, profileErr := loadOrCreateProfileFlag(cmd, profileService)
+			if profileErr != nil {
+				return profileErr
+			}
+			account, accountErr := loadAccountFlag(cmd, accountService, profile, "account")
+			if accountErr != nil {
+				return accountErr
+			}
+
+			transactions := make([]*domain.Transaction, len(result.Rows))
+			for i, row := range result.Rows {
+				transactions[i] = row.Transaction.WithTags(append([]string{}, tags...)...)
+				if account != nil {
+					transactions[i].WithAccountID(account.ID)
+				}
+			}
+			skipped, importErr := profileService.ImportTransactions(profile.ID, transactions)
+			if importErr != nil {
+				return importErr
+			}
+
+			created := 0
+			for i, row := range result.Rows {
+				if skipped[i] != nil {
+					result.Errors = append(result.Errors, importer.RowError{Line: row.Line, Err: skipped[i]})
+					continue
+				}
+				created++
+			}
+
+			fmt.Printf("imported %d of %d transactions\n", created, created+len(result.Errors))
+			outputImportErrors(result.Errors)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().StringArray("tags", []string{}, "Tags to add to every imported transaction")
+	cmd.Flags().Bool("dry-run", false, "Show the transactions that would be imported without saving them")
+	cmd.Flags().String("mapping", "", "Path to a saved column mapping file")
+	cmd.Flags().String("save-mapping", "", "Path to save the column mapping to for later use")
+	cmd.Flags().String("date-column", "", "Column containing the transaction date")
+	cmd.Flags().String("label-column", "", "Column containing the transaction label")
+	cmd.Flags().String("amount-column", "", "Column containing the signed transaction amount")
+	cmd.Flags().String("debit-column", "", "Column containing money going out")
+	cmd.Flags().String("credit-column", "", "Column containing money coming in")
+	cmd.Flags().String("date-format", "", "Format of the dates, such as DD/MM/YYYY (default YYYY-MM-DD)")
+	cmd.Flags().String("decimal-separator", "", "Character between pounds and pence, either . or , (default .)")
+	cmd.Flags().String("delimiter", "", "Character between each column (default ,)")
+	cmd.Flags().Bool("no-header", false, "The first row contains data rather than column names")
+	cmd.Flags().Bool("negate", false, "Invert all amounts, such as for credit card statements")
+	cmd.Flags().String("currency", "", "Currency of the amounts in the file, defaults to the account or profile currency")
+	cmd.Flags().String("account", "", "Name of the account to import the transactions into")
+	addCreateProfileFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+// applyMappingFlags sets any mapping values that were given as flags.
+func applyMappingFlags(cmd *cobra.Command, mapping *importer.Mapping) error {
+	for flag, dest := range map[string]*string{
+		"date-column":       &mapping.Date,
+		"label-column":      &mapping.Label,
+		"amount-column":     &mapping.Amount,
+		"debit-column":      &mapping.Debit,
+		"credit-column":     &mapping.Credit,
+		"date-format":       &mapping.DateFormat,
+		"decimal-separator": &mapping.DecimalSeparator,
+		"delimiter":         &mapping.Delimiter,
+	} {
+		if cmd.Flags().Changed(flag) {
+			*dest, _ = cmd.Flags().GetString(flag)
+		}
+	}
+	for flag, dest := range map[string]*bool{
+		"no-header": &mapping.NoHeader,
+		"negate":    &mapping.Negate,
+	} {
+		if cmd.Flags().Changed(flag) {
+			*dest, _ = cmd.Flags().GetBool(flag)
+		}
+	}
+	if cmd.Flags().Changed("currency") {
+		currency, err := getCurrencyFlag(cmd, "currency", "")
+		if err != nil {
+			return err
+		}
+		mapping.Currency = currency
+	}
+	return nil
+}
+
+func outputImportPreview(result *importer.Result) {
+	outputTable := tablewriter.NewWriter(os.Stdout)
+	outputTable.SetAutoFormatHeaders(false)
+	outputTable.SetHeader([]string{"Row", "Date", "Label", "Amount"})
+	outputTable.SetAutoWrapText(false)
+	outputTable.SetCaption(true, "Transactions to import")
+
+	c := domain.NewTransactionCollection()
+	for _, row := range result.Rows {
+		t := row.Transaction
+		c.Add(t)
+		outputTable.Append([]string{fmt.Sprint(row.Line), t.Date.Format(domain.DateFormat), t.Label, t.Money().String()})
+	}
+	outputTable.SetFooter([]string{"", "", "Total", formatTotals(c.Totals())})
+	outputTable.Render()
+}
+
+func outputImportErrors(rowErrors []importer.RowError) {
+	if len(rowErrors) == 0 {
+		return
+	}
+
+	outputTable := tablewriter.NewWriter(os.Stdout)
+	outputTable.SetAutoFormatHeaders(false)
+	outputTable.SetHeader([]string{"Row", "Error"})
+	outputTable.SetAutoWrapText(false)
+	outputTable.SetCaption(true, fmt.Sprintf("%d rows could not be imported", len(rowErrors)))
+
+	for _, e := range rowErrors {
+		outputTable.Append([]string{fmt.Sprint(e.Line), strings.TrimSpace(e.Err.Error())})
+	}
+	outputTable.Render()
+}
@@ -2,74 +2,148 @@ package command
 
 import (
 	"fmt"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/application/service"
-	"os"
-	"strings"
+	"github.com/tomwright/finance-planner/internal/render"
 )
 
-func ListTransactions(profileService service.Profile) *cobra.Command {
+func ListTransactions(profileService service.Profile, scheduleService service.Schedule, exchangeRateService service.ExchangeRate,
+	accountService service.Account) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list-transactions",
 		Short: "List all transactions for the profile",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			profileName, _ := cmd.Flags().GetString("profile")
 
-			profile, err := profileService.LoadProfileByName(profileName)
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
 			if err != nil {
 				return err
 			}
 
-			in, _ := cmd.Flags().GetBool("in")
-			out, _ := cmd.Flags().GetBool("out")
-			if in && out {
-				in = false
-				out = false
+			account, err := loadAccountFlag(cmd, accountService, profile, "account")
+			if err != nil {
+				return err
+			}
+
+			currency := profile.Currency
+			if account != nil {
+				currency = account.Currency
+			}
+			filter, err := getTransactionFilterFlags(cmd, currency, profile.TagCase)
+			if err != nil {
+				return err
+			}
+			if account != nil {
+				filter.AccountID = account.ID
+			}
+
+			projected, _ := cmd.Flags().GetBool("projected")
+			query := filter
+			if projected {
+				// Projected transactions are not stored, so the filter is applied again once they have been added
+				// and the page is chosen afterwards.
+				query = filter.WithoutPagination()
+			}
+
+			transactions, err := profileService.LoadTransactions(profile.ID, query)
+			if err != nil {
+				return err
+			}
+			if projected {
+				transactions, err = includeProjected(scheduleService, profile, filter.DateRange, transactions)
+				if err != nil {
+					return err
+				}
+				transactions = transactions.Filter(filter)
 			}
 
-			transactions := profile.Transactions
 			title := "All transactions"
-			if in {
+			switch filter.Direction {
+			case domain.DirectionIn:
 				title = "Incoming transactions"
-				transactions = profile.Transactions.Subset(func(t *domain.Transaction) bool {
-					return t.Amount > 0
-				})
-			}
-			if out {
+			case domain.DirectionOut:
 				title = "Outgoing transactions"
-				transactions = profile.Transactions.Subset(func(t *domain.Transaction) bool {
-					return t.Amount < 0
-				})
+			}
+			if account != nil {
+				title = account.Name + ": " + title
 			}
 
-			outputTransactions(title, transactions)
+			if !cmd.Flags().Changed("currency") {
+				return outputTransactions(cmd, title, transactions, nil)
+			}
 
-			return nil
+			converted, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, transactions)
+			if err != nil {
+				return err
+			}
+			return outputTransactions(cmd, fmt.Sprintf("%s in %s", title, currency), transactions, converted)
 		},
 	}
 
 	cmd.Flags().String("profile", "", "Profile to interact with")
-	cmd.Flags().Bool("in", false, "Only list incoming transactions")
-	cmd.Flags().Bool("out", false, "Only list outgoing transactions")
+	cmd.Flags().Bool("projected", false, "Include future transactions from recurring schedules, requires --to")
+	cmd.Flags().String("currency", "", "Also show each amount and the total converted into this currency")
+	cmd.Flags().String("account", "", "Only list transactions in the account with this name")
+	addTransactionFilterFlags(cmd)
+	addOutputFlag(cmd)
 
 	_ = cmd.MarkFlagRequired("profile")
 
 	return cmd
 }
 
-func outputTransactions(title string, collection *domain.TransactionCollection) {
-	outputTable := tablewriter.NewWriter(os.Stdout)
-	outputTable.SetAutoFormatHeaders(false)
-	outputTable.SetHeader([]string{"ID", "Label", "Tags", "Amount"})
-	outputTable.SetAutoWrapText(false)
-	outputTable.SetCaption(true, title)
-
-	_ = collection.Range(nil, func(t *domain.Transaction) error {
-		outputTable.Append([]string{t.ID, t.Label, strings.Join(t.Tags, ", "), "£" + fmt.Sprint(float64(t.Amount)/100)})
-		return nil
-	})
-	outputTable.SetFooter([]string{"", "", "Total", "£" + fmt.Sprint(float64(collection.Sum())/100)})
-	outputTable.Render()
+// outputTransactions outputs the given transactions.
+// If converted is not nil it must contain the same transactions in the same order, and the converted
+// amounts are shown alongside the originals.
+func outputTransactions(cmd *cobra.Command, title string, collection *domain.TransactionCollection, converted *domain.TransactionCollection) error {
+	columns := []render.Column{
+		{Key: "id", Header: "ID"},
+		{Key: "date", Header: "Date"},
+		{Key: "label", Header: "Label"},
+		{Key: "tags", Header: "Tags"},
+		{Key: "amount", Header: "Amount"},
+		{Key: "currency"},
+		{Key: "account_id"},
+		{Key: "transfer_id"},
+		{Key: "schedule_id"},
+		{Key: "projected"},
+	}
+	if converted != nil {
+		columns = append(columns,
+			render.Column{Key: "converted_amount", Header: "Converted"},
+			render.Column{Key: "converted_currency"},
+		)
+	}
+	table := render.NewTable(title, columns...)
+
+	for i, t := range collection.All() {
+		id, label := t.ID, t.Label
+		if t.Projected {
+			id, label = t.ScheduleID, label+" (projected)"
+		}
+		row := []render.Cell{
+			{Text: id, Value: t.ID},
+			render.Date(t.Date),
+			{Text: label, Value: t.Label},
+			render.List(t.Tags),
+			render.Money(t.Money()),
+			render.Text(string(t.Currency)),
+			render.Text(t.AccountID),
+			render.Text(t.TransferID),
+			render.Text(t.ScheduleID),
+			render.Bool(t.Projected),
+		}
+		if converted != nil {
+			c := converted.All()[i]
+			row = append(row, render.Money(c.Money()), render.Text(string(c.Currency)))
+		}
+		table.Append(row...)
+	}
+
+	table.Footer = []string{"", "", "", "Total", formatTotals(collection.Totals())}
+	if converted != nil {
+		table.Footer = append(table.Footer, formatTotals(converted.Totals()))
+	}
+	return renderOutput(cmd, table)
 }
@@ -3,19 +3,51 @@ package command
 import (
 	"github.com/spf13/cobra"
 	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/repository"
 )
 
-func Load(profileService service.Profile) *cobra.Command {
+func Load(migrator repository.Migrator, profileService service.Profile, scheduleService service.Schedule, exchangeRateService service.ExchangeRate,
+	accountService service.Account, tagRuleService service.TagRule, tagService service.Tag, envelopeService service.Envelope, forecastService service.Forecast) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "finance",
 		Short: "Finance is a quick and easy financial planner.",
 		Long:  `A quick and easy financial planner for the month.`,
+		// Bring the database schema up to date before running any command.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			_, err := migrator.Migrate()
+			return err
+		},
 	}
 
-	cmd.AddCommand(ListTransactions(profileService))
-	cmd.AddCommand(AddTransaction(profileService))
-	cmd.AddCommand(UpdateTransaction(profileService))
+	cmd.AddCommand(ListTransactions(profileService, scheduleService, exchangeRateService, accountService))
+	cmd.AddCommand(AddTransaction(profileService, accountService))
+	cmd.AddCommand(UpdateTransaction(profileService, accountService))
+	cmd.AddCommand(DeleteTransaction(profileService))
+	cmd.AddCommand(deprecatedDeleteProfile(profileService))
+	cmd.AddCommand(Profile(profileService, accountService))
+	cmd.AddCommand(Budget(profileService, scheduleService, exchangeRateService))
+	cmd.AddCommand(Budgets(profileService, exchangeRateService, envelopeService))
+	cmd.AddCommand(Forecast(profileService, accountService, forecastService))
+	cmd.AddCommand(Schedule(profileService, scheduleService))
+	cmd.AddCommand(Report(profileService, exchangeRateService))
+	cmd.AddCommand(Chart(profileService, exchangeRateService))
+	cmd.AddCommand(Import(profileService, accountService))
+	cmd.AddCommand(Export(profileService))
+	cmd.AddCommand(Rate(exchangeRateService))
+	cmd.AddCommand(Account(profileService, accountService))
+	cmd.AddCommand(Transfer(profileService, accountService))
+	cmd.AddCommand(Rules(profileService, accountService, tagRuleService))
+	cmd.AddCommand(Tags(profileService, exchangeRateService, tagService))
 	cmd.AddCommand(HTTPAPI(profileService))
+	cmd.AddCommand(DB(migrator))
 
 	return cmd
 }
+
+// deprecatedDeleteProfile returns the delete-profile command that has been replaced by profile delete.
+func deprecatedDeleteProfile(profileService service.Profile) *cobra.Command {
+	cmd := DeleteProfile(profileService)
+	cmd.Use = "delete-profile"
+	cmd.Deprecated = "use `profile delete` instead"
+	return cmd
+}
@@ -0,0 +1,71 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"strings"
+)
+
+// getCurrencyFlag parses the value of the given flag as a currency.
+// fallback is returned if the flag is empty.
+func getCurrencyFlag(cmd *cobra.Command, name string, fallback domain.Currency) (domain.Currency, errs.Error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return fallback, nil
+	}
+	currency, ok := domain.ParseCurrency(value)
+	if !ok {
+		return "", errs.New().
+			WithCode(errs.ErrInvalidCurrency).
+			WithMessage(fmt.Sprintf("unknown %s `%s`: expected one of %s", name, value, joinCurrencies(domain.Currencies())))
+	}
+	return currency, nil
+}
+
+// getMoneyFlag parses the value of the given flag as an amount of the given currency.
+// A zero amount is returned if the flag is empty.
+func getMoneyFlag(cmd *cobra.Command, name string, currency domain.Currency) (domain.Money, errs.Error) {
+	value, _ := cmd.Flags().GetString(name)
+	if value == "" {
+		return domain.NewMoney(0, currency), nil
+	}
+	money, err := domain.ParseMoney(value, currency)
+	if err != nil {
+		return money, errs.New().
+			WithCode(errs.ErrInvalidAmount).
+			WithMessage(err.Error())
+	}
+	return money, nil
+}
+
+// addReportingCurrencyFlag adds the --currency flag used to choose the currency amounts are reported in.
+func addReportingCurrencyFlag(cmd *cobra.Command) {
+	cmd.Flags().String("currency", "", "Currency to report amounts in, defaults to the profile currency")
+}
+
+// convertToReportingCurrency converts the given transactions into the currency given in the --currency flag,
+// or the currency of the profile if the flag is empty.
+func convertToReportingCurrency(cmd *cobra.Command, exchangeRateService service.ExchangeRate, profile *domain.Profile,
+	transactions *domain.TransactionCollection) (*domain.TransactionCollection, domain.Currency, errs.Error) {
+	currency, err := getCurrencyFlag(cmd, "currency", profile.Currency)
+	if err != nil {
+		return nil, "", err
+	}
+	converted, err := exchangeRateService.ConvertTransactions(transactions, currency)
+	if err != nil {
+		return nil, "", err
+	}
+	return converted, currency, nil
+}
+
+// joinCurrencies returns the given currencies as a comma separated list.
+func joinCurrencies(currencies []domain.Currency) string {
+	res := make([]string, len(currencies))
+	for i, c := range currencies {
+		res[i] = string(c)
+	}
+	return strings.Join(res, ", ")
+}
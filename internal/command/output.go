@@ -0,0 +1,40 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"os"
+	"strings"
+)
+
+// addOutputFlag adds the --output flag used to choose the format of the output of read commands.
+func addOutputFlag(cmd *cobra.Command) {
+	formats := make([]string, len(render.Formats))
+	for i, f := range render.Formats {
+		formats[i] = string(f)
+	}
+	cmd.Flags().StringP("output", "o", string(render.FormatTable), "Output format, one of "+strings.Join(formats, ", "))
+}
+
+// getOutputFlag parses the value of the --output flag.
+func getOutputFlag(cmd *cobra.Command) (render.Format, errs.Error) {
+	value, _ := cmd.Flags().GetString("output")
+	format := render.Format(strings.ToLower(value))
+	if !format.Valid() {
+		return "", errs.New().
+			WithCode(errs.ErrInvalidOutputFormat).
+			WithMessage(fmt.Sprintf("unknown output format `%s`", value))
+	}
+	return format, nil
+}
+
+// renderOutput writes the given table to stdout in the format given in the --output flag.
+func renderOutput(cmd *cobra.Command, table *render.Table) error {
+	format, err := getOutputFlag(cmd)
+	if err != nil {
+		return err
+	}
+	return render.Render(os.Stdout, format, table)
+}
@@ -0,0 +1,335 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+)
+
+func Profile(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles",
+	}
+
+	cmd.AddCommand(CreateProfile(profileService))
+	cmd.AddCommand(ListProfiles(profileService))
+	cmd.AddCommand(RenameProfile(profileService))
+	cmd.AddCommand(ShowProfile(profileService, accountService))
+	cmd.AddCommand(DeleteProfile(profileService))
+	cmd.AddCommand(SetProfileCurrency(profileService))
+	cmd.AddCommand(SetProfileTagCase(profileService))
+
+	return cmd
+}
+
+func CreateProfile(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			currency, err := getCurrencyFlag(cmd, "currency", domain.DefaultCurrency)
+			if err != nil {
+				return err
+			}
+
+			profile := domain.NewProfile()
+			profile.Name = profileName
+			profile.Currency = currency
+			if err := profileService.CreateProfile(profile); err != nil {
+				return err
+			}
+
+			fmt.Printf("created profile %s using %s\n", profile.Name, profile.Currency)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Name of the profile to create")
+	cmd.Flags().String("currency", "", "Default currency of new transactions in the profile (default "+string(domain.DefaultCurrency)+")")
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func ListProfiles(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all profiles",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := profileService.ListProfiles()
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable("Profiles",
+				render.Column{Key: "id", Header: "ID"},
+				render.Column{Key: "name", Header: "Name"},
+				render.Column{Key: "currency", Header: "Currency"},
+			)
+			for _, p := range profiles {
+				table.Append(render.Text(p.ID), render.Text(p.Name), render.Text(string(p.Currency)))
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func RenameProfile(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename",
+		Short: "Rename a profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			name, _ := cmd.Flags().GetString("name")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			profile.Name = name
+			if err := profileService.UpdateProfile(profile); err != nil {
+				return err
+			}
+
+			fmt.Printf("renamed profile %s to %s\n", profileName, profile.Name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to rename")
+	cmd.Flags().String("name", "", "New profile name")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func ShowProfile(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show a summary of a profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			accounts, err := accountService.LoadAccountsByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			return outputProfile(cmd, profile, accounts)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to show")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func outputProfile(cmd *cobra.Command, profile *domain.Profile, accounts []*domain.Account) error {
+	transactions := profile.Transactions.SortByDate().All()
+	first, last := render.Cell{Text: "-"}, render.Cell{Text: "-"}
+	if len(transactions) > 0 {
+		first = render.Date(transactions[0].Date)
+		last = render.Date(transactions[len(transactions)-1].Date)
+	}
+
+	accountNames := make([]string, 0, len(accounts))
+	for _, a := range accounts {
+		if !a.Closed {
+			accountNames = append(accountNames, a.Name)
+		}
+	}
+	accountsCell := render.List(accountNames)
+	if len(accountNames) == 0 {
+		accountsCell.Text = "-"
+	}
+
+	table := render.NewRecord("Profile", profile.Name,
+		[]render.Column{
+			{Key: "id", Header: "ID"},
+			{Key: "name"},
+			{Key: "currency", Header: "Currency"},
+			{Key: "tag_case", Header: "Tag case"},
+			{Key: "transactions", Header: "Transactions"},
+			{Key: "first_transaction", Header: "First transaction"},
+			{Key: "last_transaction", Header: "Last transaction"},
+			{Key: "total", Header: "Total"},
+			{Key: "accounts", Header: "Accounts"},
+		},
+		render.Text(profile.ID),
+		render.Text(profile.Name),
+		render.Text(string(profile.Currency)),
+		render.Text(string(profile.TagCase)),
+		render.Int(len(transactions)),
+		first,
+		last,
+		render.Totals(profile.Transactions.Totals()),
+		accountsCell,
+	)
+	return renderOutput(cmd, table)
+}
+
+func DeleteProfile(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a profile along with all of its transactions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			confirm, _ := cmd.Flags().GetBool("confirm")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			if !confirm {
+				return errs.New().
+					WithCode(errs.ErrNotConfirmed).
+					WithMessage(fmt.Sprintf("deleting profile `%s` will delete %d transactions: pass --confirm to continue",
+						profile.Name, len(profile.Transactions.All())))
+			}
+
+			// delete the profile.
+			if err := profileService.DeleteProfile(profile.ID); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().Bool("confirm", false, "Confirm that the profile and all of its transactions should be deleted")
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func SetProfileCurrency(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-currency",
+		Short: "Set the default currency of new transactions in the profile",
+		Long: `Set the default currency of new transactions in the profile.
+
+Existing transactions keep their currency.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			currency, err := getCurrencyFlag(cmd, "currency", "")
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			profile.Currency = currency
+			if err := profileService.UpdateProfile(profile); err != nil {
+				return err
+			}
+
+			fmt.Printf("profile %s now uses %s\n", profile.Name, profile.Currency)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("currency", "", "ISO 4217 currency code, such as GBP, EUR or JPY")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("currency")
+
+	return cmd
+}
+
+func SetProfileTagCase(profileService service.Profile) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-tag-case",
+		Short: "Set how the case of new tags in the profile is normalised",
+		Long: `Set how the case of new tags in the profile is normalised.
+
+With lower, tags are converted to lower case so that Travel and travel are the same tag.
+With preserve, tags keep the case they are given. Existing tags are not changed: use tags list --unnormalised to find them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			tagCaseFlag, _ := cmd.Flags().GetString("case")
+
+			tagCase := domain.TagCase(tagCaseFlag)
+			if !tagCase.Valid() {
+				return errs.New().
+					WithCode(errs.ErrInvalidTagCase).
+					WithMessage(fmt.Sprintf("unknown tag case `%s`: expected %s or %s", tagCaseFlag, domain.TagCaseLower, domain.TagCasePreserve))
+			}
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			profile.TagCase = tagCase
+			if err := profileService.UpdateProfile(profile); err != nil {
+				return err
+			}
+
+			fmt.Printf("profile %s now uses %s case tags\n", profile.Name, profile.TagCase)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("case", "", "How to normalise the case of new tags: lower or preserve")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("case")
+
+	return cmd
+}
+
+// addCreateProfileFlag adds the --create-profile flag used by commands that can create the profile they write to.
+func addCreateProfileFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("create-profile", false, "Create the profile if it does not exist")
+}
+
+// loadOrCreateProfileFlag loads the profile named in the --profile flag.
+// The profile is only created if it does not exist and --create-profile is given.
+func loadOrCreateProfileFlag(cmd *cobra.Command, profileService service.Profile) (*domain.Profile, errs.Error) {
+	profileName, _ := cmd.Flags().GetString("profile")
+	if create, _ := cmd.Flags().GetBool("create-profile"); create {
+		return profileService.LoadOrCreateProfileByName(profileName)
+	}
+	profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+	if err != nil && err.Code() == errs.ErrUnknownProfile {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownProfile).
+			WithMessage(fmt.Sprintf("unknown profile `%s`: create it with `profile create` or pass --create-profile", profileName))
+	}
+	return profile, err
+}
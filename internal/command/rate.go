@@ -0,0 +1,175 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/importer"
+	"github.com/tomwright/finance-planner/internal/render"
+	"os"
+	"strconv"
+	"time"
+)
+
+func Rate(exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rate",
+		Short: "Manage the exchange rates used to convert between currencies",
+	}
+
+	cmd.AddCommand(SetRate(exchangeRateService))
+	cmd.AddCommand(ListRates(exchangeRateService))
+	cmd.AddCommand(ImportRates(exchangeRateService))
+
+	return cmd
+}
+
+func SetRate(exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set the exchange rate between two currencies",
+		Long: `Set the exchange rate between two currencies.
+
+The rate is the number of units of the to currency that one unit of the from currency is worth,
+and is used from the given date until the date of the next rate between the same currencies.
+The inverse of the rate is used to convert in the other direction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := getCurrencyFlag(cmd, "from", "")
+			if err != nil {
+				return err
+			}
+			to, err := getCurrencyFlag(cmd, "to", "")
+			if err != nil {
+				return err
+			}
+			date, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
+			if date.IsZero() {
+				date = time.Now()
+			}
+
+			value, _ := cmd.Flags().GetString("rate")
+			rate, parseErr := strconv.ParseFloat(value, 64)
+			if parseErr != nil {
+				return errs.New().
+					WithCode(errs.ErrInvalidExchangeRate).
+					WithMessage(fmt.Sprintf("invalid rate `%s`", value))
+			}
+
+			r := &domain.ExchangeRate{
+				From: from,
+				To:   to,
+				Date: date,
+				Rate: rate,
+			}
+			if err := exchangeRateService.SaveExchangeRates(r); err != nil {
+				return err
+			}
+
+			fmt.Printf("1 %s = %s %s from %s\n", r.From, formatRate(r.Rate), r.To, r.Date.Format(domain.DateFormat))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("from", "", "Currency to convert from")
+	cmd.Flags().String("to", "", "Currency to convert to")
+	cmd.Flags().String("rate", "", "Number of units of the to currency that one unit of the from currency is worth")
+	cmd.Flags().String("date", "", "Date the rate is used from ("+domain.DateFormat+"), defaults to today")
+
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagRequired("rate")
+
+	return cmd
+}
+
+func ListRates(exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List exchange rates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := getCurrencyFlag(cmd, "from", "")
+			if err != nil {
+				return err
+			}
+			to, err := getCurrencyFlag(cmd, "to", "")
+			if err != nil {
+				return err
+			}
+
+			rates, err := exchangeRateService.LoadExchangeRates(from, to)
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable("Exchange rates",
+				render.Column{Key: "date", Header: "Date"},
+				render.Column{Key: "from", Header: "From"},
+				render.Column{Key: "to", Header: "To"},
+				render.Column{Key: "rate", Header: "Rate"},
+			)
+			for _, r := range rates {
+				table.Append(
+					render.Date(r.Date),
+					render.Text(string(r.From)),
+					render.Text(string(r.To)),
+					render.Cell{Text: formatRate(r.Rate), Value: r.Rate},
+				)
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("from", "", "Only show rates from this currency")
+	cmd.Flags().String("to", "", "Only show rates to this currency")
+	addOutputFlag(cmd)
+
+	return cmd
+}
+
+func ImportRates(exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import exchange rates from a CSV file",
+		Long: `Import exchange rates from a CSV file.
+
+The file must have a header row containing the columns date, from, to and rate, in any order.
+Dates must be in the format ` + domain.DateFormat + `. Existing rates on the same date are replaced.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("could not open file: %s", err)
+			}
+			defer f.Close()
+
+			result, err := importer.ParseRatesCSV(f)
+			if err != nil {
+				return err
+			}
+
+			if len(result.Rates) > 0 {
+				if err := exchangeRateService.SaveExchangeRates(result.Rates...); err != nil {
+					return err
+				}
+			}
+
+			fmt.Printf("imported %d of %d rates\n", len(result.Rates), len(result.Rates)+len(result.Errors))
+			outputImportErrors(result.Errors)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// formatRate returns the given exchange rate without trailing zeros.
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
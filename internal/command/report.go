@@ -0,0 +1,19 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/service"
+)
+
+func Report(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarise the transactions in a profile",
+	}
+
+	cmd.AddCommand(ReportTags(profileService, exchangeRateService))
+	cmd.AddCommand(ReportMonthly(profileService, exchangeRateService))
+	cmd.AddCommand(ReportHTML(profileService, exchangeRateService))
+
+	return cmd
+}
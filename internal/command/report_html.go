@@ -0,0 +1,84 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/htmlreport"
+	"os"
+)
+
+func ReportHTML(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "html",
+		Short: "Write a report of the profile as a self-contained HTML page",
+		Long: `Write a report of the profile as a self-contained HTML page that can be opened in any browser.
+
+The report contains the same monthly summary as report monthly, the same breakdown of spending as report tags
+and the largest outgoing transactions, along with charts of each. Charts are drawn as inline SVG and the page
+does not load anything else, so it can be shared as a single file.
+
+The range given by --from and --to starts at the beginning of the month of --from, and defaults to the last 12
+months including the current one up to today. The last month stops at --to in the same way as report monthly.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			filePath, _ := cmd.Flags().GetString("file")
+			top, _ := cmd.Flags().GetInt("top")
+			depth, err := getTagDepthFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dateRange, err := getMonthRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Earlier transactions are needed for the running balance and comparisons.
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{To: dateRange.To})
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+
+			report := htmlreport.NewReport(profile, transactions, dateRange, currency, depth, top)
+			if filePath == "" {
+				if err := htmlreport.Render(os.Stdout, report); err != nil {
+					return fmt.Errorf("could not write report: %s", err)
+				}
+				return nil
+			}
+
+			f, createErr := os.Create(filePath)
+			if createErr != nil {
+				return fmt.Errorf("could not create report file: %s", createErr)
+			}
+			if err := htmlreport.Render(f, report); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("could not write report: %s", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("could not write report: %s", err)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("file", "", "File to write the report to, defaults to stdout")
+	cmd.Flags().Int("top", 10, "Number of the largest outgoing transactions to include")
+	addTagDepthFlag(cmd, "Roll tags up into their ancestor at this depth, such as 1 to count transport/rail as transport")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
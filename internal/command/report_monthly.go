@@ -0,0 +1,148 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"time"
+)
+
+// monthFormat is the format used to show a calendar month.
+const monthFormat = "2006-01"
+
+func ReportMonthly(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "monthly",
+		Short: "Show the incoming, outgoing and net totals of the profile for each month",
+		Long: `Show the incoming, outgoing and net totals of the profile for each month.
+
+The range given by --from and --to starts at the beginning of the month of --from, and defaults to the last 12
+months including the current one up to today. The last month stops at --to, so it only covers part of the month
+unless --to is the last day of a month, and is marked in the output. Transfers between accounts are not counted
+as incoming or outgoing, in the same way as list-transactions --in and --out.
+
+The savings rate is the percentage of incoming funds that were not spent. The balance is the running total of
+every transaction in the profile up to the end of each month. The net of each month is compared with the month
+before and the same month in the previous year.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			dateRange, err := getMonthRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Earlier transactions are needed for the running balance and comparisons.
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{To: dateRange.To})
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+
+			return outputMonthlySummary(cmd, transactions.MonthlySummary(dateRange), currency)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+// getMonthRangeFlags parses the --from and --to flags into a date range starting at the beginning of a month
+// and ending at --to. The date range defaults to the last 12 months, including the current month up to today.
+func getMonthRangeFlags(cmd *cobra.Command) (domain.DateRange, errs.Error) {
+	dateRange, err := getDateRangeFlags(cmd)
+	if err != nil {
+		return domain.DateRange{}, err
+	}
+	if dateRange.To.IsZero() {
+		dateRange.To = domain.TruncateDay(time.Now())
+	}
+	if dateRange.From.IsZero() {
+		dateRange.From = dateRange.To.AddDate(0, 0, 1-dateRange.To.Day()).AddDate(0, -11, 0)
+	}
+	dateRange.From = dateRange.From.AddDate(0, 0, 1-dateRange.From.Day())
+	if dateRange.To.Before(dateRange.From) {
+		return domain.DateRange{}, errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithMessage("from date must not be after the to date")
+	}
+	return dateRange, nil
+}
+
+func outputMonthlySummary(cmd *cobra.Command, summary *domain.MonthlySummary, currency domain.Currency) error {
+	table := render.NewTable("Monthly summary",
+		render.Column{Key: "month", Header: "Month"},
+		render.Column{Key: "last_day"},
+		render.Column{Key: "count"},
+		render.Column{Key: "incoming", Header: "Incoming"},
+		render.Column{Key: "outgoing", Header: "Outgoing"},
+		render.Column{Key: "net", Header: "Net"},
+		render.Column{Key: "savings_rate", Header: "Saved"},
+		render.Column{Key: "balance", Header: "Balance"},
+		render.Column{Key: "previous_month_change", Header: "vs Last month"},
+		render.Column{Key: "last_year_change", Header: "vs Last year"},
+		render.Column{Key: "currency"},
+		render.Column{Key: "previous_month_net"},
+		render.Column{Key: "last_year_net"},
+	)
+
+	money := func(amount int64) render.Cell {
+		return render.Money(domain.NewMoney(amount, currency))
+	}
+	// compare returns the net of the given month, and the change in net since then.
+	compare := func(month *domain.MonthTotal, with *domain.MonthTotal) (render.Cell, render.Cell) {
+		if with == nil {
+			return render.Cell{Text: "-"}, render.Cell{Text: "-"}
+		}
+		change := money(month.Net() - with.Net())
+		if month.Net() > with.Net() {
+			change.Text = "+" + change.Text
+		}
+		return money(with.Net()), change
+	}
+
+	for _, m := range summary.Months {
+		previousNet, previousChange := compare(m, m.PreviousMonth)
+		lastYearNet, lastYearChange := compare(m, m.LastYear)
+		month := render.Text(m.Month.Format(monthFormat))
+		if m.Partial() {
+			month.Text += fmt.Sprintf(" (to %s)", m.LastDay.Format(domain.DateFormat))
+		}
+		table.Append(
+			month,
+			render.Date(m.LastDay),
+			render.Int(m.Count),
+			money(m.Incoming),
+			money(m.Outgoing),
+			money(m.Net()),
+			render.Cell{Text: formatPercent(m.SavingsRate()), Value: m.SavingsRate()},
+			money(m.Balance),
+			previousChange,
+			lastYearChange,
+			render.Text(string(currency)),
+			previousNet,
+			lastYearNet,
+		)
+	}
+	table.Footer = []string{"Total",
+		formatAmount(summary.Incoming, currency),
+		formatAmount(summary.Outgoing, currency),
+		formatAmount(summary.Net(), currency),
+		formatPercent(summary.SavingsRate()),
+		formatAmount(summary.Closing(), currency), "", ""}
+	return renderOutput(cmd, table)
+}
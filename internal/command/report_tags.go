@@ -0,0 +1,107 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+)
+
+func ReportTags(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "Show a breakdown of the transactions in the profile by tag",
+		Long: `Show a breakdown of the transactions in the profile by tag.
+
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			split, _ := cmd.Flags().GetString("split")
+			depth, err := getTagDepthFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			tagSplit := domain.TagSplit(split)
+			if !tagSplit.Valid() {
+				return errs.New().
+					WithCode(errs.ErrInvalidTagSplit).
+					WithMessage(fmt.Sprintf("unknown split `%s`: expected %s or %s", split, domain.TagSplitEach, domain.TagSplitEven))
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, dateRange)
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+
+			return outputTagBreakdown(cmd, transactions.GroupByTagDepth(tagSplit, depth), currency)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("split", string(domain.TagSplitEach),
+		"How to count transactions with multiple tags: each counts the full amount in every tag, even splits the amount between them")
+	addTagDepthFlag(cmd, "Roll tags up into their ancestor at this depth, such as 1 to count transport/rail as transport")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func outputTagBreakdown(cmd *cobra.Command, breakdown *domain.TagBreakdown, currency domain.Currency) error {
+	table := render.NewTable("Transactions by tag",
+		render.Column{Key: "tag", Header: "Tag"},
+		render.Column{Key: "count", Header: "Count"},
+		render.Column{Key: "incoming", Header: "Incoming"},
+		render.Column{Key: "incoming_percent", Header: "% In"},
+		render.Column{Key: "outgoing", Header: "Outgoing"},
+		render.Column{Key: "outgoing_percent", Header: "% Out"},
+		render.Column{Key: "net", Header: "Net"},
+		render.Column{Key: "currency"},
+	)
+
+	money := func(amount int64) render.Cell {
+		return render.Money(domain.NewMoney(amount, currency))
+	}
+	percent := func(percent float64) render.Cell {
+		return render.Cell{Text: formatPercent(percent), Value: percent}
+	}
+	row := func(name render.Cell, total *domain.TagTotal) {
+		table.Append(
+			name,
+			render.Int(total.Count),
+			money(total.Incoming),
+			percent(total.IncomingPercent),
+			money(total.Outgoing),
+			percent(total.OutgoingPercent),
+			money(total.Net()),
+			render.Text(string(currency)),
+		)
+	}
+
+	for _, total := range breakdown.Tags {
+		row(render.Text(total.Tag), total)
+	}
+	if breakdown.Untagged.Count > 0 {
+		row(render.Cell{Text: "(untagged)", Value: ""}, breakdown.Untagged)
+	}
+	table.Footer = []string{"", "Total",
+		formatAmount(breakdown.Incoming, currency), "",
+		formatAmount(breakdown.Outgoing, currency), "",
+		formatAmount(breakdown.Incoming+breakdown.Outgoing, currency)}
+	return renderOutput(cmd, table)
+}
@@ -0,0 +1,370 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"strings"
+)
+
+func Rules(profileService service.Profile, accountService service.Account, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rules",
+		Short: "Manage the rules that tag transactions automatically",
+		Long: `Manage the rules that tag transactions automatically.
+
+Rules are applied in order to each new transaction, and add their tags to transactions that match all of their
+conditions. A rule with --stop prevents any later rules from applying to the transactions it matches.`,
+	}
+
+	cmd.AddCommand(AddRule(profileService, accountService, tagRuleService))
+	cmd.AddCommand(ListRules(profileService, accountService, tagRuleService))
+	cmd.AddCommand(MoveRule(profileService, tagRuleService))
+	cmd.AddCommand(DeleteRule(profileService, tagRuleService))
+	cmd.AddCommand(ApplyRules(profileService, tagRuleService))
+
+	return cmd
+}
+
+func AddRule(profileService service.Profile, accountService service.Account, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a rule after the existing rules in the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			rule := domain.NewTagRule()
+			rule.ProfileID = profile.ID
+			rule.LabelContains, _ = cmd.Flags().GetString("label-contains")
+			rule.LabelPattern, _ = cmd.Flags().GetString("label-pattern")
+			rule.Stop, _ = cmd.Flags().GetBool("stop")
+			tags, _ := cmd.Flags().GetStringArray("tags")
+			rule.WithTags(tags...)
+
+			account, err := loadAccountFlag(cmd, accountService, profile, "account")
+			if err != nil {
+				return err
+			}
+			if account != nil {
+				rule.AccountID = account.ID
+			}
+
+			if cmd.Flags().Changed("min-amount") || cmd.Flags().Changed("max-amount") {
+				fallback := profile.Currency
+				if account != nil {
+					fallback = account.Currency
+				}
+				if rule.Currency, err = getCurrencyFlag(cmd, "currency", fallback); err != nil {
+					return err
+				}
+				if rule.MinAmount, err = getRuleAmountFlag(cmd, "min-amount", rule.Currency); err != nil {
+					return err
+				}
+				if rule.MaxAmount, err = getRuleAmountFlag(cmd, "max-amount", rule.Currency); err != nil {
+					return err
+				}
+			}
+
+			if err := tagRuleService.CreateTagRule(rule); err != nil {
+				return err
+			}
+
+			fmt.Printf("added rule %d: %s\n", rule.Position, rule.ID)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("label-contains", "", "Match transactions with a label containing this text, ignoring case")
+	cmd.Flags().String("label-pattern", "", "Match transactions with a label matching this regular expression")
+	cmd.Flags().String("min-amount", "", "Match transactions with an amount of at least this, such as -50.00")
+	cmd.Flags().String("max-amount", "", "Match transactions with an amount of at most this, such as -10.00")
+	cmd.Flags().String("currency", "", "Currency of the amounts, defaults to the account or profile currency")
+	cmd.Flags().String("account", "", "Match transactions in the account with this name")
+	cmd.Flags().StringArray("tags", []string{}, "Tags to add to matching transactions")
+	cmd.Flags().Bool("stop", false, "Do not apply any later rules to matching transactions")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tags")
+
+	return cmd
+}
+
+// getRuleAmountFlag parses the value of the given flag as a signed amount of the given currency.
+// Nil is returned if the flag is empty.
+func getRuleAmountFlag(cmd *cobra.Command, name string, currency domain.Currency) (*int64, errs.Error) {
+	if value, _ := cmd.Flags().GetString(name); value == "" {
+		return nil, nil
+	}
+	money, err := getMoneyFlag(cmd, name, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &money.Amount, nil
+}
+
+func ListRules(profileService service.Profile, accountService service.Account, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the rules in the profile in the order they are applied",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			rules, err := tagRuleService.LoadTagRulesByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			accounts, err := accountService.LoadAccountsByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+			accountNames := make(map[string]string, len(accounts))
+			for _, a := range accounts {
+				accountNames[a.ID] = a.Name
+			}
+
+			table := render.NewTable("Tag rules",
+				render.Column{Key: "position", Header: "#"},
+				render.Column{Key: "id", Header: "ID"},
+				render.Column{Key: "conditions", Header: "Conditions"},
+				render.Column{Key: "tags", Header: "Tags"},
+				render.Column{Key: "stop", Header: "Stop"},
+				render.Column{Key: "label_contains"},
+				render.Column{Key: "label_pattern"},
+				render.Column{Key: "min_amount"},
+				render.Column{Key: "max_amount"},
+				render.Column{Key: "currency"},
+				render.Column{Key: "account_id"},
+			)
+			for _, r := range rules {
+				table.Append(
+					render.Int(r.Position),
+					render.Text(r.ID),
+					render.Text(describeTagRule(r, accountNames)),
+					render.List(r.Tags),
+					render.Bool(r.Stop),
+					render.Text(r.LabelContains),
+					render.Text(r.LabelPattern),
+					ruleAmountCell(r.MinAmount, r.Currency),
+					ruleAmountCell(r.MaxAmount, r.Currency),
+					render.Text(string(r.Currency)),
+					render.Text(r.AccountID),
+				)
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+// ruleAmountCell returns a cell containing the given amount, or nothing if it is nil.
+func ruleAmountCell(amount *int64, currency domain.Currency) render.Cell {
+	if amount == nil {
+		return render.Cell{}
+	}
+	return render.Money(domain.NewMoney(*amount, currency))
+}
+
+// describeTagRule returns a short description of the conditions of the given rule.
+// accountNames maps account ids to their names.
+func describeTagRule(rule *domain.TagRule, accountNames map[string]string) string {
+	conditions := make([]string, 0)
+	if rule.LabelContains != "" {
+		conditions = append(conditions, fmt.Sprintf("label contains %q", rule.LabelContains))
+	}
+	if rule.LabelPattern != "" {
+		conditions = append(conditions, fmt.Sprintf("label matches /%s/", rule.LabelPattern))
+	}
+	switch {
+	case rule.MinAmount != nil && rule.MaxAmount != nil:
+		conditions = append(conditions, fmt.Sprintf("amount from %s to %s",
+			domain.NewMoney(*rule.MinAmount, rule.Currency), domain.NewMoney(*rule.MaxAmount, rule.Currency)))
+	case rule.MinAmount != nil:
+		conditions = append(conditions, fmt.Sprintf("amount >= %s", domain.NewMoney(*rule.MinAmount, rule.Currency)))
+	case rule.MaxAmount != nil:
+		conditions = append(conditions, fmt.Sprintf("amount <= %s", domain.NewMoney(*rule.MaxAmount, rule.Currency)))
+	}
+	if rule.AccountID != "" {
+		name, ok := accountNames[rule.AccountID]
+		if !ok {
+			name = rule.AccountID
+		}
+		conditions = append(conditions, "account "+name)
+	}
+	return strings.Join(conditions, " and ")
+}
+
+func MoveRule(profileService service.Profile, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "move",
+		Short: "Change the order a rule is applied in",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			position, _ := cmd.Flags().GetInt("position")
+
+			rule, err := loadProfileTagRule(cmd, profileService, tagRuleService)
+			if err != nil {
+				return err
+			}
+
+			if position < 1 {
+				return errs.New().
+					WithCode(errs.ErrInvalidTagRule).
+					WithMessage("--position must be at least 1")
+			}
+
+			if err := tagRuleService.MoveTagRule(rule.ID, position); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("id", "", "Rule ID")
+	cmd.Flags().Int("position", 0, "New position of the rule, where 1 is applied first")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("id")
+	_ = cmd.MarkFlagRequired("position")
+
+	return cmd
+}
+
+func DeleteRule(profileService service.Profile, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a rule, leaving the tags it has already added",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule, err := loadProfileTagRule(cmd, profileService, tagRuleService)
+			if err != nil {
+				return err
+			}
+
+			if err := tagRuleService.DeleteTagRule(rule.ID); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("id", "", "Rule ID")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func ApplyRules(profileService service.Profile, tagRuleService service.TagRule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply the rules to existing transactions in the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			changes, err := tagRuleService.ApplyTagRules(profile.ID, dateRange, dryRun)
+			if err != nil {
+				return err
+			}
+
+			title := "Tags added"
+			if dryRun {
+				title = "Tags that would be added"
+			}
+			table := render.NewTable(title,
+				render.Column{Key: "id", Header: "ID"},
+				render.Column{Key: "date", Header: "Date"},
+				render.Column{Key: "label", Header: "Label"},
+				render.Column{Key: "amount", Header: "Amount"},
+				render.Column{Key: "currency"},
+				render.Column{Key: "tags", Header: "Existing tags"},
+				render.Column{Key: "added", Header: "Added tags"},
+			)
+			for _, c := range changes {
+				t := c.Transaction
+				added := render.List(c.Added)
+				added.Text = "+" + strings.Join(c.Added, ", +")
+				table.Append(
+					render.Text(t.ID),
+					render.Date(t.Date),
+					render.Text(t.Label),
+					render.Money(t.Money()),
+					render.Text(string(t.Currency)),
+					render.List(t.Tags),
+					added,
+				)
+			}
+			table.Footer = []string{"", "", "", "", "Transactions", fmt.Sprint(len(changes))}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().Bool("dry-run", false, "Show the tags that would be added without saving them")
+	addDateRangeFlags(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+// loadProfileTagRule loads the rule given in the --id flag, ensuring that it belongs
+// to the profile given in the --profile flag.
+func loadProfileTagRule(cmd *cobra.Command, profileService service.Profile, tagRuleService service.TagRule) (*domain.TagRule, errs.Error) {
+	profileName, _ := cmd.Flags().GetString("profile")
+	id, _ := cmd.Flags().GetString("id")
+
+	profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := tagRuleService.LoadTagRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.ProfileID != profile.ID {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownTagRule).
+			WithMessage("unknown tag rule")
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,326 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"strings"
+	"time"
+)
+
+func Schedule(profileService service.Profile, scheduleService service.Schedule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage recurring transactions",
+	}
+
+	cmd.AddCommand(AddSchedule(profileService, scheduleService))
+	cmd.AddCommand(ListSchedules(profileService, scheduleService))
+	cmd.AddCommand(PauseSchedule(profileService, scheduleService, true))
+	cmd.AddCommand(PauseSchedule(profileService, scheduleService, false))
+	cmd.AddCommand(EndSchedule(profileService, scheduleService))
+
+	return cmd
+}
+
+func AddSchedule(profileService service.Profile, scheduleService service.Schedule) *cobra.Command {
+	frequencies := make([]string, len(domain.Frequencies))
+	for i, f := range domain.Frequencies {
+		frequencies[i] = string(f)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a recurring transaction to the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			label, _ := cmd.Flags().GetString("label")
+			tags, _ := cmd.Flags().GetStringArray("tags")
+			frequency, _ := cmd.Flags().GetString("frequency")
+			interval, _ := cmd.Flags().GetInt("interval")
+			dayOfMonth, _ := cmd.Flags().GetInt("day")
+			start, err := getDateFlag(cmd, "start")
+			if err != nil {
+				return err
+			}
+			end, err := getDateFlag(cmd, "end")
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", profile.Currency)
+			if err != nil {
+				return err
+			}
+			amount, err := getMoneyFlag(cmd, "amount", currency)
+			if err != nil {
+				return err
+			}
+
+			// create the schedule.
+			s := domain.NewSchedule()
+			s.ProfileID = profile.ID
+			s.Label = label
+			s.Amount = amount.Amount
+			s.Currency = amount.Currency
+			s.Tags = tags
+			s.Frequency = domain.Frequency(frequency)
+			s.Interval = interval
+			s.DayOfMonth = dayOfMonth
+			s.Start = start
+			s.End = end
+
+			// save the schedule.
+			if err := scheduleService.CreateSchedule(s); err != nil {
+				return err
+			}
+
+			fmt.Printf("created schedule %s\n", s.ID)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("label", "", "Transaction label")
+	cmd.Flags().String("amount", "", "Transaction amount, such as -435.00")
+	cmd.Flags().String("currency", "", "Transaction currency, defaults to the profile currency")
+	cmd.Flags().StringArray("tags", []string{}, "Tags to group the transactions")
+	cmd.Flags().String("frequency", "", "How often the transaction repeats: "+strings.Join(frequencies, ", "))
+	cmd.Flags().Int("interval", 1, "Number of days, weeks, months or years between each transaction")
+	cmd.Flags().Int("day", 0, "Day of the month for monthly schedules, defaults to the day of the start date")
+	cmd.Flags().String("start", "", "First day the transaction can repeat on ("+domain.DateFormat+"), defaults to today")
+	cmd.Flags().String("end", "", "Last day the transaction can repeat on ("+domain.DateFormat+")")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("label")
+	_ = cmd.MarkFlagRequired("amount")
+	_ = cmd.MarkFlagRequired("frequency")
+
+	return cmd
+}
+
+func ListSchedules(profileService service.Profile, scheduleService service.Schedule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all recurring transactions for the profile",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			schedules, err := scheduleService.LoadSchedulesByProfileID(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			return outputSchedules(cmd, schedules)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+// PauseSchedule returns a command to pause a schedule if pause is true, or resume it if pause is false.
+func PauseSchedule(profileService service.Profile, scheduleService service.Schedule, pause bool) *cobra.Command {
+	use, short := "pause", "Stop a recurring transaction from repeating until it is resumed"
+	if !pause {
+		use, short = "resume", "Resume a paused recurring transaction"
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := loadProfileSchedule(cmd, profileService, scheduleService)
+			if err != nil {
+				return err
+			}
+
+			s.Paused = pause
+
+			// save the schedule.
+			if err := scheduleService.UpdateSchedule(s); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("id", "", "Schedule ID")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func EndSchedule(profileService service.Profile, scheduleService service.Schedule) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "end",
+		Short: "Stop a recurring transaction from repeating after the given date",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			end, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
+			if end.IsZero() {
+				end = domain.TruncateDay(time.Now())
+			}
+
+			s, err := loadProfileSchedule(cmd, profileService, scheduleService)
+			if err != nil {
+				return err
+			}
+
+			s.End = end
+
+			// save the schedule.
+			if err := scheduleService.UpdateSchedule(s); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("id", "", "Schedule ID")
+	cmd.Flags().String("date", "", "Last day the transaction can repeat on ("+domain.DateFormat+"), defaults to today")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+// loadProfileSchedule loads the schedule given in the --id flag, ensuring that it belongs
+// to the profile given in the --profile flag.
+func loadProfileSchedule(cmd *cobra.Command, profileService service.Profile, scheduleService service.Schedule) (*domain.Schedule, errs.Error) {
+	profileName, _ := cmd.Flags().GetString("profile")
+	id, _ := cmd.Flags().GetString("id")
+
+	profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := scheduleService.LoadScheduleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.ProfileID != profile.ID {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownSchedule).
+			WithMessage("unknown schedule")
+	}
+
+	return s, nil
+}
+
+// includeProjected returns the given transactions along with the transactions that the profiles
+// schedules will create after today and within the given date range.
+func includeProjected(scheduleService service.Schedule, profile *domain.Profile, dateRange domain.DateRange, transactions *domain.TransactionCollection) (*domain.TransactionCollection, errs.Error) {
+	if dateRange.To.IsZero() {
+		return nil, errs.New().
+			WithCode(errs.ErrInvalidPeriod).
+			WithMessage("projected transactions require a --to date")
+	}
+	tomorrow := domain.TruncateDay(time.Now()).AddDate(0, 0, 1)
+	if dateRange.From.Before(tomorrow) {
+		dateRange.From = tomorrow
+	}
+	projected, err := scheduleService.ProjectTransactions(profile.ID, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTransactionCollection().
+		Add(transactions.All()...).
+		Add(projected.All()...).
+		SortByDate(), nil
+}
+
+func outputSchedules(cmd *cobra.Command, schedules []*domain.Schedule) error {
+	table := render.NewTable("Schedules",
+		render.Column{Key: "id", Header: "ID"},
+		render.Column{Key: "label", Header: "Label"},
+		render.Column{Key: "tags", Header: "Tags"},
+		render.Column{Key: "amount", Header: "Amount"},
+		render.Column{Key: "currency"},
+		render.Column{Key: "frequency"},
+		render.Column{Key: "interval"},
+		render.Column{Key: "day_of_month"},
+		render.Column{Key: "repeats", Header: "Repeats"},
+		render.Column{Key: "start", Header: "Start"},
+		render.Column{Key: "end", Header: "End"},
+		render.Column{Key: "status", Header: "Status"},
+	)
+
+	for _, s := range schedules {
+		status := "active"
+		switch {
+		case s.Paused:
+			status = "paused"
+		case !s.End.IsZero() && s.End.Before(domain.TruncateDay(time.Now())):
+			status = "ended"
+		}
+		table.Append(
+			render.Text(s.ID),
+			render.Text(s.Label),
+			render.List(s.Tags),
+			render.Money(domain.NewMoney(s.Amount, s.Currency)),
+			render.Text(string(s.Currency)),
+			render.Text(string(s.Frequency)),
+			render.Int(s.Interval),
+			render.Int(s.DayOfMonth),
+			render.Text(describeFrequency(s)),
+			render.Date(s.Start),
+			render.Date(s.End),
+			render.Text(status),
+		)
+	}
+	return renderOutput(cmd, table)
+}
+
+// describeFrequency returns a human readable description of how often the schedule repeats.
+func describeFrequency(s *domain.Schedule) string {
+	every := func(unit string) string {
+		if s.Interval == 1 {
+			return "every " + unit
+		}
+		return fmt.Sprintf("every %d %ss", s.Interval, unit)
+	}
+	switch s.Frequency {
+	case domain.FrequencyDaily:
+		return every("day")
+	case domain.FrequencyWeekly:
+		return every("week") + " on " + s.Start.Weekday().String()
+	case domain.FrequencyMonthly:
+		return every("month") + fmt.Sprintf(" on day %d", s.DayOfMonth)
+	case domain.FrequencyLastWorkingDay:
+		return every("month") + " on the last working day"
+	case domain.FrequencyYearly:
+		return every("year") + " on " + s.Start.Format("2 January")
+	default:
+		return string(s.Frequency)
+	}
+}
@@ -0,0 +1,314 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/render"
+	"strings"
+)
+
+func Tags(profileService service.Profile, exchangeRateService service.ExchangeRate, tagService service.Tag) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags",
+		Short: "View and change the tags used in a profile",
+		Long: `View and change the tags used in a profile.
+
+Tags can be arranged in a hierarchy by separating their parts with ` + domain.TagSeparator + `, such as transport/rail.
+Tags without a separator are top-level tags.
+
+New tags are normalised by removing extra whitespace and, unless the profile preserves case, converting them to
+lower case. Use rename, merge and delete to tidy up existing tags.`,
+	}
+
+	cmd.AddCommand(ListTags(profileService, tagService))
+	cmd.AddCommand(TagTree(profileService, exchangeRateService))
+	cmd.AddCommand(RenameTag(profileService, tagService, false))
+	cmd.AddCommand(RenameTag(profileService, tagService, true))
+	cmd.AddCommand(DeleteTag(profileService, tagService))
+
+	return cmd
+}
+
+// addTagDepthFlag adds the --depth flag used to limit how deep into the tag hierarchy to go.
+func addTagDepthFlag(cmd *cobra.Command, usage string) {
+	cmd.Flags().Int("depth", 0, usage)
+}
+
+// getTagDepthFlag parses the value of the --depth flag.
+func getTagDepthFlag(cmd *cobra.Command) (int, errs.Error) {
+	depth, _ := cmd.Flags().GetInt("depth")
+	if depth < 0 {
+		return 0, errs.New().
+			WithCode(errs.ErrInvalidArgument).
+			WithMessage("--depth must not be negative")
+	}
+	return depth, nil
+}
+
+func ListTags(profileService service.Profile, tagService service.Tag) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the tags used in the profile and how many times each is used",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			unnormalised, _ := cmd.Flags().GetBool("unnormalised")
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			usage, err := tagService.ListTags(profile.ID)
+			if err != nil {
+				return err
+			}
+
+			table := render.NewTable("Tags",
+				render.Column{Key: "tag", Header: "Tag"},
+				render.Column{Key: "transactions", Header: "Transactions"},
+				render.Column{Key: "schedules", Header: "Schedules"},
+				render.Column{Key: "tag_rules", Header: "Rules"},
+				render.Column{Key: "normalised", Header: "Normalised"},
+			)
+			for _, u := range usage {
+				normalised := domain.NormaliseTag(u.Tag, profile.TagCase)
+				if unnormalised && normalised == u.Tag {
+					continue
+				}
+				normalisedCell := render.Text(normalised)
+				if normalised == u.Tag {
+					normalisedCell.Text = ""
+				}
+				table.Append(
+					render.Text(u.Tag),
+					render.Int(u.Transactions),
+					render.Int(u.Schedules),
+					render.Int(u.TagRules),
+					normalisedCell,
+				)
+			}
+			return renderOutput(cmd, table)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().Bool("unnormalised", false, "Only list tags that were saved before normalisation and would now be saved differently")
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func TagTree(profileService service.Profile, exchangeRateService service.ExchangeRate) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tree",
+		Short: "Show the tag hierarchy along with the totals of each tag",
+		Long: `Show the tag hierarchy along with the totals of each tag.
+
+The totals of each tag include the transactions of all of its descendants, with each transaction counted once.
+Transactions in other currencies are converted into the reporting currency using the exchange rate in effect on the day of each transaction.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			depth, err := getTagDepthFlag(cmd)
+			if err != nil {
+				return err
+			}
+			dateRange, err := getDateRangeFlags(cmd)
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, dateRange)
+			if err != nil {
+				return err
+			}
+
+			transactions, currency, err := convertToReportingCurrency(cmd, exchangeRateService, profile, profile.Transactions)
+			if err != nil {
+				return err
+			}
+
+			return outputTagTree(cmd, transactions.TagTree(), depth, currency)
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	addTagDepthFlag(cmd, "Only show tags up to this depth, where 1 is the top-level tags")
+	addDateRangeFlags(cmd)
+	addReportingCurrencyFlag(cmd)
+	addOutputFlag(cmd)
+
+	_ = cmd.MarkFlagRequired("profile")
+
+	return cmd
+}
+
+func outputTagTree(cmd *cobra.Command, tree *domain.TagTree, maxDepth int, currency domain.Currency) error {
+	table := render.NewTable("Tag hierarchy",
+		render.Column{Key: "tag", Header: "Tag"},
+		render.Column{Key: "depth"},
+		render.Column{Key: "count", Header: "Count"},
+		render.Column{Key: "incoming", Header: "Incoming"},
+		render.Column{Key: "incoming_percent", Header: "% In"},
+		render.Column{Key: "outgoing", Header: "Outgoing"},
+		render.Column{Key: "outgoing_percent", Header: "% Out"},
+		render.Column{Key: "net", Header: "Net"},
+		render.Column{Key: "currency"},
+	)
+
+	money := func(amount int64) render.Cell {
+		return render.Money(domain.NewMoney(amount, currency))
+	}
+	percent := func(percent float64) render.Cell {
+		return render.Cell{Text: formatPercent(percent), Value: percent}
+	}
+	row := func(name render.Cell, depth int, total *domain.TagTotal) {
+		table.Append(
+			name,
+			render.Int(depth),
+			render.Int(total.Count),
+			money(total.Incoming),
+			percent(total.IncomingPercent),
+			money(total.Outgoing),
+			percent(total.OutgoingPercent),
+			money(total.Net()),
+			render.Text(string(currency)),
+		)
+	}
+
+	tree.Walk(func(node *domain.TagNode, depth int) {
+		if maxDepth > 0 && depth > maxDepth {
+			return
+		}
+		name := render.Cell{Text: strings.Repeat("  ", depth-1) + node.Name, Value: node.Tag}
+		row(name, depth, &node.TagTotal)
+	})
+	if tree.Untagged.Count > 0 {
+		row(render.Cell{Text: "(untagged)", Value: ""}, 0, tree.Untagged)
+	}
+	table.Footer = []string{"Total", "",
+		formatAmount(tree.Incoming, currency), "",
+		formatAmount(tree.Outgoing, currency), "",
+		formatAmount(tree.Incoming+tree.Outgoing, currency)}
+	return renderOutput(cmd, table)
+}
+
+// RenameTag returns the tags rename command, or the tags merge command if merge is true.
+func RenameTag(profileService service.Profile, tagService service.Tag, merge bool) *cobra.Command {
+	use, short, to := "rename", "Rename a tag and its descendants", "to"
+	envelopes := "Envelopes move with the tag, so the new name must not already have an envelope."
+	if merge {
+		use, short, to = "merge", "Merge a tag and its descendants into another tag", "into"
+		envelopes = `Envelopes move with the tag. When the tag merged into already has an envelope, that envelope is kept
+and the merged envelope is deleted.`
+	}
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: short + ` in every transaction, schedule and tag rule in the profile.
+
+Descendants keep their place below the tag, so renaming transport to travel renames transport/rail to travel/rail.
+
+` + envelopes,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			from, _ := cmd.Flags().GetString("tag")
+			into, _ := cmd.Flags().GetString(to)
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			var changed int
+			var dropped []*domain.Envelope
+			if merge {
+				changed, dropped, err = tagService.MergeTag(profile.ID, from, into)
+			} else {
+				changed, err = tagService.RenameTag(profile.ID, from, into)
+			}
+			if err != nil {
+				return err
+			}
+
+			verb := "renamed"
+			if merge {
+				verb = "merged"
+			}
+			fmt.Printf("%s %s to %s in %d transactions\n", verb, from, into, changed)
+			for _, e := range dropped {
+				fmt.Printf("deleted the envelope for %s because the tag it was merged into already has an envelope\n", e.Tag)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("tag", "", "Tag to "+use)
+	if merge {
+		cmd.Flags().String(to, "", "Tag to merge into, which may already be in use")
+	} else {
+		cmd.Flags().String(to, "", "New name for the tag, which must not already be in use")
+	}
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tag")
+	_ = cmd.MarkFlagRequired(to)
+
+	return cmd
+}
+
+func DeleteTag(profileService service.Profile, tagService service.Tag) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Remove a tag and its descendants from the profile",
+		Long: `Remove a tag and its descendants from every transaction, schedule and tag rule in the profile.
+
+The transactions themselves are kept. Tag rules that no longer add any tags are deleted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			tag, _ := cmd.Flags().GetString("tag")
+			confirm, _ := cmd.Flags().GetBool("confirm")
+
+			profile, err := profileService.LoadProfileByNameWithoutTransactions(profileName)
+			if err != nil {
+				return err
+			}
+
+			if !confirm {
+				count, err := tagService.CountTaggedTransactions(profile.ID, tag)
+				if err != nil {
+					return err
+				}
+				return errs.New().
+					WithCode(errs.ErrNotConfirmed).
+					WithMessage(fmt.Sprintf("deleting tag `%s` will remove it from %d transactions: pass --confirm to continue",
+						tag, count))
+			}
+
+			changed, err := tagService.DeleteTag(profile.ID, tag)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("deleted %s from %d transactions\n", tag, changed)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("tag", "", "Tag to delete")
+	cmd.Flags().Bool("confirm", false, "Confirm that the tag should be removed from every transaction")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("tag")
+
+	return cmd
+}
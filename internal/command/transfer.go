@@ -0,0 +1,86 @@
+package command
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+)
+
+func Transfer(profileService service.Profile, accountService service.Account) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Transfer money between two accounts in the profile",
+		Long: `Transfer money between two accounts in the profile.
+
+A transfer is recorded as a pair of linked transactions: one going out of the from account and one coming into the to account.
+Transfers change the balance of each account, but do not count as income or spending in budgets and reports.
+
+Use --received when the accounts use different currencies to give the amount that arrived in the to account.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			label, _ := cmd.Flags().GetString("label")
+			tags, _ := cmd.Flags().GetStringArray("tags")
+			date, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
+
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
+			if err != nil {
+				return err
+			}
+
+			from, err := loadAccountFlag(cmd, accountService, profile, "from")
+			if err != nil {
+				return err
+			}
+			to, err := loadAccountFlag(cmd, accountService, profile, "to")
+			if err != nil {
+				return err
+			}
+
+			amount, err := getMoneyFlag(cmd, "amount", from.Currency)
+			if err != nil {
+				return err
+			}
+			received, err := getMoneyFlag(cmd, "received", to.Currency)
+			if err != nil {
+				return err
+			}
+
+			transfer := &domain.Transfer{
+				From:     from,
+				To:       to,
+				Amount:   amount,
+				Received: received,
+				Label:    label,
+				Tags:     tags,
+				Date:     date,
+			}
+			if err := accountService.Transfer(transfer); err != nil {
+				return err
+			}
+
+			fmt.Printf("transferred %s from %s to %s\n", transfer.Amount, from.Name, to.Name)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("profile", "", "Profile to interact with")
+	cmd.Flags().String("from", "", "Name of the account the money leaves")
+	cmd.Flags().String("to", "", "Name of the account the money arrives in")
+	cmd.Flags().String("amount", "", "Amount leaving the from account, such as 250.00")
+	cmd.Flags().String("received", "", "Amount arriving in the to account, if the accounts use different currencies")
+	cmd.Flags().String("label", "", "Transfer label, defaults to Transfer to/from the other account")
+	cmd.Flags().StringArray("tags", []string{}, "Tags to group the transfer")
+	cmd.Flags().String("date", "", "Transfer date ("+domain.DateFormat+"), defaults to today")
+
+	_ = cmd.MarkFlagRequired("profile")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	_ = cmd.MarkFlagRequired("amount")
+
+	return cmd
+}
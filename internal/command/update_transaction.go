@@ -2,11 +2,12 @@ package command
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/application/service"
 	"github.com/tomwright/finance-planner/internal/errs"
 )
 
-func UpdateTransaction(profileService service.Profile) *cobra.Command {
+func UpdateTransaction(profileService service.Profile, accountService service.Account) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update-transaction",
 		Short: "Update a transaction in the profile",
@@ -14,10 +15,13 @@ func UpdateTransaction(profileService service.Profile) *cobra.Command {
 			profileName, _ := cmd.Flags().GetString("profile")
 			id, _ := cmd.Flags().GetString("id")
 			label, _ := cmd.Flags().GetString("label")
-			amount, _ := cmd.Flags().GetInt64("amount")
 			tags, _ := cmd.Flags().GetStringArray("tags")
+			date, err := getDateFlag(cmd, "date")
+			if err != nil {
+				return err
+			}
 
-			profile, err := profileService.LoadProfileByName(profileName)
+			profile, err := profileService.LoadProfileByName(profileName, domain.DateRange{})
 			if err != nil {
 				return err
 			}
@@ -33,15 +37,41 @@ func UpdateTransaction(profileService service.Profile) *cobra.Command {
 					WithMessage("unknown transaction")
 			}
 
+			account, err := loadAccountFlag(cmd, accountService, profile, "account")
+			if err != nil {
+				return err
+			}
+
+			currency, err := getCurrencyFlag(cmd, "currency", t.Currency)
+			if err != nil {
+				return err
+			}
+			if currency != t.Currency && !cmd.Flags().Changed("amount") {
+				return errs.New().
+					WithCode(errs.ErrInvalidAmount).
+					WithMessage("--amount must be given when changing the currency")
+			}
+			amount, err := getMoneyFlag(cmd, "amount", currency)
+			if err != nil {
+				return err
+			}
+
 			if label != "" {
 				t.Label = label
 			}
-			if amount != 0 {
-				t.Amount = amount
+			if amount.Amount != 0 {
+				t.Amount = amount.Amount
+				t.Currency = amount.Currency
 			}
 			if len(tags) > 0 {
 				t.Tags = tags
 			}
+			if account != nil {
+				t.AccountID = account.ID
+			}
+			if !date.IsZero() {
+				t.WithDate(date)
+			}
 
 			// save the transaction.
 			if err := profileService.UpdateTransaction(t); err != nil {
@@ -55,8 +85,11 @@ func UpdateTransaction(profileService service.Profile) *cobra.Command {
 	cmd.Flags().String("profile", "", "Profile to interact with")
 	cmd.Flags().String("id", "", "Transaction ID")
 	cmd.Flags().String("label", "", "Transaction label")
-	cmd.Flags().Int64("amount", 0, "Transaction amount")
+	cmd.Flags().String("amount", "", "Transaction amount, such as -435.00")
+	cmd.Flags().String("currency", "", "Transaction currency")
+	cmd.Flags().String("account", "", "Name of the account the transaction belongs to")
 	cmd.Flags().StringArray("tags", nil, "Tags to group the transaction")
+	cmd.Flags().String("date", "", "Transaction date ("+domain.DateFormat+")")
 
 	_ = cmd.MarkFlagRequired("profile")
 	_ = cmd.MarkFlagRequired("id")
@@ -3,14 +3,24 @@ package errs
 import "fmt"
 
 const (
-	ErrUnknown        = "UnknownError"
-	ErrShutdownSignal = "ShutdownSignal"
+	ErrUnknown             = "UnknownError"
+	ErrShutdownSignal      = "ShutdownSignal"
+	ErrNotConfirmed        = "NotConfirmed"
+	ErrInvalidOutputFormat = "InvalidOutputFormat"
+	ErrInvalidCharset      = "InvalidCharset"
+	ErrInvalidArgument     = "InvalidArgument"
+
+	// Request errors
+
+	ErrInvalidRequestBody = "InvalidRequestBody"
 
 	// Profile errors
 
 	ErrUnknownProfile   = "UnknownProfile"
 	ErrInvalidProfileID = "InvalidProfileID"
 	ErrInvalidName      = "InvalidName"
+	ErrInvalidCurrency  = "InvalidCurrency"
+	ErrProfileExists    = "ProfileExists"
 
 	// Transaction errors
 
@@ -19,6 +29,54 @@ const (
 	ErrInvalidLabel         = "InvalidLabel"
 	ErrInvalidAmount        = "InvalidAmount"
 	ErrInvalidTag           = "InvalidTag"
+	ErrInvalidDate          = "InvalidDate"
+	ErrInvalidTagSplit      = "InvalidTagSplit"
+	ErrInvalidFilter        = "InvalidFilter"
+	ErrInvalidQuery         = "InvalidQuery"
+
+	// Schedule errors
+
+	ErrUnknownSchedule   = "UnknownSchedule"
+	ErrInvalidScheduleID = "InvalidScheduleID"
+	ErrInvalidFrequency  = "InvalidFrequency"
+	ErrInvalidInterval   = "InvalidInterval"
+	ErrInvalidDayOfMonth = "InvalidDayOfMonth"
+
+	// Account errors
+
+	ErrUnknownAccount   = "UnknownAccount"
+	ErrInvalidAccountID = "InvalidAccountID"
+	ErrAccountExists    = "AccountExists"
+	ErrAccountClosed    = "AccountClosed"
+	ErrInvalidTransfer  = "InvalidTransfer"
+
+	// Tag errors
+
+	ErrUnknownTag     = "UnknownTag"
+	ErrTagExists      = "TagExists"
+	ErrInvalidTagCase = "InvalidTagCase"
+
+	// Tag rule errors
+
+	ErrUnknownTagRule   = "UnknownTagRule"
+	ErrInvalidTagRuleID = "InvalidTagRuleID"
+	ErrInvalidTagRule   = "InvalidTagRule"
+
+	// Exchange rate errors
+
+	ErrInvalidExchangeRate = "InvalidExchangeRate"
+	ErrMissingExchangeRate = "MissingExchangeRate"
+
+	// Budget errors
+
+	ErrInvalidPeriod = "InvalidPeriod"
+
+	// Envelope errors
+
+	ErrUnknownEnvelope   = "UnknownEnvelope"
+	ErrInvalidEnvelopeID = "InvalidEnvelopeID"
+	ErrInvalidEnvelope   = "InvalidEnvelope"
+	ErrEnvelopeExists    = "EnvelopeExists"
 )
 
 // FromErr converts an error to an Error.
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"encoding/csv"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"strings"
+)
+
+// NewCSVWriter returns a Writer that writes transactions as CSV with a header row.
+// Tags are separated by a semicolon.
+func NewCSVWriter() Writer {
+	return &csvWriter{}
+}
+
+// csvWriter implements Writer
+type csvWriter struct {
+}
+
+// Write writes the given transactions belonging to the given profile to w.
+func (x *csvWriter) Write(w io.Writer, profile *domain.Profile, transactions *domain.TransactionCollection) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "date", "label", "amount", "currency", "tags"}); err != nil {
+		return err
+	}
+	err := transactions.Range(nil, func(t *domain.Transaction) error {
+		return cw.Write([]string{
+			t.ID,
+			t.Date.Format(domain.DateFormat),
+			t.Label,
+			t.Money().Decimal(),
+			string(t.Currency),
+			strings.Join(t.Tags, ";"),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
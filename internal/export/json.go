@@ -0,0 +1,61 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+)
+
+// NewJSONWriter returns a Writer that writes the profile and its transactions as indented JSON.
+// Amounts are written in the minor unit of their currency.
+func NewJSONWriter() Writer {
+	return &jsonWriter{}
+}
+
+// jsonWriter implements Writer
+type jsonWriter struct {
+}
+
+type jsonProfile struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Currency     string            `json:"currency"`
+	Transactions []jsonTransaction `json:"transactions"`
+}
+
+type jsonTransaction struct {
+	ID       string   `json:"id"`
+	Date     string   `json:"date"`
+	Label    string   `json:"label"`
+	Amount   int64    `json:"amount"`
+	Currency string   `json:"currency"`
+	Tags     []string `json:"tags"`
+}
+
+// Write writes the given transactions belonging to the given profile to w.
+func (x *jsonWriter) Write(w io.Writer, profile *domain.Profile, transactions *domain.TransactionCollection) error {
+	res := jsonProfile{
+		ID:           profile.ID,
+		Name:         profile.Name,
+		Currency:     string(profile.Currency),
+		Transactions: make([]jsonTransaction, 0),
+	}
+	for _, t := range transactions.All() {
+		tags := t.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		res.Transactions = append(res.Transactions, jsonTransaction{
+			ID:       t.ID,
+			Date:     t.Date.Format(domain.DateFormat),
+			Label:    t.Label,
+			Amount:   t.Amount,
+			Currency: string(t.Currency),
+			Tags:     tags,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"strings"
+)
+
+// NewLedgerWriter returns a Writer that writes transactions as a plain text ledger journal.
+// Each transaction is posted against an Expenses or Income account named after its first tag,
+// balanced against an Assets account named after the profile.
+func NewLedgerWriter() Writer {
+	return &ledgerWriter{}
+}
+
+// ledgerWriter implements Writer
+type ledgerWriter struct {
+}
+
+// Write writes the given transactions belonging to the given profile to w.
+func (x *ledgerWriter) Write(w io.Writer, profile *domain.Profile, transactions *domain.TransactionCollection) error {
+	asset := "Assets:" + ledgerAccountName(profile.Name)
+
+	first := true
+	return transactions.Range(nil, func(t *domain.Transaction) error {
+		if !first {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		category := "Uncategorised"
+		if len(t.Tags) > 0 {
+			category = ledgerAccountName(t.Tags[0])
+		}
+		account := "Expenses:" + category
+		if t.Amount > 0 {
+			account = "Income:" + category
+		}
+
+		lines := []string{
+			fmt.Sprintf("%s %s", t.Date.Format("2006/01/02"), t.Label),
+			fmt.Sprintf("    ; id: %s", t.ID),
+		}
+		if len(t.Tags) > 0 {
+			lines = append(lines, fmt.Sprintf("    ; :%s:", strings.Join(t.Tags, ":")))
+		}
+		lines = append(lines,
+			fmt.Sprintf("    %-40s  %s", account, domain.NewMoney(-t.Amount, t.Currency)),
+			fmt.Sprintf("    %s", asset),
+		)
+
+		_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+		return err
+	})
+}
+
+// ledgerAccountName returns the given name with any characters that ledger treats specially removed.
+func ledgerAccountName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.NewReplacer(":", "-", ";", "-", "  ", " ", "\t", " ").Replace(name)
+	if name == "" {
+		return "Unknown"
+	}
+	return name
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Writer writes the transactions of a profile in a single format.
+type Writer interface {
+	// Write writes the given transactions belonging to the given profile to w.
+	Write(w io.Writer, profile *domain.Profile, transactions *domain.TransactionCollection) error
+}
+
+// writers contains a constructor for each supported format.
+var writers = map[string]func() Writer{
+	"csv":    NewCSVWriter,
+	"json":   NewJSONWriter,
+	"ledger": NewLedgerWriter,
+}
+
+// Formats returns the names of all supported formats.
+func Formats() []string {
+	res := make([]string, 0, len(writers))
+	for f := range writers {
+		res = append(res, f)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// NewWriter returns a Writer for the given format.
+func NewWriter(format string) (Writer, error) {
+	fn, ok := writers[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format `%s`: expected one of %s", format, strings.Join(Formats(), ", "))
+	}
+	return fn(), nil
+}
@@ -0,0 +1,144 @@
+package export_test
+
+import (
+	"bytes"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/export"
+	"testing"
+	"time"
+)
+
+func testData() (*domain.Profile, *domain.TransactionCollection) {
+	profile := domain.NewProfile()
+	profile.ID = "pro:1"
+	profile.Name = "tom"
+	profile.Currency = "GBP"
+
+	c := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().
+			WithID("tra:1").
+			WithLabel("Train ticket").
+			WithAmount(-43505).
+			WithCurrency("GBP").
+			WithTags("commute", "travel").
+			WithDate(time.Date(2019, 3, 14, 0, 0, 0, 0, time.UTC)),
+		domain.NewTransaction().
+			WithID("tra:2").
+			WithLabel("Salary, March").
+			WithAmount(200000).
+			WithCurrency("GBP").
+			WithDate(time.Date(2019, 3, 29, 0, 0, 0, 0, time.UTC)),
+		domain.NewTransaction().
+			WithID("tra:3").
+			WithLabel("Ramen").
+			WithAmount(-1200).
+			WithCurrency("JPY").
+			WithTags("food").
+			WithDate(time.Date(2019, 3, 30, 0, 0, 0, 0, time.UTC)),
+	)
+	return profile, c
+}
+
+func TestWriters(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		exp    string
+	}{
+		{
+			format: "csv",
+			exp: `id,date,label,amount,currency,tags
+tra:1,2019-03-14,Train ticket,-435.05,GBP,commute;travel
+tra:2,2019-03-29,"Salary, March",2000.00,GBP,
+tra:3,2019-03-30,Ramen,-1200,JPY,food
+`,
+		},
+		{
+			format: "json",
+			exp: `{
+  "id": "pro:1",
+  "name": "tom",
+  "currency": "GBP",
+  "transactions": [
+    {
+      "id": "tra:1",
+      "date": "2019-03-14",
+      "label": "Train ticket",
+      "amount": -43505,
+      "currency": "GBP",
+      "tags": [
+        "commute",
+        "travel"
+      ]
+    },
+    {
+      "id": "tra:2",
+      "date": "2019-03-29",
+      "label": "Salary, March",
+      "amount": 200000,
+      "currency": "GBP",
+      "tags": []
+    },
+    {
+      "id": "tra:3",
+      "date": "2019-03-30",
+      "label": "Ramen",
+      "amount": -1200,
+      "currency": "JPY",
+      "tags": [
+        "food"
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			format: "ledger",
+			exp: `2019/03/14 Train ticket
+    ; id: tra:1
+    ; :commute:travel:
+    Expenses:commute                          £435.05
+    Assets:tom
+
+2019/03/29 Salary, March
+    ; id: tra:2
+    Income:Uncategorised                      -£2000.00
+    Assets:tom
+
+2019/03/30 Ramen
+    ; id: tra:3
+    ; :food:
+    Expenses:food                             ¥1200
+    Assets:tom
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.format, func(t *testing.T) {
+			w, err := export.NewWriter(tc.format)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			profile, transactions := testData()
+			buf := &bytes.Buffer{}
+			if err := w.Write(buf, profile, transactions); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := buf.String(); got != tc.exp {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	if _, err := export.NewWriter("xml"); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,191 @@
+// Package htmlreport writes a summary of the transactions in a profile as a self-contained HTML page.
+// Charts are drawn as inline SVG and styles are inlined, so the page can be shared as a single file
+// with people who do not use the command line.
+package htmlreport
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"strings"
+	"time"
+)
+
+// monthFormat is the format used to show a calendar month.
+const monthFormat = "2006-01"
+
+// Report contains everything shown in an HTML report.
+// All amounts are in Currency.
+type Report struct {
+	// Profile is the profile the report is about.
+	Profile *domain.Profile
+	// Currency is the currency of all amounts in the report.
+	Currency domain.Currency
+	// Period is the date range covered by the report.
+	Period domain.DateRange
+	// Generated is the time the report was generated.
+	Generated time.Time
+	// Tags is the breakdown of the transactions in the period by tag.
+	Tags *domain.TagBreakdown
+	// Monthly is the summary of each month in the period.
+	Monthly *domain.MonthlySummary
+	// Top contains the largest outgoing transactions in the period, largest first.
+	Top []*domain.Transaction
+}
+
+// NewReport returns a Report on the given transactions over the given period, which must have both a start
+// and an end. The transactions must all be in the given currency, and may start before the period so the
+// monthly summary has a running balance and comparisons.
+// Tags are rolled up to the given depth, and the given number of the largest outgoing transactions are included.
+func NewReport(profile *domain.Profile, transactions *domain.TransactionCollection, period domain.DateRange,
+	currency domain.Currency, depth int, top int) *Report {
+	inPeriod := transactions.Between(period)
+	return &Report{
+		Profile:   profile,
+		Currency:  currency,
+		Period:    period,
+		Generated: time.Now(),
+		Tags:      inPeriod.GroupByTagDepth(domain.TagSplitEach, depth),
+		Monthly:   transactions.MonthlySummary(period),
+		Top: inPeriod.ExcludeTransfers().Filter(domain.TransactionFilter{
+			Direction: domain.DirectionOut,
+			Sort:      domain.TransactionSortAmount,
+			Limit:     top,
+		}).All(),
+	}
+}
+
+// Render writes the given report to w as an HTML page.
+func Render(w io.Writer, report *Report) error {
+	return reportTemplate.Execute(w, newView(report))
+}
+
+// view is the data given to the report template, with all values formatted for display.
+type view struct {
+	Title     string
+	Period    string
+	Generated string
+	Currency  string
+	Incoming  string
+	Outgoing  string
+	Net       string
+	Saved     string
+	Closing   string
+
+	TagChart *svgChart
+	Tags     []tagRow
+	Untagged *tagRow
+
+	MonthChart   *svgChart
+	BalanceChart *svgChart
+	Months       []monthRow
+
+	Top []transactionRow
+}
+
+type tagRow struct {
+	Tag             string
+	Count           int
+	Incoming        string
+	Outgoing        string
+	OutgoingPercent string
+	Net             string
+}
+
+type monthRow struct {
+	Month     string
+	Incoming  string
+	Outgoing  string
+	Net       string
+	Saved     string
+	Balance   string
+	LastMonth string
+	LastYear  string
+}
+
+type transactionRow struct {
+	Date   string
+	Label  string
+	Tags   string
+	Amount string
+}
+
+// newView formats the given report for the report template.
+func newView(report *Report) *view {
+	money := func(amount int64) string {
+		return domain.NewMoney(amount, report.Currency).String()
+	}
+	percent := func(percent float64) string {
+		return fmt.Sprintf("%.1f%%", percent)
+	}
+
+	v := &view{
+		Title:     fmt.Sprintf("Finance report for %s", report.Profile.Name),
+		Period:    fmt.Sprintf("%s to %s", report.Period.From.Format(domain.DateFormat), report.Period.To.Format(domain.DateFormat)),
+		Generated: report.Generated.Format("2006-01-02 15:04"),
+		Currency:  string(report.Currency),
+		Incoming:  money(report.Monthly.Incoming),
+		Outgoing:  money(report.Monthly.Outgoing),
+		Net:       money(report.Monthly.Net()),
+		Saved:     percent(report.Monthly.SavingsRate()),
+		Closing:   money(report.Monthly.Closing()),
+	}
+
+	tagRowFor := func(tag string, total *domain.TagTotal) tagRow {
+		return tagRow{
+			Tag:             tag,
+			Count:           total.Count,
+			Incoming:        money(total.Incoming),
+			Outgoing:        money(total.Outgoing),
+			OutgoingPercent: percent(total.OutgoingPercent),
+			Net:             money(total.Net()),
+		}
+	}
+	for _, total := range report.Tags.Tags {
+		v.Tags = append(v.Tags, tagRowFor(total.Tag, total))
+	}
+	if report.Tags.Untagged.Count > 0 {
+		row := tagRowFor("(untagged)", report.Tags.Untagged)
+		v.Untagged = &row
+	}
+	v.TagChart = tagChart(report.Tags, money)
+
+	compare := func(month *domain.MonthTotal, with *domain.MonthTotal) string {
+		if with == nil {
+			return "-"
+		}
+		change := month.Net() - with.Net()
+		if change > 0 {
+			return "+" + money(change)
+		}
+		return money(change)
+	}
+	for _, m := range report.Monthly.Months {
+		month := m.Month.Format(monthFormat)
+		if m.Partial() {
+			month += fmt.Sprintf(" (to %s)", m.LastDay.Format(domain.DateFormat))
+		}
+		v.Months = append(v.Months, monthRow{
+			Month:     month,
+			Incoming:  money(m.Incoming),
+			Outgoing:  money(m.Outgoing),
+			Net:       money(m.Net()),
+			Saved:     percent(m.SavingsRate()),
+			Balance:   money(m.Balance),
+			LastMonth: compare(m, m.PreviousMonth),
+			LastYear:  compare(m, m.LastYear),
+		})
+	}
+	v.MonthChart = monthChart(report.Monthly, money)
+	v.BalanceChart = balanceChart(report.Monthly, money)
+
+	for _, t := range report.Top {
+		v.Top = append(v.Top, transactionRow{
+			Date:   t.Date.Format(domain.DateFormat),
+			Label:  t.Label,
+			Tags:   strings.Join(t.Tags, ", "),
+			Amount: money(t.Amount),
+		})
+	}
+	return v
+}
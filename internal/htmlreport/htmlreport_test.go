@@ -0,0 +1,67 @@
+package htmlreport_test
+
+import (
+	"bytes"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/htmlreport"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRender(t *testing.T) {
+	profile := domain.NewProfile()
+	profile.Name = "tom"
+	profile.Currency = "GBP"
+
+	c := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithLabel("Salary").WithAmount(200000).WithDate(date(2019, 2, 28)),
+		domain.NewTransaction().WithLabel("Rent").WithAmount(-80000).WithTags("home").WithDate(date(2019, 3, 1)),
+		domain.NewTransaction().WithLabel("<b>Fish</b> & chips").WithAmount(-1250).WithTags("food").WithDate(date(2019, 3, 2)),
+		domain.NewTransaction().WithLabel("Coffee").WithAmount(-300).WithTags("food").WithDate(date(2019, 3, 3)),
+		domain.NewTransaction().WithLabel("Salary").WithAmount(200000).WithDate(date(2019, 3, 29)),
+		// Before the period, so only counted in the balance.
+		domain.NewTransaction().WithLabel("Deposit").WithAmount(-100000).WithTags("home").WithDate(date(2019, 1, 10)),
+	)
+
+	report := htmlreport.NewReport(profile, c, domain.DateRange{From: date(2019, 2, 1), To: date(2019, 3, 31)}, "GBP", 0, 2)
+
+	if len(report.Top) != 2 || report.Top[0].Label != "Rent" || report.Top[1].Label != "<b>Fish</b> & chips" {
+		t.Errorf("expected the 2 largest outgoing transactions, got %v", report.Top)
+	}
+	if len(report.Tags.Tags) != 2 || report.Tags.Tags[0].Tag != "home" || report.Tags.Tags[0].Outgoing != -80000 {
+		t.Errorf("expected tags to only include transactions in the period, got %v", report.Tags.Tags)
+	}
+	if len(report.Monthly.Months) != 2 || report.Monthly.Opening != -100000 {
+		t.Errorf("unexpected monthly summary: %+v", report.Monthly)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := htmlreport.Render(buf, report); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := buf.String()
+
+	for _, exp := range []string{
+		"<title>Finance report for tom</title>",
+		"2019-02-01 to 2019-03-31",
+		"<td>2019-03</td>",
+		"£3184.50",
+		"&lt;b&gt;Fish&lt;/b&gt; &amp; chips",
+		"<polyline points=",
+		`class="out"><title>home: £800.00</title>`,
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("expected report to contain %q", exp)
+		}
+	}
+	for _, unexpected := range []string{"<b>Fish", "src=", "href=", "<link", "<script"} {
+		if strings.Contains(got, unexpected) {
+			t.Errorf("expected report not to contain %q", unexpected)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package htmlreport
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"math"
+	"strings"
+)
+
+const (
+	// chartWidth is the width of every chart, in SVG user units.
+	chartWidth = 680
+	// maxTagBars is the most tags shown in the tag chart. Every tag is still listed in the table.
+	maxTagBars = 10
+	// maxMonthLabels is the most month labels shown along the bottom of the monthly charts.
+	maxMonthLabels = 12
+)
+
+// svgChart is the geometry of a chart, ready to be drawn by the report template.
+type svgChart struct {
+	Width  float64
+	Height float64
+	Rects  []svgRect
+	Lines  []svgLine
+	Texts  []svgText
+	// Points is the points attribute of a polyline, or empty if the chart has no line.
+	Points string
+	// Dots are drawn over the points of the line to show their values.
+	Dots []svgDot
+}
+
+type svgRect struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Class  string
+	Title  string
+}
+
+type svgLine struct {
+	X1    float64
+	Y1    float64
+	X2    float64
+	Y2    float64
+	Class string
+}
+
+type svgText struct {
+	X      float64
+	Y      float64
+	Anchor string
+	Text   string
+}
+
+type svgDot struct {
+	X     float64
+	Y     float64
+	Title string
+}
+
+// round rounds v to 1 decimal place to keep the SVG small.
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// tagChart returns a horizontal bar chart of the spending in the tags that were spent the most.
+func tagChart(breakdown *domain.TagBreakdown, money func(int64) string) *svgChart {
+	const (
+		labelWidth = 160
+		barArea    = 390
+		rowHeight  = 26
+		barHeight  = 18
+	)
+
+	totals := make([]*domain.TagTotal, 0, maxTagBars)
+	for _, total := range breakdown.Tags {
+		if total.Outgoing == 0 || len(totals) == maxTagBars {
+			break
+		}
+		totals = append(totals, total)
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+
+	max := float64(-totals[0].Outgoing)
+	c := &svgChart{Width: chartWidth, Height: float64(len(totals)*rowHeight + 8)}
+	for i, total := range totals {
+		y := float64(4 + i*rowHeight)
+		width := math.Max(1, float64(-total.Outgoing)/max*barArea)
+		c.Rects = append(c.Rects, svgRect{
+			X: labelWidth, Y: y, Width: round(width), Height: barHeight, Class: "out",
+			Title: fmt.Sprintf("%s: %s", total.Tag, money(-total.Outgoing)),
+		})
+		c.Texts = append(c.Texts,
+			svgText{X: labelWidth - 8, Y: y + 13, Anchor: "end", Text: total.Tag},
+			svgText{X: round(labelWidth + width + 6), Y: y + 13, Anchor: "start", Text: money(-total.Outgoing)},
+		)
+	}
+	return c
+}
+
+// monthFrame contains the layout shared by the monthly charts.
+type monthFrame struct {
+	left   float64
+	top    float64
+	width  float64
+	height float64
+	group  float64
+}
+
+// newMonthFrame returns the layout for a chart of the given number of months, adding the axes and month labels
+// to the given chart.
+func newMonthFrame(c *svgChart, months []*domain.MonthTotal) monthFrame {
+	f := monthFrame{left: 90, top: 10, height: c.Height - 40}
+	f.width = c.Width - f.left - 10
+	f.group = f.width / float64(len(months))
+
+	bottom := f.top + f.height
+	c.Lines = append(c.Lines,
+		svgLine{X1: f.left, Y1: f.top, X2: f.left, Y2: bottom, Class: "axis"},
+		svgLine{X1: f.left, Y1: bottom, X2: f.left + f.width, Y2: bottom, Class: "axis"},
+	)
+	every := (len(months) + maxMonthLabels - 1) / maxMonthLabels
+	for i, m := range months {
+		if i%every != 0 {
+			continue
+		}
+		c.Texts = append(c.Texts, svgText{
+			X: round(f.left + (float64(i)+0.5)*f.group), Y: bottom + 18, Anchor: "middle",
+			Text: m.Month.Format(monthFormat),
+		})
+	}
+	return f
+}
+
+// monthChart returns a column chart of the incoming and outgoing totals of each month.
+func monthChart(summary *domain.MonthlySummary, money func(int64) string) *svgChart {
+	if len(summary.Months) == 0 {
+		return nil
+	}
+	c := &svgChart{Width: chartWidth, Height: 260}
+	f := newMonthFrame(c, summary.Months)
+
+	var max int64
+	for _, m := range summary.Months {
+		if m.Incoming > max {
+			max = m.Incoming
+		}
+		if -m.Outgoing > max {
+			max = -m.Outgoing
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	bottom := f.top + f.height
+	barWidth := f.group * 0.35
+	bar := func(i int, offset float64, amount int64, class string, label string) svgRect {
+		height := float64(amount) / float64(max) * f.height
+		return svgRect{
+			X: round(f.left + float64(i)*f.group + offset), Y: round(bottom - height),
+			Width: round(barWidth), Height: round(height), Class: class,
+			Title: fmt.Sprintf("%s %s: %s", summary.Months[i].Month.Format(monthFormat), label, money(amount)),
+		}
+	}
+	for i, m := range summary.Months {
+		c.Rects = append(c.Rects,
+			bar(i, f.group*0.12, m.Incoming, "in", "incoming"),
+			bar(i, f.group*0.53, -m.Outgoing, "out", "outgoing"),
+		)
+	}
+	c.Texts = append(c.Texts,
+		svgText{X: f.left - 6, Y: f.top + 4, Anchor: "end", Text: money(max)},
+		svgText{X: f.left - 6, Y: bottom, Anchor: "end", Text: money(0)},
+	)
+	return c
+}
+
+// balanceChart returns a line chart of the running balance at the end of each month.
+func balanceChart(summary *domain.MonthlySummary, money func(int64) string) *svgChart {
+	if len(summary.Months) == 0 {
+		return nil
+	}
+	c := &svgChart{Width: chartWidth, Height: 220}
+	f := newMonthFrame(c, summary.Months)
+
+	min, max := summary.Months[0].Balance, summary.Months[0].Balance
+	for _, m := range summary.Months {
+		if m.Balance < min {
+			min = m.Balance
+		}
+		if m.Balance > max {
+			max = m.Balance
+		}
+	}
+	y := func(balance int64) float64 {
+		if max == min {
+			return round(f.top + f.height/2)
+		}
+		return round(f.top + float64(max-balance)/float64(max-min)*f.height)
+	}
+
+	if min < 0 && max > 0 {
+		c.Lines = append(c.Lines, svgLine{X1: f.left, Y1: y(0), X2: f.left + f.width, Y2: y(0), Class: "zero"})
+	}
+	points := make([]string, len(summary.Months))
+	for i, m := range summary.Months {
+		x := round(f.left + (float64(i)+0.5)*f.group)
+		points[i] = fmt.Sprintf("%g,%g", x, y(m.Balance))
+		c.Dots = append(c.Dots, svgDot{
+			X: x, Y: y(m.Balance),
+			Title: fmt.Sprintf("%s: %s", m.Month.Format(monthFormat), money(m.Balance)),
+		})
+	}
+	c.Points = strings.Join(points, " ")
+	c.Texts = append(c.Texts,
+		svgText{X: f.left - 6, Y: y(max) + 4, Anchor: "end", Text: money(max)},
+	)
+	if min != max {
+		c.Texts = append(c.Texts, svgText{X: f.left - 6, Y: y(min) + 4, Anchor: "end", Text: money(min)})
+	}
+	return c
+}
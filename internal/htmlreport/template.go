@@ -0,0 +1,83 @@
+package htmlreport
+
+import (
+	"html/template"
+)
+
+// reportTemplate renders a view as a self-contained HTML page.
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #222; margin: 2em auto; max-width: 760px; padding: 0 1em; }
+h1 { margin-bottom: 0; }
+h2 { margin-top: 2em; border-bottom: 1px solid #ddd; padding-bottom: 0.2em; }
+.meta { color: #666; margin-top: 0.3em; }
+.summary { display: flex; flex-wrap: wrap; gap: 1em; margin-top: 1.5em; }
+.summary div { background: #f5f5f5; border-radius: 4px; padding: 0.6em 1em; }
+.summary span { display: block; color: #666; font-size: 0.85em; }
+table { border-collapse: collapse; width: 100%; margin-top: 1em; font-size: 0.9em; }
+th, td { padding: 0.35em 0.6em; border-bottom: 1px solid #eee; text-align: left; }
+th { background: #f5f5f5; }
+td.amount, th.amount { text-align: right; white-space: nowrap; }
+svg { width: 100%; height: auto; }
+svg text { font-size: 11px; fill: #444; }
+svg .in { fill: #3a9e5c; }
+svg .out { fill: #d9534f; }
+svg .axis { stroke: #999; }
+svg .zero { stroke: #bbb; stroke-dasharray: 4 3; }
+svg polyline { fill: none; stroke: #337ab7; stroke-width: 2; }
+svg circle { fill: #337ab7; }
+.legend .in, .legend .out { display: inline-block; width: 0.8em; height: 0.8em; margin: 0 0.3em 0 1em; }
+.legend .in { background: #3a9e5c; }
+.legend .out { background: #d9534f; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Period}}, amounts in {{.Currency}}. Generated {{.Generated}}.</p>
+
+<div class="summary">
+<div><span>Incoming</span>{{.Incoming}}</div>
+<div><span>Outgoing</span>{{.Outgoing}}</div>
+<div><span>Net</span>{{.Net}}</div>
+<div><span>Saved</span>{{.Saved}}</div>
+<div><span>Closing balance</span>{{.Closing}}</div>
+</div>
+
+<h2>Monthly summary</h2>
+{{with .MonthChart}}<p class="legend"><span class="in"></span>Incoming<span class="out"></span>Outgoing</p>
+{{template "chart" .}}{{end}}
+{{with .BalanceChart}}<h3>Balance</h3>
+{{template "chart" .}}{{end}}
+<table>
+<tr><th>Month</th><th class="amount">Incoming</th><th class="amount">Outgoing</th><th class="amount">Net</th><th class="amount">Saved</th><th class="amount">Balance</th><th class="amount">vs Last month</th><th class="amount">vs Last year</th></tr>
+{{range .Months}}<tr><td>{{.Month}}</td><td class="amount">{{.Incoming}}</td><td class="amount">{{.Outgoing}}</td><td class="amount">{{.Net}}</td><td class="amount">{{.Saved}}</td><td class="amount">{{.Balance}}</td><td class="amount">{{.LastMonth}}</td><td class="amount">{{.LastYear}}</td></tr>
+{{end}}</table>
+
+<h2>Spending by tag</h2>
+{{with .TagChart}}{{template "chart" .}}{{else}}<p>Nothing was spent in this period.</p>{{end}}
+{{if or .Tags .Untagged}}<table>
+<tr><th>Tag</th><th class="amount">Count</th><th class="amount">Incoming</th><th class="amount">Outgoing</th><th class="amount">% Out</th><th class="amount">Net</th></tr>
+{{range .Tags}}{{template "tag" .}}{{end}}{{with .Untagged}}{{template "tag" .}}{{end}}</table>{{end}}
+
+<h2>Largest transactions</h2>
+{{if .Top}}<table>
+<tr><th>Date</th><th>Label</th><th>Tags</th><th class="amount">Amount</th></tr>
+{{range .Top}}<tr><td>{{.Date}}</td><td>{{.Label}}</td><td>{{.Tags}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}</table>{{else}}<p>Nothing was spent in this period.</p>{{end}}
+</body>
+</html>
+{{define "tag"}}<tr><td>{{.Tag}}</td><td class="amount">{{.Count}}</td><td class="amount">{{.Incoming}}</td><td class="amount">{{.Outgoing}}</td><td class="amount">{{.OutgoingPercent}}</td><td class="amount">{{.Net}}</td></tr>
+{{end}}
+{{define "chart"}}<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 {{.Width}} {{.Height}}" role="img">
+{{range .Lines}}<line x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}" class="{{.Class}}"/>
+{{end}}{{range .Rects}}<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" class="{{.Class}}"><title>{{.Title}}</title></rect>
+{{end}}{{if .Points}}<polyline points="{{.Points}}"/>
+{{end}}{{range .Dots}}<circle cx="{{.X}}" cy="{{.Y}}" r="3"><title>{{.Title}}</title></circle>
+{{end}}{{range .Texts}}<text x="{{.X}}" y="{{.Y}}" text-anchor="{{.Anchor}}">{{.Text}}</text>
+{{end}}</svg>
+{{end}}`))
@@ -12,6 +12,17 @@ type Handler interface {
 	Bind(r chi.Router)
 }
 
+// decodeBody decodes the JSON request body into v.
+func decodeBody(r *http.Request, v interface{}) errs.Error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errs.New().
+			WithCode(errs.ErrInvalidRequestBody).
+			WithStatusCode(http.StatusBadRequest).
+			WithMessage("could not decode request body: " + err.Error())
+	}
+	return nil
+}
+
 func sendError(err error, rw http.ResponseWriter) {
 	e := errs.FromErr(err)
 
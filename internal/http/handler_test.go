@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/application/validate"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testRouter returns a router serving every handler from a migrated database in a temporary directory,
+// and a func to remove it.
+func testRouter(t *testing.T) (http.Handler, func()) {
+	dir, err := ioutil.TempDir("", "finance-planner")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	db, err := repository.ConnectSQLite(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatalf("could not connect: %s", err)
+	}
+	cleanup := func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	}
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		cleanup()
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	profileRepo := repository.NewSQLiteProfile(db)
+	transactionRepo := repository.NewSQLiteTransaction(db)
+	validator := validate.NewValidator(profileRepo, transactionRepo)
+	profileService := service.NewProfileService(repository.NewSQLiteUnitOfWork(db), profileRepo, transactionRepo, validator)
+
+	r := chi.NewRouter()
+	for _, h := range loadHandlers(profileService) {
+		h.Bind(r)
+	}
+	return r, cleanup
+}
+
+// serve sends a request with the given JSON body to the router, and checks the response has the given status code.
+// If res is not nil the response body is decoded into it.
+func serve(t *testing.T, r http.Handler, method string, target string, body string, statusCode int, res interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+
+	if rw.Code != statusCode {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, target, statusCode, rw.Code, rw.Body.String())
+	}
+	if res != nil {
+		if err := json.Unmarshal(rw.Body.Bytes(), res); err != nil {
+			t.Fatalf("%s %s: could not decode response: %s", method, target, err)
+		}
+	}
+}
+
+// errorResponse is the JSON representation of an error, as sent by sendError.
+type errorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// serveError sends a request that is expected to fail with the given status and error code.
+func serveError(t *testing.T, r http.Handler, method string, target string, body string, statusCode int, code string) {
+	t.Helper()
+
+	res := errorResponse{}
+	serve(t, r, method, target, body, statusCode, &res)
+	if res.Code != code {
+		t.Errorf("%s %s: expected error code %s, got %s: %s", method, target, code, res.Code, res.Error)
+	}
+}
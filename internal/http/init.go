@@ -45,7 +45,8 @@ func Start(profileService service.Profile, listenAddress string, wg *sync.WaitGr
 		_ = server.Serve(listener)
 	}
 	stopFn := func() {
-		ctx, _ := context.WithTimeout(context.Background(), time.Second*10)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
 		fmt.Printf("http server shutting down\n")
 		err := server.Shutdown(ctx)
 		if err != nil {
@@ -70,5 +71,8 @@ func Start(profileService service.Profile, listenAddress string, wg *sync.WaitGr
 
 // loadHandlers returns all of the handlers to be served via HTTP.
 func loadHandlers(profileService service.Profile) []Handler {
-	return []Handler{}
+	return []Handler{
+		&profileHandler{profileService: profileService},
+		&transactionHandler{profileService: profileService},
+	}
 }
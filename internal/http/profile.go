@@ -0,0 +1,148 @@
+package http
+
+import (
+	"github.com/go-chi/chi"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"net/http"
+)
+
+// profileResponse is the JSON representation of a profile.
+type profileResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+}
+
+func newProfileResponse(profile *domain.Profile) profileResponse {
+	return profileResponse{
+		ID:       profile.ID,
+		Name:     profile.Name,
+		Currency: string(profile.Currency),
+	}
+}
+
+// profileRequest is the JSON request body used to create or update a profile.
+// Any nil fields are left unchanged when updating a profile.
+type profileRequest struct {
+	Name     *string `json:"name"`
+	Currency *string `json:"currency"`
+}
+
+// apply sets any given values on the given profile.
+func (x profileRequest) apply(profile *domain.Profile) {
+	if x.Name != nil {
+		profile.Name = *x.Name
+	}
+	if x.Currency != nil {
+		profile.Currency, _ = domain.ParseCurrency(*x.Currency)
+	}
+}
+
+// profileHandler serves profiles.
+type profileHandler struct {
+	profileService service.Profile
+}
+
+// Bind binds the profile routes to the given router.
+func (x *profileHandler) Bind(r chi.Router) {
+	r.Route("/profiles", func(r chi.Router) {
+		r.Post("/", x.create)
+		r.Get("/", x.getByName)
+		r.Get("/{profileID}", x.getByID)
+		r.Patch("/{profileID}", x.update)
+	})
+}
+
+// create creates a new profile.
+func (x *profileHandler) create(rw http.ResponseWriter, r *http.Request) {
+	body := profileRequest{}
+	if err := decodeBody(r, &body); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	profile := domain.NewProfile()
+	body.apply(profile)
+	if err := x.profileService.CreateProfile(profile); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newProfileResponse(profile), http.StatusCreated, rw)
+}
+
+// getByName gets the profile with the name given in the name query parameter.
+// All profiles are listed if the name query parameter is empty.
+func (x *profileHandler) getByName(rw http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		x.list(rw, r)
+		return
+	}
+
+	profile, err := x.profileService.LoadProfileByNameWithoutTransactions(name)
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newProfileResponse(profile), http.StatusOK, rw)
+}
+
+// profileListResponse is the JSON representation of a list of profiles.
+type profileListResponse struct {
+	Profiles []profileResponse `json:"profiles"`
+}
+
+// list lists all profiles.
+func (x *profileHandler) list(rw http.ResponseWriter, r *http.Request) {
+	profiles, err := x.profileService.ListProfiles()
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	res := profileListResponse{
+		Profiles: make([]profileResponse, 0, len(profiles)),
+	}
+	for _, p := range profiles {
+		res.Profiles = append(res.Profiles, newProfileResponse(p))
+	}
+
+	sendResponse(res, http.StatusOK, rw)
+}
+
+// getByID gets the profile with the given id.
+func (x *profileHandler) getByID(rw http.ResponseWriter, r *http.Request) {
+	profile, err := x.profileService.LoadProfileByIDWithoutTransactions(chi.URLParam(r, "profileID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newProfileResponse(profile), http.StatusOK, rw)
+}
+
+// update changes the name or currency of the profile with the given id.
+func (x *profileHandler) update(rw http.ResponseWriter, r *http.Request) {
+	body := profileRequest{}
+	if err := decodeBody(r, &body); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	profile, err := x.profileService.LoadProfileByIDWithoutTransactions(chi.URLParam(r, "profileID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	body.apply(profile)
+	if err := x.profileService.UpdateProfile(profile); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newProfileResponse(profile), http.StatusOK, rw)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"testing"
+)
+
+func TestProfileHandler(t *testing.T) {
+	r, cleanup := testRouter(t)
+	defer cleanup()
+
+	created := profileResponse{}
+	serve(t, r, http.MethodPost, "/profiles", `{"name": "tom", "currency": "gbp"}`, http.StatusCreated, &created)
+	if created.ID == "" || created.Name != "tom" || created.Currency != "GBP" {
+		t.Errorf("unexpected profile: %+v", created)
+	}
+
+	serveError(t, r, http.MethodPost, "/profiles", `{"name": `, http.StatusBadRequest, errs.ErrInvalidRequestBody)
+	serveError(t, r, http.MethodPost, "/profiles", `{"name": "tom"}`, http.StatusConflict, errs.ErrProfileExists)
+	serveError(t, r, http.MethodPost, "/profiles", `{"currency": "GBP"}`, http.StatusBadRequest, errs.ErrInvalidName)
+
+	got := profileResponse{}
+	serve(t, r, http.MethodGet, "/profiles?name=tom", "", http.StatusOK, &got)
+	if got != created {
+		t.Errorf("expected %+v, got %+v", created, got)
+	}
+	serveError(t, r, http.MethodGet, "/profiles?name=jess", "", http.StatusNotFound, errs.ErrUnknownProfile)
+
+	list := profileListResponse{}
+	serve(t, r, http.MethodGet, "/profiles", "", http.StatusOK, &list)
+	if len(list.Profiles) != 1 || list.Profiles[0] != created {
+		t.Errorf("expected only %+v, got %+v", created, list.Profiles)
+	}
+
+	updated := profileResponse{}
+	serve(t, r, http.MethodPatch, "/profiles/"+created.ID, `{"currency": "EUR"}`, http.StatusOK, &updated)
+	if updated.Name != "tom" || updated.Currency != "EUR" {
+		t.Errorf("expected only the currency to change, got %+v", updated)
+	}
+	serve(t, r, http.MethodGet, "/profiles/"+created.ID, "", http.StatusOK, &got)
+	if got != updated {
+		t.Errorf("expected %+v, got %+v", updated, got)
+	}
+	serveError(t, r, http.MethodPatch, "/profiles/pro:unknown", `{"currency": "EUR"}`, http.StatusNotFound, errs.ErrUnknownProfile)
+}
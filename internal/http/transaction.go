@@ -0,0 +1,262 @@
+package http
+
+import (
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/application/service"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"time"
+)
+
+// transactionResponse is the JSON representation of a transaction.
+type transactionResponse struct {
+	ID         string    `json:"id"`
+	ProfileID  string    `json:"profile_id"`
+	AccountID  string    `json:"account_id,omitempty"`
+	TransferID string    `json:"transfer_id,omitempty"`
+	Label      string    `json:"label"`
+	Amount     int64     `json:"amount"`
+	Currency   string    `json:"currency"`
+	Tags       []string  `json:"tags"`
+	Date       string    `json:"date"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newTransactionResponse(t *domain.Transaction) transactionResponse {
+	return transactionResponse{
+		ID:         t.ID,
+		ProfileID:  t.ProfileID,
+		AccountID:  t.AccountID,
+		TransferID: t.TransferID,
+		Label:      t.Label,
+		Amount:     t.Amount,
+		Currency:   string(t.Currency),
+		Tags:       t.Tags,
+		Date:       t.Date.Format(domain.DateFormat),
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}
+}
+
+// moneyResponse is the JSON representation of an amount in the minor unit of a currency.
+type moneyResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// transactionListResponse is the JSON representation of a collection of transactions.
+type transactionListResponse struct {
+	Transactions []transactionResponse `json:"transactions"`
+	// Totals contains the sum of the transactions in each currency.
+	Totals []moneyResponse `json:"totals"`
+}
+
+func newTransactionListResponse(c *domain.TransactionCollection) transactionListResponse {
+	res := transactionListResponse{
+		Transactions: make([]transactionResponse, 0),
+		Totals:       make([]moneyResponse, 0),
+	}
+	for _, t := range c.All() {
+		res.Transactions = append(res.Transactions, newTransactionResponse(t))
+	}
+	for _, total := range c.Totals() {
+		res.Totals = append(res.Totals, moneyResponse{Amount: total.Amount, Currency: string(total.Currency)})
+	}
+	return res
+}
+
+// transactionRequest is the JSON request body used to create or update a transaction.
+// Any nil fields are left unchanged when updating a transaction.
+type transactionRequest struct {
+	AccountID *string   `json:"account_id"`
+	Label     *string   `json:"label"`
+	Amount    *int64    `json:"amount"`
+	Currency  *string   `json:"currency"`
+	Tags      *[]string `json:"tags"`
+	Date      *string   `json:"date"`
+}
+
+// apply sets any given values on the given transaction.
+func (x transactionRequest) apply(t *domain.Transaction) errs.Error {
+	if x.AccountID != nil {
+		t.AccountID = *x.AccountID
+	}
+	if x.Label != nil {
+		t.Label = *x.Label
+	}
+	if x.Amount != nil {
+		t.Amount = *x.Amount
+	}
+	if x.Currency != nil {
+		t.Currency, _ = domain.ParseCurrency(*x.Currency)
+	}
+	if x.Tags != nil {
+		t.WithTags(*x.Tags...)
+	}
+	if x.Date != nil {
+		date, err := domain.ParseDate(*x.Date)
+		if err != nil {
+			return errs.New().
+				WithCode(errs.ErrInvalidDate).
+				WithStatusCode(http.StatusBadRequest).
+				WithMessage(fmt.Sprintf("invalid date `%s`: expected format %s", *x.Date, domain.DateFormat))
+		}
+		t.WithDate(date)
+	}
+	return nil
+}
+
+// queryDate parses the given query parameter as a date.
+// A zero time is returned if the parameter is empty.
+func queryDate(r *http.Request, param string) (time.Time, errs.Error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := domain.ParseDate(value)
+	if err != nil {
+		return time.Time{}, errs.New().
+			WithCode(errs.ErrInvalidDate).
+			WithStatusCode(http.StatusBadRequest).
+			WithMessage(fmt.Sprintf("invalid %s date `%s`: expected format %s", param, value, domain.DateFormat))
+	}
+	return date, nil
+}
+
+// transactionHandler serves transactions.
+type transactionHandler struct {
+	profileService service.Profile
+}
+
+// Bind binds the transaction routes to the given router.
+func (x *transactionHandler) Bind(r chi.Router) {
+	r.Route("/profiles/{profileID}/transactions", func(r chi.Router) {
+		r.Get("/", x.listByProfile)
+		r.Post("/", x.create)
+	})
+	r.Route("/transactions/{transactionID}", func(r chi.Router) {
+		r.Get("/", x.get)
+		r.Patch("/", x.update)
+		r.Delete("/", x.delete)
+	})
+}
+
+// listByProfile lists the transactions belonging to the given profile.
+// The results can be filtered with the from and to query parameters, and the q query parameter which
+// contains a search such as `tag:travel and amount < -5000`.
+func (x *transactionHandler) listByProfile(rw http.ResponseWriter, r *http.Request) {
+	from, err := queryDate(r, "from")
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+	to, err := queryDate(r, "to")
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+	filter := domain.TransactionFilter{
+		DateRange: domain.DateRange{From: from, To: to},
+	}
+	if q := r.URL.Query().Get("q"); q != "" {
+		query, parseErr := domain.ParseQuery(q)
+		if parseErr != nil {
+			sendError(errs.New().
+				WithCode(errs.ErrInvalidQuery).
+				WithStatusCode(http.StatusBadRequest).
+				WithMessage("invalid query: "+parseErr.Error()), rw)
+			return
+		}
+		filter.Query = query
+	}
+
+	profile, err := x.profileService.LoadProfileByIDWithoutTransactions(chi.URLParam(r, "profileID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+	transactions, err := x.profileService.LoadTransactions(profile.ID, filter.WithNormalisedTags(profile.TagCase))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newTransactionListResponse(transactions), http.StatusOK, rw)
+}
+
+// create creates a new transaction in the given profile.
+func (x *transactionHandler) create(rw http.ResponseWriter, r *http.Request) {
+	body := transactionRequest{}
+	if err := decodeBody(r, &body); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	profile, err := x.profileService.LoadProfileByIDWithoutTransactions(chi.URLParam(r, "profileID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	t := domain.NewTransaction().WithProfileID(profile.ID)
+	if err := body.apply(t); err != nil {
+		sendError(err, rw)
+		return
+	}
+	if err := x.profileService.CreateTransaction(t); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newTransactionResponse(t), http.StatusCreated, rw)
+}
+
+// get gets the given transaction.
+func (x *transactionHandler) get(rw http.ResponseWriter, r *http.Request) {
+	t, err := x.profileService.LoadTransactionByID(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newTransactionResponse(t), http.StatusOK, rw)
+}
+
+// update updates the given transaction with any values given in the request body.
+func (x *transactionHandler) update(rw http.ResponseWriter, r *http.Request) {
+	body := transactionRequest{}
+	if err := decodeBody(r, &body); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	t, err := x.profileService.LoadTransactionByID(chi.URLParam(r, "transactionID"))
+	if err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	if err := body.apply(t); err != nil {
+		sendError(err, rw)
+		return
+	}
+	if err := x.profileService.UpdateTransaction(t); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	sendResponse(newTransactionResponse(t), http.StatusOK, rw)
+}
+
+// delete deletes the given transaction.
+func (x *transactionHandler) delete(rw http.ResponseWriter, r *http.Request) {
+	if err := x.profileService.DeleteTransaction(chi.URLParam(r, "transactionID")); err != nil {
+		sendError(err, rw)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
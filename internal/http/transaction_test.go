@@ -0,0 +1,67 @@
+package http
+
+import (
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionHandler(t *testing.T) {
+	r, cleanup := testRouter(t)
+	defer cleanup()
+
+	profile := profileResponse{}
+	serve(t, r, http.MethodPost, "/profiles", `{"name": "tom", "currency": "GBP"}`, http.StatusCreated, &profile)
+	transactions := "/profiles/" + profile.ID + "/transactions"
+
+	rent := transactionResponse{}
+	serve(t, r, http.MethodPost, transactions, `{"label": "Rent", "amount": -80000, "tags": ["Home"], "date": "2019-03-01"}`,
+		http.StatusCreated, &rent)
+	if rent.ID == "" || rent.ProfileID != profile.ID || rent.Currency != "GBP" || rent.Date != "2019-03-01" ||
+		!reflect.DeepEqual([]string{"home"}, rent.Tags) {
+		t.Errorf("unexpected transaction: %+v", rent)
+	}
+	serve(t, r, http.MethodPost, transactions, `{"label": "Salary", "amount": 200000, "date": "2019-03-29"}`,
+		http.StatusCreated, nil)
+
+	serveError(t, r, http.MethodPost, transactions, `[]`, http.StatusBadRequest, errs.ErrInvalidRequestBody)
+	serveError(t, r, http.MethodPost, transactions, `{"label": "Rent", "amount": -1, "date": "01/03/2019"}`,
+		http.StatusBadRequest, errs.ErrInvalidDate)
+	serveError(t, r, http.MethodPost, transactions, `{"label": "Rent", "date": "2019-03-01"}`,
+		http.StatusBadRequest, errs.ErrInvalidAmount)
+	serveError(t, r, http.MethodPost, "/profiles/pro:unknown/transactions", `{"label": "Rent", "amount": -1}`,
+		http.StatusNotFound, errs.ErrUnknownProfile)
+
+	list := transactionListResponse{}
+	serve(t, r, http.MethodGet, transactions, "", http.StatusOK, &list)
+	if len(list.Transactions) != 2 || !reflect.DeepEqual([]moneyResponse{{Amount: 120000, Currency: "GBP"}}, list.Totals) {
+		t.Errorf("unexpected transactions: %+v", list)
+	}
+	serve(t, r, http.MethodGet, transactions+"?q="+url.QueryEscape(`tag = "HOME" and amount < 0`), "", http.StatusOK, &list)
+	if len(list.Transactions) != 1 || list.Transactions[0].ID != rent.ID {
+		t.Errorf("expected only the rent to match the query, got %+v", list.Transactions)
+	}
+	serve(t, r, http.MethodGet, transactions+"?from=2019-03-02&to=2019-03-31", "", http.StatusOK, &list)
+	if len(list.Transactions) != 1 || list.Transactions[0].Label != "Salary" {
+		t.Errorf("expected only the salary to be in range, got %+v", list.Transactions)
+	}
+	serveError(t, r, http.MethodGet, transactions+"?q="+url.QueryEscape(`tag < home`), "", http.StatusBadRequest, errs.ErrInvalidQuery)
+	serveError(t, r, http.MethodGet, transactions+"?from=2019-13-01", "", http.StatusBadRequest, errs.ErrInvalidDate)
+
+	updated := transactionResponse{}
+	serve(t, r, http.MethodPatch, "/transactions/"+rent.ID, `{"label": "March rent"}`, http.StatusOK, &updated)
+	if updated.Label != "March rent" || updated.Amount != rent.Amount || !reflect.DeepEqual(rent.Tags, updated.Tags) {
+		t.Errorf("expected only the label to change, got %+v", updated)
+	}
+	got := transactionResponse{}
+	serve(t, r, http.MethodGet, "/transactions/"+rent.ID, "", http.StatusOK, &got)
+	if got.Label != "March rent" {
+		t.Errorf("expected the updated label, got %+v", got)
+	}
+
+	serve(t, r, http.MethodDelete, "/transactions/"+rent.ID, "", http.StatusNoContent, nil)
+	serveError(t, r, http.MethodGet, "/transactions/"+rent.ID, "", http.StatusNotFound, errs.ErrUnknownTransaction)
+	serveError(t, r, http.MethodDelete, "/transactions/"+rent.ID, "", http.StatusNotFound, errs.ErrUnknownTransaction)
+}
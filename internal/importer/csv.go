@@ -0,0 +1,359 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Mapping describes how the columns in a CSV file map to the fields of a transaction.
+// Columns are referenced by their header name, or by their 1-based position if the
+// file has no header row.
+type Mapping struct {
+	// Date is the column containing the transaction date.
+	Date string `json:"date"`
+	// Label is the column containing the transaction label.
+	Label string `json:"label"`
+	// Amount is the column containing the signed transaction amount.
+	// Either Amount, or Debit and/or Credit must be given.
+	Amount string `json:"amount,omitempty"`
+	// Debit is the column containing money going out of the account.
+	Debit string `json:"debit,omitempty"`
+	// Credit is the column containing money coming in to the account.
+	Credit string `json:"credit,omitempty"`
+	// DateFormat is the format of the values in the Date column, such as DD/MM/YYYY.
+	DateFormat string `json:"date_format"`
+	// DecimalSeparator is the character between pounds and pence in amounts.
+	DecimalSeparator string `json:"decimal_separator"`
+	// Delimiter is the character between each column.
+	Delimiter string `json:"delimiter"`
+	// NoHeader is true if the first row of the file contains data rather than column names.
+	NoHeader bool `json:"no_header,omitempty"`
+	// Negate is true if amounts should be inverted, such as for credit card statements
+	// where spending is shown as a positive amount.
+	Negate bool `json:"negate,omitempty"`
+	// Currency is the currency of the amounts in the file.
+	// domain.DefaultCurrency is used if Currency is empty.
+	Currency domain.Currency `json:"currency,omitempty"`
+}
+
+// NewMapping returns a new Mapping with default values.
+func NewMapping() Mapping {
+	return Mapping{
+		DateFormat:       "YYYY-MM-DD",
+		DecimalSeparator: ".",
+		Delimiter:        ",",
+	}
+}
+
+// LoadMapping reads a Mapping from the JSON file at the given path.
+// Any values that are missing from the file keep their default value.
+func LoadMapping(path string) (Mapping, error) {
+	m := NewMapping()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("could not read mapping file: %s", err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("could not parse mapping file: %s", err)
+	}
+	return m, nil
+}
+
+// Save writes the Mapping to a JSON file at the given path.
+func (x Mapping) Save(path string) error {
+	data, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode mapping: %s", err)
+	}
+	if err := ioutil.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write mapping file: %s", err)
+	}
+	return nil
+}
+
+// Validate returns an error if the mapping cannot be used.
+func (x Mapping) Validate() error {
+	if x.Date == "" {
+		return fmt.Errorf("missing date column")
+	}
+	if x.Label == "" {
+		return fmt.Errorf("missing label column")
+	}
+	if x.Amount == "" && x.Debit == "" && x.Credit == "" {
+		return fmt.Errorf("missing amount, debit or credit column")
+	}
+	if x.Amount != "" && (x.Debit != "" || x.Credit != "") {
+		return fmt.Errorf("amount column cannot be used with debit or credit columns")
+	}
+	if x.DecimalSeparator != "." && x.DecimalSeparator != "," {
+		return fmt.Errorf("decimal separator must be `.` or `,`")
+	}
+	if utf8.RuneCountInString(x.Delimiter) != 1 {
+		return fmt.Errorf("delimiter must be a single character")
+	}
+	if x.DateFormat == "" {
+		return fmt.Errorf("missing date format")
+	}
+	if x.Currency != "" && !x.Currency.Valid() {
+		return fmt.Errorf("unknown currency `%s`", x.Currency)
+	}
+	return nil
+}
+
+// dateLayout converts a date format such as DD/MM/YYYY into a Go time layout.
+func dateLayout(format string) string {
+	return strings.NewReplacer(
+		"YYYY", "2006",
+		"YY", "06",
+		"MMM", "Jan",
+		"MM", "01",
+		"DD", "02",
+	).Replace(format)
+}
+
+// RowError describes a row in a CSV file that could not be imported.
+type RowError struct {
+	// Line is the row number in the file, counting the header row.
+	Line int
+	// Err is the reason the row could not be imported.
+	Err error
+}
+
+// Error implements error.
+func (x RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", x.Line, x.Err)
+}
+
+// Row is a row in a CSV file that has been parsed into a transaction.
+type Row struct {
+	// Line is the row number in the file, counting the header row.
+	Line int
+	// Transaction is the parsed transaction.
+	// Only the label, amount, currency and date are set.
+	Transaction *domain.Transaction
+}
+
+// Result contains the outcome of parsing a CSV file.
+type Result struct {
+	// Rows contains the rows that were parsed successfully.
+	Rows []Row
+	// Errors contains the rows that could not be parsed.
+	Errors []RowError
+}
+
+// columns maps the fields in a Mapping to column positions.
+type columns struct {
+	date, label, amount, debit, credit int
+}
+
+// ParseCSV parses the CSV file in r into transactions using the given mapping.
+// An error is only returned if the file as a whole cannot be read. Rows that cannot be
+// parsed are returned in the Result.
+func ParseCSV(r io.Reader, mapping Mapping) (*Result, error) {
+	if err := mapping.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mapping: %s", err)
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// line tracks the row number in the file, counting the header row.
+	line := 0
+
+	res := &Result{
+		Rows:   make([]Row, 0),
+		Errors: make([]RowError, 0),
+	}
+
+	var header []string
+	if !mapping.NoHeader {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read header row: %s", err)
+		}
+		header = record
+	}
+
+	cols := columns{}
+	var err error
+	for _, c := range []struct {
+		ref  string
+		dest *int
+	}{
+		{mapping.Date, &cols.date},
+		{mapping.Label, &cols.label},
+		{mapping.Amount, &cols.amount},
+		{mapping.Debit, &cols.debit},
+		{mapping.Credit, &cols.credit},
+	} {
+		if *c.dest, err = columnIndex(c.ref, header); err != nil {
+			return nil, err
+		}
+	}
+
+	layout := dateLayout(mapping.DateFormat)
+	currency := mapping.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	decimalSeparator, _ := utf8.DecodeRuneInString(mapping.DecimalSeparator)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				res.Errors = append(res.Errors, RowError{Line: line, Err: parseErr.Err})
+				continue
+			}
+			return nil, fmt.Errorf("could not read csv: %s", err)
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		t, err := parseRecord(record, cols, layout, decimalSeparator, currency, mapping.Negate)
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+		res.Rows = append(res.Rows, Row{Line: line, Transaction: t})
+	}
+
+	return res, nil
+}
+
+// columnIndex returns the 0-based position of the given column reference.
+// -1 is returned if ref is empty.
+func columnIndex(ref string, header []string) (int, error) {
+	if ref == "" {
+		return -1, nil
+	}
+	if header == nil {
+		i, err := strconv.Atoi(ref)
+		if err != nil || i < 1 {
+			return 0, fmt.Errorf("column `%s` must be a position starting at 1 when the file has no header", ref)
+		}
+		return i - 1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(ref)) {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(ref); err == nil && i >= 1 && i <= len(header) {
+		return i - 1, nil
+	}
+	return 0, fmt.Errorf("column `%s` not found in header", ref)
+}
+
+// parseRecord parses a single CSV record into a transaction.
+func parseRecord(record []string, cols columns, layout string, decimalSeparator rune, currency domain.Currency, negate bool) (*domain.Transaction, error) {
+	field := func(i int) (string, error) {
+		if i >= len(record) {
+			return "", fmt.Errorf("missing column %d", i+1)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+
+	dateValue, err := field(cols.date)
+	if err != nil {
+		return nil, err
+	}
+	date, err := time.Parse(layout, dateValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date `%s`", dateValue)
+	}
+
+	label, err := field(cols.label)
+	if err != nil {
+		return nil, err
+	}
+	if label == "" {
+		return nil, fmt.Errorf("missing label")
+	}
+
+	var amount int64
+	if cols.amount >= 0 {
+		value, err := field(cols.amount)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := domain.ParseMoneyWithSeparator(value, currency, decimalSeparator)
+		if err != nil {
+			return nil, err
+		}
+		amount = parsed.Amount
+	} else {
+		found := false
+		for _, c := range []struct {
+			index int
+			sign  int64
+		}{
+			{cols.debit, -1},
+			{cols.credit, 1},
+		} {
+			if c.index < 0 {
+				continue
+			}
+			value, err := field(c.index)
+			if err != nil {
+				return nil, err
+			}
+			if value == "" {
+				continue
+			}
+			parsed, err := domain.ParseMoneyWithSeparator(value, currency, decimalSeparator)
+			if err != nil {
+				return nil, err
+			}
+			if parsed.Amount < 0 {
+				parsed.Amount = -parsed.Amount
+			}
+			amount += c.sign * parsed.Amount
+			found = true
+		}
+		if !found {
+			return nil, fmt.Errorf("missing debit or credit amount")
+		}
+	}
+	if negate {
+		amount = -amount
+	}
+	if amount == 0 {
+		return nil, fmt.Errorf("amount must not be 0")
+	}
+
+	return domain.NewTransaction().
+		WithLabel(label).
+		WithAmount(amount).
+		WithCurrency(currency).
+		WithDate(date), nil
+}
+
+// isBlank returns true if every field in the record is empty.
+func isBlank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package importer_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/importer"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Parallel()
+
+	t.Run("debit and credit columns", func(t *testing.T) {
+		data := `Date;Description;Paid out;Paid in
+01/03/2019;Shop;12,50;
+02/03/2019;Salary;;2.000,00
+03/03/2019;Broken;abc;
+`
+		mapping := importer.NewMapping()
+		mapping.Date = "date"
+		mapping.Label = "Description"
+		mapping.Debit = "Paid out"
+		mapping.Credit = "Paid in"
+		mapping.DateFormat = "DD/MM/YYYY"
+		mapping.DecimalSeparator = ","
+		mapping.Delimiter = ";"
+
+		res, err := importer.ParseCSV(strings.NewReader(data), mapping)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if exp, got := 2, len(res.Rows); exp != got {
+			t.Fatalf("expected %d rows, got %d", exp, got)
+		}
+		if exp, got := int64(-1250), res.Rows[0].Transaction.Amount; exp != got {
+			t.Errorf("expected amount %d, got %d", exp, got)
+		}
+		if exp, got := int64(200000), res.Rows[1].Transaction.Amount; exp != got {
+			t.Errorf("expected amount %d, got %d", exp, got)
+		}
+		if exp, got := "2019-03-02", res.Rows[1].Transaction.Date.Format("2006-01-02"); exp != got {
+			t.Errorf("expected date %s, got %s", exp, got)
+		}
+		if exp, got := 1, len(res.Errors); exp != got {
+			t.Fatalf("expected %d errors, got %d", exp, got)
+		}
+		if exp, got := 4, res.Errors[0].Line; exp != got {
+			t.Errorf("expected error on row %d, got %d", exp, got)
+		}
+	})
+
+	t.Run("no header", func(t *testing.T) {
+		data := "2019-03-01,-4.35,Coffee\n"
+		mapping := importer.NewMapping()
+		mapping.Date = "1"
+		mapping.Amount = "2"
+		mapping.Label = "3"
+		mapping.NoHeader = true
+		mapping.Negate = true
+
+		res, err := importer.ParseCSV(strings.NewReader(data), mapping)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if exp, got := 1, len(res.Rows); exp != got {
+			t.Fatalf("expected %d rows, got %d", exp, got)
+		}
+		if exp, got := int64(435), res.Rows[0].Transaction.Amount; exp != got {
+			t.Errorf("expected amount %d, got %d", exp, got)
+		}
+	})
+
+	t.Run("currency", func(t *testing.T) {
+		data := "Date,Label,Amount\n2019-03-01,Ramen,\"-1,200\"\n"
+		mapping := importer.NewMapping()
+		mapping.Date = "Date"
+		mapping.Label = "Label"
+		mapping.Amount = "Amount"
+		mapping.Currency = "JPY"
+
+		res, err := importer.ParseCSV(strings.NewReader(data), mapping)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if exp, got := 1, len(res.Rows); exp != got {
+			t.Fatalf("expected %d rows, got %d", exp, got)
+		}
+		if exp, got := domain.NewMoney(-1200, "JPY"), res.Rows[0].Transaction.Money(); exp != got {
+			t.Errorf("expected %s, got %s", exp, got)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		mapping := importer.NewMapping()
+		mapping.Date = "Date"
+		mapping.Label = "Label"
+		mapping.Amount = "Value"
+
+		if _, err := importer.ParseCSV(strings.NewReader("Date,Label,Amount\n"), mapping); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RateResult contains the outcome of parsing an exchange rates CSV file.
+type RateResult struct {
+	// Rates contains the rates that were parsed successfully.
+	Rates []*domain.ExchangeRate
+	// Errors contains the rows that could not be parsed.
+	Errors []RowError
+}
+
+// ParseRatesCSV parses a CSV file of exchange rates with a header row containing
+// the columns date, from, to and rate, in any order.
+// Dates must be in the format YYYY-MM-DD, and rate is the number of units of the to currency
+// that one unit of the from currency is worth.
+// An error is only returned if the file as a whole cannot be read. Rows that cannot be
+// parsed are returned in the RateResult.
+func ParseRatesCSV(r io.Reader) (*RateResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	res := &RateResult{
+		Rates:  make([]*domain.ExchangeRate, 0),
+		Errors: make([]RowError, 0),
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return res, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read header row: %s", err)
+	}
+
+	var dateCol, fromCol, toCol, rateCol int
+	for _, c := range []struct {
+		ref  string
+		dest *int
+	}{
+		{"date", &dateCol},
+		{"from", &fromCol},
+		{"to", &toCol},
+		{"rate", &rateCol},
+	} {
+		if *c.dest, err = columnIndex(c.ref, header); err != nil {
+			return nil, err
+		}
+	}
+
+	// line tracks the row number in the file, counting the header row.
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			if parseErr, ok := err.(*csv.ParseError); ok {
+				res.Errors = append(res.Errors, RowError{Line: line, Err: parseErr.Err})
+				continue
+			}
+			return nil, fmt.Errorf("could not read csv: %s", err)
+		}
+		if isBlank(record) {
+			continue
+		}
+
+		rate, err := parseRateRecord(record, dateCol, fromCol, toCol, rateCol)
+		if err != nil {
+			res.Errors = append(res.Errors, RowError{Line: line, Err: err})
+			continue
+		}
+		res.Rates = append(res.Rates, rate)
+	}
+
+	return res, nil
+}
+
+// parseRateRecord parses a single CSV record into an exchange rate.
+func parseRateRecord(record []string, dateCol, fromCol, toCol, rateCol int) (*domain.ExchangeRate, error) {
+	field := func(i int) (string, error) {
+		if i >= len(record) {
+			return "", fmt.Errorf("missing column %d", i+1)
+		}
+		return strings.TrimSpace(record[i]), nil
+	}
+
+	values := make([]string, 4)
+	for i, col := range []int{dateCol, fromCol, toCol, rateCol} {
+		value, err := field(col)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	date, err := domain.ParseDate(values[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date `%s`", values[0])
+	}
+	from, ok := domain.ParseCurrency(values[1])
+	if !ok {
+		return nil, fmt.Errorf("unknown currency `%s`", values[1])
+	}
+	to, ok := domain.ParseCurrency(values[2])
+	if !ok {
+		return nil, fmt.Errorf("unknown currency `%s`", values[2])
+	}
+	rate, err := strconv.ParseFloat(values[3], 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate `%s`", values[3])
+	}
+
+	return &domain.ExchangeRate{
+		From: from,
+		To:   to,
+		Date: date,
+		Rate: rate,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package importer_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/importer"
+	"strings"
+	"testing"
+)
+
+func TestParseRatesCSV(t *testing.T) {
+	t.Parallel()
+
+	data := `Rate,Date,From,To
+0.86,2019-03-01,eur,GBP
+145.5,2019-03-01,GBP,JPY
+abc,2019-03-02,EUR,GBP
+0.85,2019-03-02,EUR,XXX
+`
+	res, err := importer.ParseRatesCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp, got := 2, len(res.Rates); exp != got {
+		t.Fatalf("expected %d rates, got %d", exp, got)
+	}
+	if got := res.Rates[0]; got.From != "EUR" || got.To != "GBP" || got.Rate != 0.86 || got.Date.Format("2006-01-02") != "2019-03-01" {
+		t.Errorf("unexpected rate: %+v", got)
+	}
+	if exp, got := 2, len(res.Errors); exp != got {
+		t.Fatalf("expected %d errors, got %d", exp, got)
+	}
+	if exp, got := 4, res.Errors[0].Line; exp != got {
+		t.Errorf("expected error on row %d, got %d", exp, got)
+	}
+
+	if _, err := importer.ParseRatesCSV(strings.NewReader("date,from,rate\n")); err == nil {
+		t.Errorf("expected error for missing column")
+	}
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// listSeparator is used between the values of a list in delimited formats.
+// It matches the separator used for tags in CSV exports.
+const listSeparator = ";"
+
+// encodeValue returns the JSON encoding of the given cell value.
+func encodeValue(value interface{}) ([]byte, error) {
+	if list, ok := value.([]string); ok && list == nil {
+		value = []string{}
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// formatValue returns the given cell value as plain text for delimited formats.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case []string:
+		return strings.Join(v, listSeparator), nil
+	default:
+		return "", fmt.Errorf("unsupported value type %T", value)
+	}
+}
+
+// renderJSON writes the rows of the given table as a JSON array of objects, or a single object
+// if the table is a record.
+// Fields are written in column order.
+func renderJSON(w io.Writer, table *Table) error {
+	buf := &bytes.Buffer{}
+
+	writeObject := func(row []Cell, indent string) error {
+		buf.WriteString("{")
+		for i, c := range table.Columns {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := encodeValue(c.Key)
+			value, err := encodeValue(row[i].Value)
+			if err != nil {
+				return fmt.Errorf("could not encode %s: %s", c.Key, err)
+			}
+			fmt.Fprintf(buf, "\n%s  %s: %s", indent, key, value)
+		}
+		if len(table.Columns) > 0 {
+			buf.WriteString("\n" + indent)
+		}
+		buf.WriteString("}")
+		return nil
+	}
+
+	if table.Record {
+		if len(table.Rows) != 1 {
+			return fmt.Errorf("record must contain exactly 1 row, got %d", len(table.Rows))
+		}
+		if err := writeObject(table.Rows[0], ""); err != nil {
+			return err
+		}
+	} else {
+		buf.WriteString("[")
+		for i, row := range table.Rows {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n  ")
+			if err := writeObject(row, "  "); err != nil {
+				return err
+			}
+		}
+		if len(table.Rows) > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("]")
+	}
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// renderYAML writes the rows of the given table as a YAML sequence of mappings, or a single mapping
+// if the table is a record.
+// Scalars are written in their JSON form, which is also valid YAML.
+func renderYAML(w io.Writer, table *Table) error {
+	buf := &bytes.Buffer{}
+
+	writeMapping := func(row []Cell, first string, indent string) error {
+		for i, c := range table.Columns {
+			value, err := encodeValue(row[i].Value)
+			if err != nil {
+				return fmt.Errorf("could not encode %s: %s", c.Key, err)
+			}
+			prefix := indent
+			if i == 0 {
+				prefix = first
+			}
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, c.Key, value)
+		}
+		return nil
+	}
+
+	if table.Record {
+		if len(table.Rows) != 1 {
+			return fmt.Errorf("record must contain exactly 1 row, got %d", len(table.Rows))
+		}
+		if err := writeMapping(table.Rows[0], "", ""); err != nil {
+			return err
+		}
+	} else {
+		if len(table.Rows) == 0 {
+			buf.WriteString("[]\n")
+		}
+		for _, row := range table.Rows {
+			if err := writeMapping(row, "- ", "  "); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// renderDelimited writes the rows of the given table with a header row of column keys, using the given
+// character between each column.
+func renderDelimited(w io.Writer, table *Table, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	header := make([]string, len(table.Columns))
+	for i, c := range table.Columns {
+		header[i] = c.Key
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range table.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			value, err := formatValue(cell.Value)
+			if err != nil {
+				return fmt.Errorf("could not format %s: %s", table.Columns[i].Key, err)
+			}
+			record[i] = value
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,186 @@
+// Package render writes the output of read commands as a human readable table, or in a machine
+// readable format such as JSON or CSV.
+package render
+
+import (
+	"fmt"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format defines how output is rendered.
+type Format string
+
+const (
+	// FormatTable renders a human readable ASCII table.
+	FormatTable Format = "table"
+	// FormatJSON renders a JSON array of objects, or a single object for records.
+	FormatJSON Format = "json"
+	// FormatCSV renders comma separated values with a header row.
+	FormatCSV Format = "csv"
+	// FormatTSV renders tab separated values with a header row.
+	FormatTSV Format = "tsv"
+	// FormatYAML renders a YAML sequence of mappings, or a single mapping for records.
+	FormatYAML Format = "yaml"
+)
+
+// Formats contains all of the valid formats.
+var Formats = []Format{
+	FormatTable,
+	FormatJSON,
+	FormatCSV,
+	FormatTSV,
+	FormatYAML,
+}
+
+// Valid returns true if the format is known.
+func (x Format) Valid() bool {
+	for _, f := range Formats {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// Column describes a single field in the output.
+type Column struct {
+	// Key is the field name used in machine readable formats, such as amount.
+	// Keys are stable and should not be changed once released.
+	Key string
+	// Header is the heading of the column in tables, such as Amount.
+	// Columns without a Header are only included in machine readable formats.
+	Header string
+}
+
+// Cell is a single value in the output.
+type Cell struct {
+	// Text is shown in tables.
+	Text string
+	// Value is used in machine readable formats.
+	// Value must be one of nil, string, bool, int, int64, float64 or []string.
+	Value interface{}
+}
+
+// Text returns a cell containing the given string.
+func Text(value string) Cell {
+	return Cell{Text: value, Value: value}
+}
+
+// Int returns a cell containing the given number.
+func Int(value int) Cell {
+	return Cell{Text: fmt.Sprint(value), Value: value}
+}
+
+// Bool returns a cell containing the given bool, shown in tables as yes or no.
+func Bool(value bool) Cell {
+	text := "no"
+	if value {
+		text = "yes"
+	}
+	return Cell{Text: text, Value: value}
+}
+
+// Money returns a cell containing the given amount.
+// Tables show the amount with its currency symbol, and machine readable formats use the raw amount in the
+// minor unit of the currency. The currency should be given in a separate column.
+func Money(value domain.Money) Cell {
+	return Cell{Text: value.String(), Value: value.Amount}
+}
+
+// Totals returns a cell containing the given amounts, such as the totals of transactions in each currency.
+// Machine readable formats use a list of raw amounts followed by their currency, such as "-43500 GBP".
+func Totals(values []domain.Money) Cell {
+	if len(values) == 0 {
+		return Cell{Text: "0", Value: []string{}}
+	}
+	text := make([]string, len(values))
+	list := make([]string, len(values))
+	for i, v := range values {
+		text[i] = v.String()
+		list[i] = fmt.Sprintf("%d %s", v.Amount, v.Currency)
+	}
+	return Cell{Text: strings.Join(text, ", "), Value: list}
+}
+
+// Date returns a cell containing the given day, or nothing if it is zero.
+func Date(value time.Time) Cell {
+	if value.IsZero() {
+		return Cell{}
+	}
+	date := value.Format(domain.DateFormat)
+	return Cell{Text: date, Value: date}
+}
+
+// List returns a cell containing the given strings.
+func List(values []string) Cell {
+	list := make([]string, len(values))
+	copy(list, values)
+	return Cell{Text: strings.Join(values, ", "), Value: list}
+}
+
+// Table is output made up of rows of cells, with one cell for each column.
+type Table struct {
+	// Title is shown as the caption of tables, or in the heading of records.
+	Title string
+	// Subtitle is shown alongside the Title in the heading of records.
+	Subtitle string
+	// Columns describes each field in the rows.
+	Columns []Column
+	// Rows contains the values of each row.
+	Rows [][]Cell
+	// Footer is shown below tables, such as totals, with one value for each column that has a Header.
+	// Footer is not included in machine readable formats.
+	Footer []string
+	// Record is true if the table contains a single row that is shown as a list of fields in tables,
+	// and as a single object rather than a list in JSON and YAML.
+	Record bool
+}
+
+// NewTable returns a new Table with the given title and columns.
+func NewTable(title string, columns ...Column) *Table {
+	return &Table{
+		Title:   title,
+		Columns: columns,
+		Rows:    make([][]Cell, 0),
+	}
+}
+
+// NewRecord returns a new Table with the given title and subtitle that contains the given fields.
+func NewRecord(title string, subtitle string, fields []Column, values ...Cell) *Table {
+	x := NewTable(title, fields...)
+	x.Subtitle = subtitle
+	x.Record = true
+	x.Append(values...)
+	return x
+}
+
+// Append adds a row to the table.
+func (x *Table) Append(cells ...Cell) {
+	x.Rows = append(x.Rows, cells)
+}
+
+// Render writes the given table to w in the given format.
+func Render(w io.Writer, format Format, table *Table) error {
+	for i, row := range table.Rows {
+		if len(row) != len(table.Columns) {
+			return fmt.Errorf("row %d has %d cells but there are %d columns", i, len(row), len(table.Columns))
+		}
+	}
+	switch format {
+	case FormatTable, "":
+		return renderTable(w, table)
+	case FormatJSON:
+		return renderJSON(w, table)
+	case FormatCSV:
+		return renderDelimited(w, table, ',')
+	case FormatTSV:
+		return renderDelimited(w, table, '\t')
+	case FormatYAML:
+		return renderYAML(w, table)
+	default:
+		return fmt.Errorf("unknown output format `%s`", format)
+	}
+}
@@ -0,0 +1,238 @@
+package render_test
+
+import (
+	"bytes"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/render"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testTable() *render.Table {
+	table := render.NewTable("Transactions",
+		render.Column{Key: "id", Header: "ID"},
+		render.Column{Key: "label", Header: "Label"},
+		render.Column{Key: "tags", Header: "Tags"},
+		render.Column{Key: "amount", Header: "Amount"},
+		render.Column{Key: "currency"},
+		render.Column{Key: "date", Header: "Date"},
+		render.Column{Key: "projected", Header: "Projected"},
+	)
+	table.Append(
+		render.Text("tra:1"),
+		render.Text("Salary, March"),
+		render.List(nil),
+		render.Money(domain.NewMoney(200000, "GBP")),
+		render.Text("GBP"),
+		render.Date(time.Date(2019, 3, 29, 0, 0, 0, 0, time.UTC)),
+		render.Bool(false),
+	)
+	table.Append(
+		render.Text("tra:2"),
+		render.Text(`"Fish" & chips`),
+		render.List([]string{"food", "takeaway"}),
+		render.Money(domain.NewMoney(-1250, "GBP")),
+		render.Text("GBP"),
+		render.Date(time.Time{}),
+		render.Bool(true),
+	)
+	table.Footer = []string{"", "Total", "", "£1987.50", "", ""}
+	return table
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format render.Format
+		exp    string
+	}{
+		{
+			format: render.FormatJSON,
+			exp: `[
+  {
+    "id": "tra:1",
+    "label": "Salary, March",
+    "tags": [],
+    "amount": 200000,
+    "currency": "GBP",
+    "date": "2019-03-29",
+    "projected": false
+  },
+  {
+    "id": "tra:2",
+    "label": "\"Fish\" & chips",
+    "tags": ["food","takeaway"],
+    "amount": -1250,
+    "currency": "GBP",
+    "date": null,
+    "projected": true
+  }
+]
+`,
+		},
+		{
+			format: render.FormatCSV,
+			exp: `id,label,tags,amount,currency,date,projected
+tra:1,"Salary, March",,200000,GBP,2019-03-29,false
+tra:2,"""Fish"" & chips",food;takeaway,-1250,GBP,,true
+`,
+		},
+		{
+			format: render.FormatTSV,
+			exp: "id\tlabel\ttags\tamount\tcurrency\tdate\tprojected\n" +
+				"tra:1\tSalary, March\t\t200000\tGBP\t2019-03-29\tfalse\n" +
+				"tra:2\t\"\"\"Fish\"\" & chips\"\tfood;takeaway\t-1250\tGBP\t\ttrue\n",
+		},
+		{
+			format: render.FormatYAML,
+			exp: `- id: "tra:1"
+  label: "Salary, March"
+  tags: []
+  amount: 200000
+  currency: "GBP"
+  date: "2019-03-29"
+  projected: false
+- id: "tra:2"
+  label: "\"Fish\" & chips"
+  tags: ["food","takeaway"]
+  amount: -1250
+  currency: "GBP"
+  date: null
+  projected: true
+`,
+		},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(string(tc.format), func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			if err := render.Render(buf, tc.format, testTable()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := buf.String(); got != tc.exp {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	if err := render.Render(buf, render.FormatTable, testTable()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	got := buf.String()
+
+	for _, exp := range []string{"Salary, March", "£2000.00", "food, takeaway", "yes", "Total", "Transactions"} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("expected table to contain `%s`, got:\n%s", exp, got)
+		}
+	}
+	// Columns without a header are only included in machine readable formats.
+	if strings.Contains(got, "GBP") {
+		t.Errorf("expected table to not contain the currency column, got:\n%s", got)
+	}
+}
+
+func TestRender_Record(t *testing.T) {
+	t.Parallel()
+
+	record := func() *render.Table {
+		return render.NewRecord("Profile", "tom",
+			[]render.Column{
+				{Key: "id", Header: "ID"},
+				{Key: "transactions", Header: "Transactions"},
+				{Key: "total", Header: "Total"},
+			},
+			render.Text("pro:1"),
+			render.Int(2),
+			render.Totals([]domain.Money{domain.NewMoney(-43505, "GBP"), domain.NewMoney(-1200, "JPY")}),
+		)
+	}
+
+	tests := []struct {
+		format render.Format
+		exp    string
+	}{
+		{
+			format: render.FormatJSON,
+			exp: `{
+  "id": "pro:1",
+  "transactions": 2,
+  "total": ["-43505 GBP","-1200 JPY"]
+}
+`,
+		},
+		{
+			format: render.FormatYAML,
+			exp: `id: "pro:1"
+transactions: 2
+total: ["-43505 GBP","-1200 JPY"]
+`,
+		},
+		{
+			format: render.FormatCSV,
+			exp: `id,transactions,total
+pro:1,2,-43505 GBP;-1200 JPY
+`,
+		},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(string(tc.format), func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			if err := render.Render(buf, tc.format, record()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := buf.String(); got != tc.exp {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRender_Empty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format render.Format
+		exp    string
+	}{
+		{format: render.FormatJSON, exp: "[]\n"},
+		{format: render.FormatYAML, exp: "[]\n"},
+		{format: render.FormatCSV, exp: "id,label\n"},
+	}
+
+	for _, test := range tests {
+		tc := test
+		t.Run(string(tc.format), func(t *testing.T) {
+			t.Parallel()
+			table := render.NewTable("Empty", render.Column{Key: "id"}, render.Column{Key: "label"})
+			buf := &bytes.Buffer{}
+			if err := render.Render(buf, tc.format, table); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := buf.String(); got != tc.exp {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.exp, got)
+			}
+		})
+	}
+}
+
+func TestRender_InvalidRow(t *testing.T) {
+	t.Parallel()
+
+	table := render.NewTable("Invalid", render.Column{Key: "id"}, render.Column{Key: "label"})
+	table.Append(render.Text("tra:1"))
+	if err := render.Render(&bytes.Buffer{}, render.FormatJSON, table); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,54 @@
+package render
+
+import (
+	"github.com/olekukonko/tablewriter"
+	"io"
+)
+
+// renderTable writes the given table as a human readable ASCII table.
+// Columns without a Header are left out.
+func renderTable(w io.Writer, table *Table) error {
+	outputTable := tablewriter.NewWriter(w)
+	outputTable.SetAutoFormatHeaders(false)
+	outputTable.SetAutoWrapText(false)
+
+	if table.Record {
+		outputTable.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT})
+		outputTable.SetHeader([]string{table.Title, table.Subtitle})
+		for _, row := range table.Rows {
+			for i, c := range table.Columns {
+				if c.Header != "" {
+					outputTable.Append([]string{c.Header, row[i].Text})
+				}
+			}
+		}
+		outputTable.Render()
+		return nil
+	}
+
+	header := make([]string, 0, len(table.Columns))
+	for _, c := range table.Columns {
+		if c.Header != "" {
+			header = append(header, c.Header)
+		}
+	}
+	outputTable.SetHeader(header)
+	if table.Title != "" {
+		outputTable.SetCaption(true, table.Title)
+	}
+
+	for _, row := range table.Rows {
+		values := make([]string, 0, len(header))
+		for i, c := range table.Columns {
+			if c.Header != "" {
+				values = append(values, row[i].Text)
+			}
+		}
+		outputTable.Append(values)
+	}
+	if len(table.Footer) > 0 {
+		outputTable.SetFooter(table.Footer)
+	}
+	outputTable.Render()
+	return nil
+}
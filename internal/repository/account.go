@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+)
+
+// Account allows you to load and save accounts.
+type Account interface {
+	// LoadAccountByID loads the given account by id.
+	LoadAccountByID(id string) (*domain.Account, errs.Error)
+	// LoadAccountByName loads the account with the given name belonging to the given profile.
+	LoadAccountByName(profileID string, name string) (*domain.Account, errs.Error)
+	// LoadAccountsByProfileID loads the accounts belonging to the given profile, ordered by name.
+	LoadAccountsByProfileID(id string) ([]*domain.Account, errs.Error)
+	// CreateAccount creates the given account.
+	CreateAccount(account *domain.Account) errs.Error
+	// UpdateAccount updates the given account.
+	UpdateAccount(account *domain.Account) errs.Error
+	// DeleteAccountsByProfileID deletes all accounts belonging to the given profile.
+	DeleteAccountsByProfileID(id string) errs.Error
+}
+
+func NewSQLiteAccount(db *sql.DB) Account {
+	return &sqliteAccount{
+		db: db,
+	}
+}
+
+// sqliteAccount implements Account
+type sqliteAccount struct {
+	db querier
+}
+
+const accountColumns = `id, profile_id, name, currency, opening_balance, closed, created_at, updated_at`
+
+// scanAccount scans a single account row.
+func scanAccount(row scanner) (*domain.Account, error) {
+	res := domain.NewAccount()
+	err := row.Scan(&res.ID, &res.ProfileID, &res.Name, &res.Currency, &res.OpeningBalance, &res.Closed,
+		&res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// LoadAccountByID loads the given account by id.
+func (x *sqliteAccount) LoadAccountByID(id string) (*domain.Account, errs.Error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE id = ?;`
+	res, err := scanAccount(x.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownAccount).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("account id not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadAccountByName loads the account with the given name belonging to the given profile.
+func (x *sqliteAccount) LoadAccountByName(profileID string, name string) (*domain.Account, errs.Error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE profile_id = ? AND name = ?;`
+	res, err := scanAccount(x.db.QueryRow(query, profileID, name))
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownAccount).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("account name not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadAccountsByProfileID loads the accounts belonging to the given profile, ordered by name.
+func (x *sqliteAccount) LoadAccountsByProfileID(id string) ([]*domain.Account, errs.Error) {
+	query := `SELECT ` + accountColumns + ` FROM accounts WHERE profile_id = ? ORDER BY name;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query accounts: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.Account, 0)
+
+	for rows.Next() {
+		row, err := scanAccount(rows)
+		if err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// CreateAccount creates the given account.
+func (x *sqliteAccount) CreateAccount(account *domain.Account) errs.Error {
+	query := `INSERT INTO accounts (` + accountColumns + `) VALUES(?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := x.db.Exec(query, account.ID, account.ProfileID, account.Name, account.Currency, account.OpeningBalance,
+		account.Closed, account.CreatedAt.UTC(), account.UpdatedAt.UTC())
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not insert row: ")
+	}
+	return nil
+}
+
+// UpdateAccount updates the given account.
+func (x *sqliteAccount) UpdateAccount(account *domain.Account) errs.Error {
+	query := `UPDATE accounts SET name = ?, opening_balance = ?, closed = ?, updated_at = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, account.Name, account.OpeningBalance, account.Closed, account.UpdatedAt.UTC(), account.ID)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not update row: ")
+	}
+	return nil
+}
+
+// DeleteAccountsByProfileID deletes all accounts belonging to the given profile.
+func (x *sqliteAccount) DeleteAccountsByProfileID(id string) errs.Error {
+	if _, err := x.db.Exec(`DELETE FROM accounts WHERE profile_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete rows: ")
+	}
+	return nil
+}
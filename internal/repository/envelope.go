@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+)
+
+// Envelope allows you to load and save budget envelopes.
+type Envelope interface {
+	// LoadEnvelopeByID loads the given envelope by id.
+	LoadEnvelopeByID(id string) (*domain.Envelope, errs.Error)
+	// LoadEnvelopeByTag loads the envelope for the given tag belonging to the given profile.
+	LoadEnvelopeByTag(profileID string, tag string) (*domain.Envelope, errs.Error)
+	// LoadEnvelopesByProfileID loads the envelopes belonging to the given profile, ordered by tag.
+	LoadEnvelopesByProfileID(id string) ([]*domain.Envelope, errs.Error)
+	// CreateEnvelope creates the given envelope.
+	CreateEnvelope(envelope *domain.Envelope) errs.Error
+	// UpdateEnvelope updates the given envelope.
+	UpdateEnvelope(envelope *domain.Envelope) errs.Error
+	// DeleteEnvelope deletes the given envelope.
+	DeleteEnvelope(id string) errs.Error
+	// DeleteEnvelopesByProfileID deletes all envelopes belonging to the given profile.
+	DeleteEnvelopesByProfileID(id string) errs.Error
+}
+
+func NewSQLiteEnvelope(db *sql.DB) Envelope {
+	return &sqliteEnvelope{
+		db: db,
+	}
+}
+
+// sqliteEnvelope implements Envelope
+type sqliteEnvelope struct {
+	db querier
+}
+
+const envelopeColumns = `id, profile_id, tag, amount, currency, period, rollover, start_date, created_at, updated_at`
+
+// scanEnvelope scans a single envelope row.
+func scanEnvelope(row scanner) (*domain.Envelope, error) {
+	res := domain.NewEnvelope()
+	err := row.Scan(&res.ID, &res.ProfileID, &res.Tag, &res.Amount, &res.Currency, &res.Period, &res.Rollover,
+		&res.Start, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// LoadEnvelopeByID loads the given envelope by id.
+func (x *sqliteEnvelope) LoadEnvelopeByID(id string) (*domain.Envelope, errs.Error) {
+	query := `SELECT ` + envelopeColumns + ` FROM envelopes WHERE id = ?;`
+	res, err := scanEnvelope(x.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownEnvelope).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("envelope id not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadEnvelopeByTag loads the envelope for the given tag belonging to the given profile.
+func (x *sqliteEnvelope) LoadEnvelopeByTag(profileID string, tag string) (*domain.Envelope, errs.Error) {
+	query := `SELECT ` + envelopeColumns + ` FROM envelopes WHERE profile_id = ? AND tag = ?;`
+	res, err := scanEnvelope(x.db.QueryRow(query, profileID, tag))
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownEnvelope).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("envelope tag not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadEnvelopesByProfileID loads the envelopes belonging to the given profile, ordered by tag.
+func (x *sqliteEnvelope) LoadEnvelopesByProfileID(id string) ([]*domain.Envelope, errs.Error) {
+	query := `SELECT ` + envelopeColumns + ` FROM envelopes WHERE profile_id = ? ORDER BY tag;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query envelopes: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.Envelope, 0)
+
+	for rows.Next() {
+		row, err := scanEnvelope(rows)
+		if err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// CreateEnvelope creates the given envelope.
+func (x *sqliteEnvelope) CreateEnvelope(envelope *domain.Envelope) errs.Error {
+	query := `INSERT INTO envelopes (` + envelopeColumns + `) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := x.db.Exec(query, envelope.ID, envelope.ProfileID, envelope.Tag, envelope.Amount, envelope.Currency,
+		envelope.Period, envelope.Rollover, envelope.Start.UTC(), envelope.CreatedAt.UTC(), envelope.UpdatedAt.UTC())
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not insert row: ")
+	}
+	return nil
+}
+
+// UpdateEnvelope updates the given envelope.
+func (x *sqliteEnvelope) UpdateEnvelope(envelope *domain.Envelope) errs.Error {
+	query := `UPDATE envelopes SET tag = ?, amount = ?, currency = ?, period = ?, rollover = ?, start_date = ?,
+		updated_at = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, envelope.Tag, envelope.Amount, envelope.Currency, envelope.Period, envelope.Rollover,
+		envelope.Start.UTC(), envelope.UpdatedAt.UTC(), envelope.ID)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not update row: ")
+	}
+	return nil
+}
+
+// DeleteEnvelope deletes the given envelope.
+func (x *sqliteEnvelope) DeleteEnvelope(id string) errs.Error {
+	res, err := x.db.Exec(`DELETE FROM envelopes WHERE id = ?;`, id)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete row: ")
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return errs.New().
+			WithCode(errs.ErrUnknownEnvelope).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("envelope id not found")
+	}
+	return nil
+}
+
+// DeleteEnvelopesByProfileID deletes all envelopes belonging to the given profile.
+func (x *sqliteEnvelope) DeleteEnvelopesByProfileID(id string) errs.Error {
+	if _, err := x.db.Exec(`DELETE FROM envelopes WHERE profile_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete rows: ")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestSQLiteEnvelope(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	repo := repository.NewSQLiteEnvelope(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	start := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+	envelopes := []*domain.Envelope{
+		{ID: "env:1", ProfileID: "pro:1", Tag: "groceries", Amount: 40000, Currency: "GBP",
+			Period: domain.EnvelopePeriodMonthly, Rollover: true, Start: start, CreatedAt: now, UpdatedAt: now},
+		{ID: "env:2", ProfileID: "pro:1", Tag: "eating-out", Amount: 15000, Currency: "GBP",
+			Period: domain.EnvelopePeriodWeekly, Start: start, CreatedAt: now, UpdatedAt: now},
+		{ID: "env:3", ProfileID: "pro:2", Tag: "groceries", Amount: 30000, Currency: "EUR",
+			Period: domain.EnvelopePeriodMonthly, Start: start, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, e := range envelopes {
+		if err := repo.CreateEnvelope(e); err != nil {
+			t.Fatalf("could not create envelope: %s", err)
+		}
+	}
+
+	t.Run("UniqueTag", func(t *testing.T) {
+		duplicate := *envelopes[0]
+		duplicate.ID = "env:4"
+		if err := repo.CreateEnvelope(&duplicate); err == nil {
+			t.Errorf("expected an error when creating a second envelope for the same tag")
+		}
+	})
+
+	t.Run("LoadEnvelopeByTag", func(t *testing.T) {
+		got, err := repo.LoadEnvelopeByTag("pro:1", "groceries")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got.ID != "env:1" || got.Amount != 40000 || got.Currency != "GBP" ||
+			got.Period != domain.EnvelopePeriodMonthly || !got.Rollover || !got.Start.Equal(start) {
+			t.Errorf("unexpected envelope: %+v", got)
+		}
+
+		_, err = repo.LoadEnvelopeByTag("pro:1", "unknown")
+		if err == nil || err.Code() != errs.ErrUnknownEnvelope {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownEnvelope, err)
+		}
+	})
+
+	t.Run("LoadEnvelopesByProfileID", func(t *testing.T) {
+		got, err := repo.LoadEnvelopesByProfileID("pro:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(got) != 2 || got[0].Tag != "eating-out" || got[1].Tag != "groceries" {
+			t.Errorf("expected envelopes ordered by tag, got %v", got)
+		}
+	})
+
+	t.Run("UpdateEnvelope", func(t *testing.T) {
+		e := envelopes[1]
+		e.Amount = 20000
+		e.Rollover = true
+		if err := repo.UpdateEnvelope(e); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		got, err := repo.LoadEnvelopeByID(e.ID)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got.Amount != 20000 || !got.Rollover {
+			t.Errorf("unexpected envelope: %+v", got)
+		}
+	})
+
+	t.Run("DeleteEnvelope", func(t *testing.T) {
+		if err := repo.DeleteEnvelope("env:3"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := repo.LoadEnvelopeByID("env:3"); err == nil || err.Code() != errs.ErrUnknownEnvelope {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownEnvelope, err)
+		}
+		if err := repo.DeleteEnvelope("env:3"); err == nil || err.Code() != errs.ErrUnknownEnvelope {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownEnvelope, err)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+)
+
+// ExchangeRate allows you to load and save exchange rates.
+type ExchangeRate interface {
+	// LoadExchangeRates loads the exchange rates between the given currencies, ordered by date.
+	// An empty currency matches any currency.
+	LoadExchangeRates(from domain.Currency, to domain.Currency) ([]*domain.ExchangeRate, errs.Error)
+	// SaveExchangeRate saves the given exchange rate, replacing any existing rate between the
+	// same currencies on the same day.
+	SaveExchangeRate(rate *domain.ExchangeRate) errs.Error
+}
+
+func NewSQLiteExchangeRate(db *sql.DB) ExchangeRate {
+	return &sqliteExchangeRate{
+		db: db,
+	}
+}
+
+// sqliteExchangeRate implements ExchangeRate
+type sqliteExchangeRate struct {
+	db querier
+}
+
+// LoadExchangeRates loads the exchange rates between the given currencies, ordered by date.
+// An empty currency matches any currency.
+func (x *sqliteExchangeRate) LoadExchangeRates(from domain.Currency, to domain.Currency) ([]*domain.ExchangeRate, errs.Error) {
+	query := `SELECT from_currency, to_currency, date, rate FROM exchange_rates WHERE 1 = 1`
+	args := make([]interface{}, 0)
+	if from != "" {
+		query += ` AND from_currency = ?`
+		args = append(args, from)
+	}
+	if to != "" {
+		query += ` AND to_currency = ?`
+		args = append(args, to)
+	}
+	query += ` ORDER BY date, from_currency, to_currency;`
+
+	rows, err := x.db.Query(query, args...)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query exchange rates: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.ExchangeRate, 0)
+
+	for rows.Next() {
+		row := &domain.ExchangeRate{}
+		if err := rows.Scan(&row.From, &row.To, &row.Date, &row.Rate); err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// SaveExchangeRate saves the given exchange rate, replacing any existing rate between the
+// same currencies on the same day.
+func (x *sqliteExchangeRate) SaveExchangeRate(rate *domain.ExchangeRate) errs.Error {
+	query := `INSERT OR REPLACE INTO exchange_rates (from_currency, to_currency, date, rate) VALUES(?, ?, ?, ?);`
+	_, err := x.db.Exec(query, rate.From, rate.To, domain.TruncateDay(rate.Date), rate.Rate)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not insert row: ")
+	}
+	return nil
+}
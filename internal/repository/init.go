@@ -15,3 +15,17 @@ func ConnectSQLite(storageDir string) (*sql.DB, error) {
 	}
 	return db, nil
 }
+
+// scanner is implemented by both *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx, allowing repositories to be used
+// within a unit of work.
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Prepare(query string) (*sql.Stmt, error)
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration is a single, ordered change to the database schema.
+type migration struct {
+	// version is the schema version the database is at once the migration has been applied.
+	version int
+	// description is a short human readable summary of the migration.
+	description string
+	// up applies the migration using the given tx.
+	up func(tx *sql.Tx) error
+}
+
+// MigrationState describes a single migration and whether it has been applied.
+type MigrationState struct {
+	// Version is the schema version the migration brings the database to.
+	Version int
+	// Description is a short human readable summary of the migration.
+	Description string
+	// AppliedAt is the time the migration was applied.
+	// AppliedAt is zero if the migration is pending.
+	AppliedAt time.Time
+}
+
+// Applied returns true if the migration has been applied.
+func (x MigrationState) Applied() bool {
+	return !x.AppliedAt.IsZero()
+}
+
+// MigrationStatus describes the schema version of a database.
+type MigrationStatus struct {
+	// Version is the current schema version of the database.
+	Version int
+	// Latest is the latest schema version known to this binary.
+	Latest int
+	// Migrations contains every migration known to this binary, as well as any applied
+	// migrations that are not, ordered by version.
+	Migrations []MigrationState
+}
+
+// Pending returns the number of migrations that have not yet been applied.
+func (x *MigrationStatus) Pending() int {
+	pending := 0
+	for _, m := range x.Migrations {
+		if !m.Applied() {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Migrator manages the version of the database schema.
+type Migrator interface {
+	// Status returns the current schema version of the database along with every known migration.
+	Status() (*MigrationStatus, error)
+	// Migrate applies any pending migrations in order and returns the migrations that were applied.
+	// An error is returned if the database schema is newer than the latest known version.
+	Migrate() ([]MigrationState, error)
+}
+
+func NewSQLiteMigrator(db *sql.DB) Migrator {
+	return &sqliteMigrator{
+		db:         db,
+		migrations: migrations,
+	}
+}
+
+// sqliteMigrator implements Migrator
+type sqliteMigrator struct {
+	db         *sql.DB
+	migrations []migration
+}
+
+// latest returns the latest schema version known to the migrator.
+func (x *sqliteMigrator) latest() int {
+	if len(x.migrations) == 0 {
+		return 0
+	}
+	return x.migrations[len(x.migrations)-1].version
+}
+
+// init creates the schema_migrations table if it does not exist.
+func (x *sqliteMigrator) init() error {
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		description VARCHAR(255) NOT NULL,
+		applied_at DATETIME NOT NULL
+	);`
+	if _, err := x.db.Exec(query); err != nil {
+		return fmt.Errorf("could not create schema_migrations table: %s", err)
+	}
+	return nil
+}
+
+// applied returns the migrations that have been applied to the database, ordered by version.
+func (x *sqliteMigrator) applied() ([]MigrationState, error) {
+	rows, err := x.db.Query(`SELECT version, description, applied_at FROM schema_migrations ORDER BY version;`)
+	if err != nil {
+		return nil, fmt.Errorf("could not query schema_migrations: %s", err)
+	}
+	defer rows.Close()
+
+	res := make([]MigrationState, 0)
+	for rows.Next() {
+		m := MigrationState{}
+		if err := rows.Scan(&m.Version, &m.Description, &m.AppliedAt); err != nil {
+			return nil, fmt.Errorf("could not scan row: %s", err)
+		}
+		res = append(res, m)
+	}
+	return res, rows.Err()
+}
+
+// Status returns the current schema version of the database along with every known migration.
+func (x *sqliteMigrator) Status() (*MigrationStatus, error) {
+	if err := x.init(); err != nil {
+		return nil, err
+	}
+	applied, err := x.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &MigrationStatus{
+		Latest:     x.latest(),
+		Migrations: make([]MigrationState, 0, len(x.migrations)),
+	}
+
+	appliedByVersion := make(map[int]MigrationState, len(applied))
+	for _, m := range applied {
+		appliedByVersion[m.Version] = m
+		if m.Version > res.Version {
+			res.Version = m.Version
+		}
+	}
+
+	for _, m := range x.migrations {
+		state, ok := appliedByVersion[m.version]
+		if !ok {
+			state = MigrationState{Version: m.version, Description: m.description}
+		}
+		res.Migrations = append(res.Migrations, state)
+	}
+	// Include any migrations applied by a newer binary.
+	for _, m := range applied {
+		if m.Version > res.Latest {
+			res.Migrations = append(res.Migrations, m)
+		}
+	}
+
+	return res, nil
+}
+
+// Migrate applies any pending migrations in order and returns the migrations that were applied.
+// An error is returned if the database schema is newer than the latest known version.
+// Each migration is applied in its own tx along with the record of it being applied, so a
+// failed migration leaves the database at the previous version.
+func (x *sqliteMigrator) Migrate() ([]MigrationState, error) {
+	status, err := x.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Version > status.Latest {
+		return nil, fmt.Errorf("database schema version %d is newer than the latest version supported by this binary (%d): please upgrade", status.Version, status.Latest)
+	}
+
+	res := make([]MigrationState, 0)
+	for _, m := range x.migrations {
+		if m.version <= status.Version {
+			continue
+		}
+		state, err := x.apply(m)
+		if err != nil {
+			return res, err
+		}
+		res = append(res, state)
+	}
+	return res, nil
+}
+
+// apply applies the given migration and records it in the schema_migrations table.
+func (x *sqliteMigrator) apply(m migration) (MigrationState, error) {
+	state := MigrationState{
+		Version:     m.version,
+		Description: m.description,
+		AppliedAt:   time.Now().UTC(),
+	}
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return state, fmt.Errorf("could not begin tx: %s", err)
+	}
+	if err := m.up(tx); err != nil {
+		_ = tx.Rollback()
+		return state, fmt.Errorf("could not apply migration %d (%s): %s", m.version, m.description, err)
+	}
+	query := `INSERT INTO schema_migrations (version, description, applied_at) VALUES(?, ?, ?);`
+	if _, err := tx.Exec(query, state.Version, state.Description, state.AppliedAt); err != nil {
+		_ = tx.Rollback()
+		return state, fmt.Errorf("could not record migration %d: %s", m.version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return state, fmt.Errorf("could not commit tx: %s", err)
+	}
+	return state, nil
+}
+
+// execAll executes each of the given queries using the given tx.
+func execAll(tx *sql.Tx, queries ...string) error {
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnExists returns true if the given table has a column with the given name.
+func columnExists(tx *sql.Tx, table string, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s);`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, colType    string
+			defaultValue     interface{}
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
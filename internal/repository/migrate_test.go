@@ -0,0 +1,167 @@
+package repository_test
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// testDB returns a connection to a new SQLite db along with a func to clean it up.
+func testDB(t *testing.T) (*sql.DB, func()) {
+	dir, err := ioutil.TempDir("", "finance-planner")
+	if err != nil {
+		t.Fatalf("could not create temp dir: %s", err)
+	}
+	db, err := repository.ConnectSQLite(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		t.Fatalf("could not connect: %s", err)
+	}
+	return db, func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func TestMigrator_Migrate_Fresh(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	migrator := repository.NewSQLiteMigrator(db)
+
+	status, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if status.Version != 0 || status.Pending() != status.Latest {
+		t.Fatalf("expected all migrations pending, got version %d with %d pending", status.Version, status.Pending())
+	}
+
+	applied, err := migrator.Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(applied) != status.Latest {
+		t.Errorf("expected %d migrations applied, got %d", status.Latest, len(applied))
+	}
+
+	applied, err = migrator.Migrate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("expected no migrations applied, got %d", len(applied))
+	}
+
+	status, err = migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if status.Version != status.Latest || status.Pending() != 0 {
+		t.Errorf("expected up to date, got version %d of %d with %d pending", status.Version, status.Latest, status.Pending())
+	}
+}
+
+func TestMigrator_Migrate_Untracked(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	// Create the schema as it was before migrations were tracked.
+	_, err := db.Exec(`CREATE TABLE profiles (id VARCHAR(255) PRIMARY KEY, name VARCHAR(255) NOT NULL);
+	CREATE INDEX profiles_name ON profiles (name);
+	CREATE TABLE transactions (id VARCHAR(255) PRIMARY KEY, profile_id VARCHAR(255), label VARCHAR(255), amount INT);
+	CREATE INDEX transactions_profile_id ON transactions (profile_id);
+	CREATE TABLE transaction_tags (transaction_id VARCHAR(255), tag VARCHAR(255), PRIMARY KEY (transaction_id, tag));
+	INSERT INTO profiles (id, name) VALUES ('pro:1', 'tom');
+	INSERT INTO transactions (id, profile_id, label, amount) VALUES ('tra:1', 'pro:1', 'Train ticket', -43500);
+	INSERT INTO transaction_tags (transaction_id, tag) VALUES ('tra:1', 'commute');`)
+	if err != nil {
+		t.Fatalf("could not create legacy schema: %s", err)
+	}
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	transactions, loadErr := repository.NewSQLiteTransaction(db).LoadTransactionsByProfileID("pro:1", domain.DateRange{})
+	if loadErr != nil {
+		t.Fatalf("unexpected error: %s", loadErr)
+	}
+	if len(transactions) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(transactions))
+	}
+	if got := transactions[0]; got.Label != "Train ticket" || got.Amount != -43500 || got.Date.IsZero() {
+		t.Errorf("unexpected transaction: %+v", got)
+	}
+}
+
+func TestMigrator_Migrate_DuplicateProfileNames(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	// Create profiles with duplicate names, as was possible before profile names were unique.
+	_, err := db.Exec(`CREATE TABLE profiles (id VARCHAR(255) PRIMARY KEY, name VARCHAR(255) NOT NULL);
+	CREATE INDEX profiles_name ON profiles (name);
+	INSERT INTO profiles (id, name) VALUES ('pro:1', 'tom');
+	INSERT INTO profiles (id, name) VALUES ('pro:2', 'tom');
+	INSERT INTO profiles (id, name) VALUES ('pro:3', 'jess');`)
+	if err != nil {
+		t.Fatalf("could not create legacy schema: %s", err)
+	}
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	profileRepo := repository.NewSQLiteProfile(db)
+	for id, name := range map[string]string{"pro:1": "tom", "pro:2": "tom (pro:2)", "pro:3": "jess"} {
+		profile, err := profileRepo.LoadProfileByID(id)
+		if err != nil {
+			t.Errorf("could not load %s: %s", id, err)
+			continue
+		}
+		if profile.Name != name {
+			t.Errorf("expected %s to be named %s, got %s", id, name, profile.Name)
+		}
+	}
+
+	duplicate := domain.NewProfile()
+	duplicate.ID = "pro:4"
+	duplicate.Name = "tom"
+	duplicate.Currency = "GBP"
+	if err := profileRepo.CreateProfile(duplicate); err == nil {
+		t.Errorf("expected an error when creating a profile with a duplicate name")
+	}
+}
+
+func TestMigrator_Migrate_Newer(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	migrator := repository.NewSQLiteMigrator(db)
+	status, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = db.Exec(`INSERT INTO schema_migrations (version, description, applied_at) VALUES(?, ?, CURRENT_TIMESTAMP);`,
+		status.Latest+1, "from the future")
+	if err != nil {
+		t.Fatalf("could not insert migration: %s", err)
+	}
+
+	if _, err := migrator.Migrate(); err == nil {
+		t.Errorf("expected error")
+	}
+
+	status, err = migrator.Status()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if status.Version != status.Latest+1 {
+		t.Errorf("expected version %d, got %d", status.Latest+1, status.Version)
+	}
+}
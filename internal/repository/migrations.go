@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// migrations contains every migration, ordered by version.
+// Migrations must never be changed or removed once released: add a new one instead.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create profiles and transactions",
+		up: func(tx *sql.Tx) error {
+			// Tables may already exist in databases created before migrations were tracked.
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS profiles (
+					id VARCHAR(255) PRIMARY KEY,
+					name VARCHAR(255) NOT NULL
+				);`,
+				`CREATE INDEX IF NOT EXISTS profiles_name ON profiles (name);`,
+				`CREATE TABLE IF NOT EXISTS transactions (
+					id VARCHAR(255) PRIMARY KEY,
+					profile_id VARCHAR(255),
+					label VARCHAR(255),
+					amount INT
+				);`,
+				`CREATE INDEX IF NOT EXISTS transactions_profile_id ON transactions (profile_id);`,
+				`CREATE INDEX IF NOT EXISTS transactions_label ON transactions (label);`,
+				`CREATE INDEX IF NOT EXISTS transactions_amount ON transactions (amount);`,
+				`CREATE TABLE IF NOT EXISTS transaction_tags (
+					transaction_id VARCHAR(255),
+					tag VARCHAR(255),
+					PRIMARY KEY (transaction_id, tag)
+				);`,
+				`CREATE INDEX IF NOT EXISTS transaction_tags_transaction_id ON transaction_tags (transaction_id);`,
+				`CREATE INDEX IF NOT EXISTS transaction_tags_tag ON transaction_tags (tag);`,
+			)
+		},
+	},
+	{
+		version:     2,
+		description: "add transaction dates",
+		up: func(tx *sql.Tx) error {
+			exists, err := columnExists(tx, "transactions", "date")
+			if err != nil {
+				return err
+			}
+			if !exists {
+				// SQLite cannot add a column with a default of CURRENT_TIMESTAMP, so the table is
+				// rebuilt. Existing transactions are dated at the time of the migration.
+				if err := execAll(tx,
+					`ALTER TABLE transactions RENAME TO transactions_old;`,
+					`CREATE TABLE transactions (
+						id VARCHAR(255) PRIMARY KEY,
+						profile_id VARCHAR(255),
+						label VARCHAR(255),
+						amount INT,
+						date DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+					);`,
+					`INSERT INTO transactions (id, profile_id, label, amount)
+						SELECT id, profile_id, label, amount FROM transactions_old;`,
+					`DROP TABLE transactions_old;`,
+				); err != nil {
+					return err
+				}
+			}
+			return execAll(tx,
+				`CREATE INDEX IF NOT EXISTS transactions_profile_id ON transactions (profile_id);`,
+				`CREATE INDEX IF NOT EXISTS transactions_label ON transactions (label);`,
+				`CREATE INDEX IF NOT EXISTS transactions_amount ON transactions (amount);`,
+				`CREATE INDEX IF NOT EXISTS transactions_date ON transactions (date);`,
+			)
+		},
+	},
+	{
+		version:     3,
+		description: "create schedules",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS schedules (
+					id VARCHAR(255) PRIMARY KEY,
+					profile_id VARCHAR(255),
+					label VARCHAR(255),
+					amount INT,
+					frequency VARCHAR(255) NOT NULL,
+					frequency_interval INT NOT NULL DEFAULT 1,
+					day_of_month INT NOT NULL DEFAULT 0,
+					start_date DATETIME NOT NULL,
+					end_date DATETIME NULL,
+					paused BOOLEAN NOT NULL DEFAULT 0,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);`,
+				`CREATE INDEX IF NOT EXISTS schedules_profile_id ON schedules (profile_id);`,
+				`CREATE TABLE IF NOT EXISTS schedule_tags (
+					schedule_id VARCHAR(255),
+					tag VARCHAR(255),
+					PRIMARY KEY (schedule_id, tag)
+				);`,
+				`CREATE INDEX IF NOT EXISTS schedule_tags_schedule_id ON schedule_tags (schedule_id);`,
+			)
+		},
+	},
+	{
+		version:     4,
+		description: "add currencies",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`ALTER TABLE profiles ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'GBP';`,
+				`ALTER TABLE transactions ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'GBP';`,
+				`ALTER TABLE schedules ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'GBP';`,
+			)
+		},
+	},
+	{
+		version:     5,
+		description: "create exchange rates",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS exchange_rates (
+					from_currency VARCHAR(3) NOT NULL,
+					to_currency VARCHAR(3) NOT NULL,
+					date DATETIME NOT NULL,
+					rate REAL NOT NULL,
+					PRIMARY KEY (from_currency, to_currency, date)
+				);`,
+			)
+		},
+	},
+	{
+		version:     6,
+		description: "create accounts and transfers",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS accounts (
+					id VARCHAR(255) PRIMARY KEY,
+					profile_id VARCHAR(255) NOT NULL,
+					name VARCHAR(255) NOT NULL,
+					currency VARCHAR(3) NOT NULL,
+					opening_balance INT NOT NULL DEFAULT 0,
+					closed BOOLEAN NOT NULL DEFAULT 0,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS accounts_profile_id_name ON accounts (profile_id, name);`,
+				`ALTER TABLE transactions ADD COLUMN account_id VARCHAR(255) NOT NULL DEFAULT '';`,
+				`ALTER TABLE transactions ADD COLUMN transfer_id VARCHAR(255) NOT NULL DEFAULT '';`,
+				`CREATE INDEX IF NOT EXISTS transactions_account_id ON transactions (account_id);`,
+				`CREATE INDEX IF NOT EXISTS transactions_transfer_id ON transactions (transfer_id);`,
+			)
+		},
+	},
+	{
+		version:     7,
+		description: "make profile names unique",
+		up: func(tx *sql.Tx) error {
+			// Profiles that share a name with an older profile are renamed so that the index can be created.
+			if _, err := tx.Exec(`UPDATE profiles SET name = name || ' (' || id || ')'
+				WHERE rowid NOT IN (SELECT MIN(rowid) FROM profiles GROUP BY name);`); err != nil {
+				return err
+			}
+			return execAll(tx,
+				`DROP INDEX IF EXISTS profiles_name;`,
+				`CREATE UNIQUE INDEX profiles_name ON profiles (name);`,
+			)
+		},
+	},
+	{
+		version:     8,
+		description: "create tag rules",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS tag_rules (
+					id VARCHAR(255) PRIMARY KEY,
+					profile_id VARCHAR(255) NOT NULL,
+					position INT NOT NULL,
+					label_contains VARCHAR(255) NOT NULL DEFAULT '',
+					label_pattern VARCHAR(255) NOT NULL DEFAULT '',
+					min_amount INT NULL,
+					max_amount INT NULL,
+					currency VARCHAR(3) NOT NULL DEFAULT '',
+					account_id VARCHAR(255) NOT NULL DEFAULT '',
+					stop BOOLEAN NOT NULL DEFAULT 0,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);`,
+				`CREATE INDEX IF NOT EXISTS tag_rules_profile_id ON tag_rules (profile_id);`,
+				`CREATE TABLE IF NOT EXISTS tag_rule_tags (
+					tag_rule_id VARCHAR(255),
+					tag VARCHAR(255),
+					position INT NOT NULL,
+					PRIMARY KEY (tag_rule_id, tag)
+				);`,
+			)
+		},
+	},
+	{
+		version:     9,
+		description: "add profile tag case",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`ALTER TABLE profiles ADD COLUMN tag_case VARCHAR(16) NOT NULL DEFAULT 'lower';`,
+			)
+		},
+	},
+	{
+		version:     10,
+		description: "create envelopes",
+		up: func(tx *sql.Tx) error {
+			return execAll(tx,
+				`CREATE TABLE IF NOT EXISTS envelopes (
+					id VARCHAR(255) PRIMARY KEY,
+					profile_id VARCHAR(255) NOT NULL,
+					tag VARCHAR(255) NOT NULL,
+					amount INT NOT NULL,
+					currency VARCHAR(3) NOT NULL,
+					period VARCHAR(16) NOT NULL,
+					rollover BOOLEAN NOT NULL DEFAULT 0,
+					start_date DATETIME NOT NULL,
+					created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				);`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS envelopes_profile_id_tag ON envelopes (profile_id, tag);`,
+			)
+		},
+	},
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"database/sql"
-	"fmt"
 	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/errs"
 	"net/http"
@@ -10,17 +9,19 @@ import (
 
 // Profile allows you to load and save a full profile.
 type Profile interface {
-	// Init prepares the repository for use later on.
-	Init() error
-
 	// LoadProfile loads the given profile by id.
 	LoadProfileByID(id string) (*domain.Profile, errs.Error)
 	// LoadProfile loads the given profile by name.
 	LoadProfileByName(name string) (*domain.Profile, errs.Error)
+	// ListProfiles loads all profiles, ordered by name.
+	ListProfiles() ([]*domain.Profile, errs.Error)
 	// CreateProfile creates the given profile.
 	CreateProfile(profile *domain.Profile) errs.Error
 	// UpdateProfile updates the given profile.
 	UpdateProfile(profile *domain.Profile) errs.Error
+	// DeleteProfile deletes the given profile.
+	// It does not delete anything belonging to the profile.
+	DeleteProfile(id string) errs.Error
 }
 
 func NewSQLiteProfile(db *sql.DB) Profile {
@@ -31,33 +32,17 @@ func NewSQLiteProfile(db *sql.DB) Profile {
 
 // sqliteProfile implements Profile
 type sqliteProfile struct {
-	db *sql.DB
-}
-
-// Init prepares the repository for use later on.
-func (x *sqliteProfile) Init() error {
-	query := `BEGIN;
-	CREATE TABLE IF NOT EXISTS profiles (
-		id VARCHAR(255) PRIMARY KEY,
-		name VARCHAR(255) NOT NULL
-	);
-	CREATE INDEX IF NOT EXISTS profiles_name ON profiles (name);
-	COMMIT;`
-	_, err := x.db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("could not create profiles table: %s", err)
-	}
-	return nil
+	db querier
 }
 
 // LoadProfile loads the given profile by id.
 func (x *sqliteProfile) LoadProfileByID(id string) (*domain.Profile, errs.Error) {
-	query := `SELECT id, name FROM profiles WHERE id = ?;`
+	query := `SELECT id, name, currency, tag_case FROM profiles WHERE id = ?;`
 	row := x.db.QueryRow(query, id)
 
 	res := domain.NewProfile()
 
-	err := row.Scan(&res.ID, &res.Name)
+	err := row.Scan(&res.ID, &res.Name, &res.Currency, &res.TagCase)
 	if err == sql.ErrNoRows {
 		return nil, errs.New().
 			WithCode(errs.ErrUnknownProfile).
@@ -72,12 +57,12 @@ func (x *sqliteProfile) LoadProfileByID(id string) (*domain.Profile, errs.Error)
 
 // LoadProfile loads the given profile by name.
 func (x *sqliteProfile) LoadProfileByName(name string) (*domain.Profile, errs.Error) {
-	query := `SELECT id, name FROM profiles WHERE name = ?;`
+	query := `SELECT id, name, currency, tag_case FROM profiles WHERE name = ?;`
 	row := x.db.QueryRow(query, name)
 
 	res := domain.NewProfile()
 
-	err := row.Scan(&res.ID, &res.Name)
+	err := row.Scan(&res.ID, &res.Name, &res.Currency, &res.TagCase)
 	if err == sql.ErrNoRows {
 		return nil, errs.New().
 			WithCode(errs.ErrUnknownProfile).
@@ -90,10 +75,33 @@ func (x *sqliteProfile) LoadProfileByName(name string) (*domain.Profile, errs.Er
 	return res, nil
 }
 
+// ListProfiles loads all profiles, ordered by name.
+func (x *sqliteProfile) ListProfiles() ([]*domain.Profile, errs.Error) {
+	rows, err := x.db.Query(`SELECT id, name, currency, tag_case FROM profiles ORDER BY name;`)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query profiles: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.Profile, 0)
+
+	for rows.Next() {
+		row := domain.NewProfile()
+		if err := rows.Scan(&row.ID, &row.Name, &row.Currency, &row.TagCase); err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
 // CreateProfile creates the given profile.
 func (x *sqliteProfile) CreateProfile(profile *domain.Profile) errs.Error {
-	query := `INSERT INTO profiles (id, name) VALUES(?, ?);`
-	_, err := x.db.Exec(query, profile.ID, profile.Name)
+	query := `INSERT INTO profiles (id, name, currency, tag_case) VALUES(?, ?, ?, ?);`
+	_, err := x.db.Exec(query, profile.ID, profile.Name, profile.Currency, profile.TagCase)
 	if err != nil {
 		return errs.FromErr(err).PrefixMessage("could not insert row: ")
 	}
@@ -102,10 +110,26 @@ func (x *sqliteProfile) CreateProfile(profile *domain.Profile) errs.Error {
 
 // UpdateProfile updates the given profile.
 func (x *sqliteProfile) UpdateProfile(profile *domain.Profile) errs.Error {
-	query := `UPDATE profiles SET name = ? WHERE id = ?;`
-	_, err := x.db.Exec(query, profile.Name, profile.ID)
+	query := `UPDATE profiles SET name = ?, currency = ?, tag_case = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, profile.Name, profile.Currency, profile.TagCase, profile.ID)
 	if err != nil {
 		return errs.FromErr(err).PrefixMessage("could not update row: ")
 	}
 	return nil
 }
+
+// DeleteProfile deletes the given profile.
+// It does not delete anything belonging to the profile.
+func (x *sqliteProfile) DeleteProfile(id string) errs.Error {
+	res, err := x.db.Exec(`DELETE FROM profiles WHERE id = ?;`, id)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete row: ")
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return errs.New().
+			WithCode(errs.ErrUnknownProfile).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("profile id not found")
+	}
+	return nil
+}
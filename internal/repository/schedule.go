@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"time"
+)
+
+// Schedule allows you to load and save a full schedule.
+type Schedule interface {
+	// LoadScheduleByID loads the given schedule by id.
+	LoadScheduleByID(id string) (*domain.Schedule, errs.Error)
+	// LoadSchedulesByProfileID loads the schedules belonging to the given profile.
+	LoadSchedulesByProfileID(id string) ([]*domain.Schedule, errs.Error)
+	// CreateSchedule creates the given schedule.
+	CreateSchedule(schedule *domain.Schedule) errs.Error
+	// UpdateSchedule updates the given schedule.
+	UpdateSchedule(schedule *domain.Schedule) errs.Error
+	// DeleteSchedulesByProfileID deletes all schedules belonging to the given profile, along with their tags.
+	DeleteSchedulesByProfileID(id string) errs.Error
+
+	// LoadScheduleTagsByID loads the given schedules tags by id.
+	LoadScheduleTagsByID(id string) ([]string, errs.Error)
+	// AddScheduleTags adds the given tags to the given schedule.
+	AddScheduleTags(id string, tags ...string) errs.Error
+	// ClearScheduleTags deletes all tags for the given schedule.
+	ClearScheduleTags(id string) errs.Error
+}
+
+func NewSQLiteSchedule(db *sql.DB) Schedule {
+	return &sqliteSchedule{
+		db: db,
+	}
+}
+
+// sqliteSchedule implements Schedule
+type sqliteSchedule struct {
+	db querier
+}
+
+const scheduleColumns = `id, profile_id, label, amount, currency, frequency, frequency_interval, day_of_month, start_date, end_date, paused, created_at, updated_at`
+
+// scanSchedule scans a single schedule row.
+func scanSchedule(row scanner) (*domain.Schedule, error) {
+	res := domain.NewSchedule()
+	var end *time.Time
+	err := row.Scan(&res.ID, &res.ProfileID, &res.Label, &res.Amount, &res.Currency, &res.Frequency, &res.Interval,
+		&res.DayOfMonth, &res.Start, &end, &res.Paused, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if end != nil {
+		res.End = *end
+	}
+	return res, nil
+}
+
+// nullTime returns nil for a zero time so that it is stored as NULL.
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// LoadScheduleByID loads the given schedule by id.
+func (x *sqliteSchedule) LoadScheduleByID(id string) (*domain.Schedule, errs.Error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = ?;`
+	row := x.db.QueryRow(query, id)
+
+	res, err := scanSchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownSchedule).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("schedule id not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadSchedulesByProfileID loads the schedules belonging to the given profile.
+func (x *sqliteSchedule) LoadSchedulesByProfileID(id string) ([]*domain.Schedule, errs.Error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE profile_id = ? ORDER BY start_date, created_at;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query schedules: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.Schedule, 0)
+
+	for rows.Next() {
+		row, err := scanSchedule(rows)
+		if err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// CreateSchedule creates the given schedule.
+func (x *sqliteSchedule) CreateSchedule(schedule *domain.Schedule) errs.Error {
+	query := `INSERT INTO schedules (` + scheduleColumns + `) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := x.db.Exec(query, schedule.ID, schedule.ProfileID, schedule.Label, schedule.Amount, schedule.Currency,
+		schedule.Frequency, schedule.Interval, schedule.DayOfMonth, schedule.Start.UTC(), nullTime(schedule.End),
+		schedule.Paused, schedule.CreatedAt.UTC(), schedule.UpdatedAt.UTC())
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not insert row: ")
+	}
+	return nil
+}
+
+// UpdateSchedule updates the given schedule.
+func (x *sqliteSchedule) UpdateSchedule(schedule *domain.Schedule) errs.Error {
+	query := `UPDATE schedules SET profile_id = ?, label = ?, amount = ?, currency = ?, frequency = ?, frequency_interval = ?,
+		day_of_month = ?, start_date = ?, end_date = ?, paused = ?, updated_at = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, schedule.ProfileID, schedule.Label, schedule.Amount, schedule.Currency,
+		schedule.Frequency, schedule.Interval, schedule.DayOfMonth, schedule.Start.UTC(), nullTime(schedule.End),
+		schedule.Paused, schedule.UpdatedAt.UTC(), schedule.ID)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not update row: ")
+	}
+	return nil
+}
+
+// DeleteSchedulesByProfileID deletes all schedules belonging to the given profile, along with their tags.
+func (x *sqliteSchedule) DeleteSchedulesByProfileID(id string) errs.Error {
+	query := `DELETE FROM schedule_tags WHERE schedule_id IN (SELECT id FROM schedules WHERE profile_id = ?);`
+	if _, err := x.db.Exec(query, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete schedule tags: ")
+	}
+	if _, err := x.db.Exec(`DELETE FROM schedules WHERE profile_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete rows: ")
+	}
+	return nil
+}
+
+// LoadScheduleTagsByID loads the given schedules tags by id.
+func (x *sqliteSchedule) LoadScheduleTagsByID(id string) ([]string, errs.Error) {
+	tags := make([]string, 0)
+	query := `SELECT tag FROM schedule_tags WHERE schedule_id = ?;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return tags, errs.New().
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query schedule tags: ")
+	}
+	defer rows.Close()
+
+	var tag string
+	for rows.Next() {
+		if err := rows.Scan(&tag); err != nil {
+			return tags, errs.New().
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not scan tag: ")
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// AddScheduleTags adds the given tags to the given schedule.
+func (x *sqliteSchedule) AddScheduleTags(id string, tags ...string) errs.Error {
+	if len(tags) > 0 {
+		stmt, err := x.db.Prepare(`INSERT INTO schedule_tags (schedule_id, tag) VALUES(?, ?);`)
+		if err != nil {
+			return errs.New().
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not prepare add tag stmt: ")
+		}
+		defer stmt.Close()
+
+		for _, t := range tags {
+			_, err = stmt.Exec(id, t)
+			if err != nil {
+				return errs.New().
+					WithStatusCode(http.StatusInternalServerError).
+					PrefixMessage("could not exec add tag stmt: ")
+			}
+		}
+	}
+	return nil
+}
+
+// ClearScheduleTags deletes all tags for the given schedule.
+func (x *sqliteSchedule) ClearScheduleTags(id string) errs.Error {
+	_, err := x.db.Exec(`DELETE FROM schedule_tags WHERE schedule_id = ?;`, id)
+	if err != nil {
+		return errs.New().
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not delete schedule tags: ")
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+	"strings"
+)
+
+// Tag allows you to work with the tags used across a profile.
+// Methods that take a tag also apply to its descendants, so transport includes transport/rail.
+type Tag interface {
+	// CountTaggedTransactions returns the number of transactions in the given profile with the given tag.
+	CountTaggedTransactions(profileID string, tag string) (int, errs.Error)
+	// RenameTag replaces the given tag with another in the transactions, schedules and tag rules of the
+	// given profile, and returns the number of transactions changed.
+	// Anything that already has the new tag keeps a single copy of it.
+	RenameTag(profileID string, from string, to string) (int, errs.Error)
+	// DeleteTag removes the given tag from the transactions, schedules and tag rules of the given profile,
+	// and returns the number of transactions changed.
+	DeleteTag(profileID string, tag string) (int, errs.Error)
+	// LoadTagUsage loads every tag used in the given profile along with the number of transactions,
+	// schedules and tag rules that use it, ordered by tag.
+	// Unlike the other methods, each tag is counted on its own rather than with its descendants.
+	LoadTagUsage(profileID string) ([]*domain.TagUsage, errs.Error)
+}
+
+func NewSQLiteTag(db *sql.DB) Tag {
+	return &sqliteTag{
+		db: db,
+	}
+}
+
+// sqliteTag implements Tag
+type sqliteTag struct {
+	db querier
+}
+
+// tagTable describes a table that links tags to the objects in a profile.
+type tagTable struct {
+	// name is the name of the table.
+	name string
+	// owner is the column containing the id of the object.
+	owner string
+	// owners selects the ids of the objects in a profile.
+	owners string
+	// positioned is true if the table has a position column that orders the tags.
+	positioned bool
+}
+
+// columns returns the columns of the table.
+func (x tagTable) columns() string {
+	if x.positioned {
+		return x.owner + ", tag, position"
+	}
+	return x.owner + ", tag"
+}
+
+// tagTables contains each table that holds tags.
+var tagTables = []tagTable{
+	{name: "transaction_tags", owner: "transaction_id", owners: `SELECT id FROM transactions WHERE profile_id = ?`},
+	{name: "schedule_tags", owner: "schedule_id", owners: `SELECT id FROM schedules WHERE profile_id = ?`},
+	{name: "tag_rule_tags", owner: "tag_rule_id", owners: `SELECT id FROM tag_rules WHERE profile_id = ?`, positioned: true},
+}
+
+// tagWithinWhere returns a condition that matches the given tag and its descendants.
+// substr is used rather than LIKE so that % and _ in the tag are not treated as wildcards.
+func tagWithinWhere(tag string) (string, []interface{}) {
+	prefix := tag + domain.TagSeparator
+	return `(tag = ? OR substr(tag, 1, length(?)) = ?)`, []interface{}{tag, prefix, prefix}
+}
+
+// tagsWithinWhere returns a condition that matches any of the given tags and their descendants.
+func tagsWithinWhere(tags []string) (string, []interface{}) {
+	wheres := make([]string, len(tags))
+	args := make([]interface{}, 0, len(tags)*3)
+	for i, tag := range tags {
+		where, whereArgs := tagWithinWhere(tag)
+		wheres[i] = where
+		args = append(args, whereArgs...)
+	}
+	return `(` + strings.Join(wheres, ` OR `) + `)`, args
+}
+
+// CountTaggedTransactions returns the number of transactions in the given profile with the given tag.
+func (x *sqliteTag) CountTaggedTransactions(profileID string, tag string) (int, errs.Error) {
+	where, args := tagWithinWhere(tag)
+	query := `SELECT COUNT(DISTINCT transaction_id) FROM transaction_tags
+		WHERE transaction_id IN (SELECT id FROM transactions WHERE profile_id = ?) AND ` + where + `;`
+	var count int
+	if err := x.db.QueryRow(query, append([]interface{}{profileID}, args...)...).Scan(&count); err != nil {
+		return 0, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not count tagged transactions: ")
+	}
+	return count, nil
+}
+
+// RenameTag replaces the given tag with another in the transactions, schedules and tag rules of the
+// given profile, and returns the number of transactions changed.
+// Anything that already has the new tag keeps a single copy of it.
+func (x *sqliteTag) RenameTag(profileID string, from string, to string) (int, errs.Error) {
+	changed := 0
+	for _, table := range tagTables {
+		n, err := x.renameTagIn(table, profileID, from, to)
+		if err != nil {
+			return 0, err
+		}
+		if table.name == "transaction_tags" {
+			changed = n
+		}
+	}
+	return changed, nil
+}
+
+// DeleteTag removes the given tag from the transactions, schedules and tag rules of the given profile,
+// and returns the number of transactions changed.
+func (x *sqliteTag) DeleteTag(profileID string, tag string) (int, errs.Error) {
+	changed, err := x.CountTaggedTransactions(profileID, tag)
+	if err != nil {
+		return 0, err
+	}
+	where, whereArgs := tagWithinWhere(tag)
+	args := append([]interface{}{profileID}, whereArgs...)
+	for _, table := range tagTables {
+		query := `DELETE FROM ` + table.name + ` WHERE ` + table.owner + ` IN (` + table.owners + `) AND ` + where + `;`
+		if _, err := x.db.Exec(query, args...); err != nil {
+			return 0, errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not delete " + table.name + ": ")
+		}
+	}
+	return changed, nil
+}
+
+// LoadTagUsage loads every tag used in the given profile along with the number of transactions,
+// schedules and tag rules that use it, ordered by tag.
+func (x *sqliteTag) LoadTagUsage(profileID string) ([]*domain.TagUsage, errs.Error) {
+	// columns contains a count for each of tagTables, in the same order.
+	columns := []string{"transactions", "schedules", "tag_rules"}
+	selects := make([]string, len(tagTables))
+	args := make([]interface{}, len(tagTables))
+	for i, table := range tagTables {
+		counts := make([]string, len(columns))
+		for j, column := range columns {
+			count := "0"
+			if i == j {
+				count = "COUNT(*)"
+			}
+			counts[j] = count + " AS " + column
+		}
+		selects[i] = `SELECT tag, ` + strings.Join(counts, ", ") + ` FROM ` + table.name +
+			` WHERE ` + table.owner + ` IN (` + table.owners + `) GROUP BY tag`
+		args[i] = profileID
+	}
+	query := `SELECT tag, SUM(transactions), SUM(schedules), SUM(tag_rules) FROM (` +
+		strings.Join(selects, ` UNION ALL `) + `) GROUP BY tag ORDER BY tag;`
+
+	rows, err := x.db.Query(query, args...)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query tag usage: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.TagUsage, 0)
+	for rows.Next() {
+		usage := &domain.TagUsage{}
+		if err := rows.Scan(&usage.Tag, &usage.Transactions, &usage.Schedules, &usage.TagRules); err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, usage)
+	}
+	return res, nil
+}
+
+// renameTagIn replaces the given tag with another in the given table, and returns the number of objects changed.
+// Every matching row is deleted before the renamed rows are inserted, so that renaming a tag into or out of
+// its own subtree cannot conflict with rows that have not been renamed yet.
+func (x *sqliteTag) renameTagIn(table tagTable, profileID string, from string, to string) (int, errs.Error) {
+	where, whereArgs := tagWithinWhere(from)
+	where = ` WHERE ` + table.owner + ` IN (` + table.owners + `) AND ` + where
+	args := append([]interface{}{profileID}, whereArgs...)
+
+	rows, err := x.db.Query(`SELECT `+table.columns()+` FROM `+table.name+where+`;`, args...)
+	if err != nil {
+		return 0, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query " + table.name + ": ")
+	}
+	renamed := make([][]interface{}, 0)
+	owners := make(map[string]struct{})
+	for rows.Next() {
+		var owner, tag string
+		var position int
+		dest := []interface{}{&owner, &tag}
+		if table.positioned {
+			dest = append(dest, &position)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			_ = rows.Close()
+			return 0, errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not scan tag: ")
+		}
+		tag, _ = domain.RenameTag(tag, from, to)
+		row := []interface{}{owner, tag}
+		if table.positioned {
+			row = append(row, position)
+		}
+		renamed = append(renamed, row)
+		owners[owner] = struct{}{}
+	}
+	_ = rows.Close()
+	if len(renamed) == 0 {
+		return 0, nil
+	}
+
+	if _, err := x.db.Exec(`DELETE FROM `+table.name+where+`;`, args...); err != nil {
+		return 0, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not delete " + table.name + ": ")
+	}
+
+	stmt, err := x.db.Prepare(`INSERT OR IGNORE INTO ` + table.name + ` (` + table.columns() + `) VALUES(` +
+		placeholders(len(renamed[0])) + `);`)
+	if err != nil {
+		return 0, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not prepare rename tag stmt: ")
+	}
+	defer stmt.Close()
+
+	for _, row := range renamed {
+		if _, err := stmt.Exec(row...); err != nil {
+			return 0, errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not exec rename tag stmt: ")
+		}
+	}
+	return len(owners), nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"net/http"
+)
+
+// TagRule allows you to load and save tag rules.
+type TagRule interface {
+	// LoadTagRuleByID loads the given tag rule by id.
+	LoadTagRuleByID(id string) (*domain.TagRule, errs.Error)
+	// LoadTagRulesByProfileID loads the tag rules belonging to the given profile, ordered by position.
+	LoadTagRulesByProfileID(id string) ([]*domain.TagRule, errs.Error)
+	// CreateTagRule creates the given tag rule.
+	CreateTagRule(rule *domain.TagRule) errs.Error
+	// UpdateTagRule updates the given tag rule.
+	UpdateTagRule(rule *domain.TagRule) errs.Error
+	// DeleteTagRule deletes the given tag rule along with its tags.
+	DeleteTagRule(id string) errs.Error
+	// DeleteTagRulesByProfileID deletes all tag rules belonging to the given profile, along with their tags.
+	DeleteTagRulesByProfileID(id string) errs.Error
+	// LoadTagRuleTagsByID loads the given tag rules tags by id, in the order they were added.
+	LoadTagRuleTagsByID(id string) ([]string, errs.Error)
+	// AddTagRuleTags adds the given tags to the given tag rule.
+	AddTagRuleTags(id string, tags ...string) errs.Error
+	// ClearTagRuleTags deletes all tags for the given tag rule.
+	ClearTagRuleTags(id string) errs.Error
+}
+
+func NewSQLiteTagRule(db *sql.DB) TagRule {
+	return &sqliteTagRule{
+		db: db,
+	}
+}
+
+// sqliteTagRule implements TagRule
+type sqliteTagRule struct {
+	db querier
+}
+
+const tagRuleColumns = `id, profile_id, position, label_contains, label_pattern, min_amount, max_amount, currency,
+	account_id, stop, created_at, updated_at`
+
+// scanTagRule scans a single tag rule row.
+func scanTagRule(row scanner) (*domain.TagRule, error) {
+	res := domain.NewTagRule()
+	err := row.Scan(&res.ID, &res.ProfileID, &res.Position, &res.LabelContains, &res.LabelPattern, &res.MinAmount,
+		&res.MaxAmount, &res.Currency, &res.AccountID, &res.Stop, &res.CreatedAt, &res.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// LoadTagRuleByID loads the given tag rule by id.
+func (x *sqliteTagRule) LoadTagRuleByID(id string) (*domain.TagRule, errs.Error) {
+	query := `SELECT ` + tagRuleColumns + ` FROM tag_rules WHERE id = ?;`
+	res, err := scanTagRule(x.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, errs.New().
+			WithCode(errs.ErrUnknownTagRule).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("tag rule id not found")
+	}
+	if err != nil {
+		return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+	}
+	return res, nil
+}
+
+// LoadTagRulesByProfileID loads the tag rules belonging to the given profile, ordered by position.
+func (x *sqliteTagRule) LoadTagRulesByProfileID(id string) ([]*domain.TagRule, errs.Error) {
+	query := `SELECT ` + tagRuleColumns + ` FROM tag_rules WHERE profile_id = ? ORDER BY position, created_at;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query tag rules: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.TagRule, 0)
+
+	for rows.Next() {
+		row, err := scanTagRule(rows)
+		if err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// CreateTagRule creates the given tag rule.
+func (x *sqliteTagRule) CreateTagRule(rule *domain.TagRule) errs.Error {
+	query := `INSERT INTO tag_rules (` + tagRuleColumns + `) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := x.db.Exec(query, rule.ID, rule.ProfileID, rule.Position, rule.LabelContains, rule.LabelPattern,
+		rule.MinAmount, rule.MaxAmount, rule.Currency, rule.AccountID, rule.Stop, rule.CreatedAt.UTC(),
+		rule.UpdatedAt.UTC())
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not insert row: ")
+	}
+	return nil
+}
+
+// UpdateTagRule updates the given tag rule.
+func (x *sqliteTagRule) UpdateTagRule(rule *domain.TagRule) errs.Error {
+	query := `UPDATE tag_rules SET position = ?, label_contains = ?, label_pattern = ?, min_amount = ?, max_amount = ?,
+		currency = ?, account_id = ?, stop = ?, updated_at = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, rule.Position, rule.LabelContains, rule.LabelPattern, rule.MinAmount, rule.MaxAmount,
+		rule.Currency, rule.AccountID, rule.Stop, rule.UpdatedAt.UTC(), rule.ID)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not update row: ")
+	}
+	return nil
+}
+
+// DeleteTagRule deletes the given tag rule along with its tags.
+func (x *sqliteTagRule) DeleteTagRule(id string) errs.Error {
+	if err := x.ClearTagRuleTags(id); err != nil {
+		return err
+	}
+	res, err := x.db.Exec(`DELETE FROM tag_rules WHERE id = ?;`, id)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete row: ")
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return errs.New().
+			WithCode(errs.ErrUnknownTagRule).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("tag rule id not found")
+	}
+	return nil
+}
+
+// DeleteTagRulesByProfileID deletes all tag rules belonging to the given profile, along with their tags.
+func (x *sqliteTagRule) DeleteTagRulesByProfileID(id string) errs.Error {
+	query := `DELETE FROM tag_rule_tags WHERE tag_rule_id IN (SELECT id FROM tag_rules WHERE profile_id = ?);`
+	if _, err := x.db.Exec(query, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete tag rule tags: ")
+	}
+	if _, err := x.db.Exec(`DELETE FROM tag_rules WHERE profile_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete rows: ")
+	}
+	return nil
+}
+
+// LoadTagRuleTagsByID loads the given tag rules tags by id, in the order they were added.
+func (x *sqliteTagRule) LoadTagRuleTagsByID(id string) ([]string, errs.Error) {
+	tags := make([]string, 0)
+	query := `SELECT tag FROM tag_rule_tags WHERE tag_rule_id = ? ORDER BY position;`
+	rows, err := x.db.Query(query, id)
+	if err != nil {
+		return tags, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query tag rule tags: ")
+	}
+	defer rows.Close()
+
+	var tag string
+	for rows.Next() {
+		if err := rows.Scan(&tag); err != nil {
+			return tags, errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not scan tag: ")
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// AddTagRuleTags adds the given tags to the given tag rule.
+// Tags are positioned after any existing tags.
+func (x *sqliteTagRule) AddTagRuleTags(id string, tags ...string) errs.Error {
+	if len(tags) > 0 {
+		var position int
+		err := x.db.QueryRow(`SELECT COUNT(*) FROM tag_rule_tags WHERE tag_rule_id = ?;`, id).Scan(&position)
+		if err != nil {
+			return errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not count tag rule tags: ")
+		}
+
+		stmt, err := x.db.Prepare(`INSERT INTO tag_rule_tags (tag_rule_id, tag, position) VALUES(?, ?, ?);`)
+		if err != nil {
+			return errs.FromErr(err).
+				WithStatusCode(http.StatusInternalServerError).
+				PrefixMessage("could not prepare add tag stmt: ")
+		}
+		defer stmt.Close()
+
+		for _, t := range tags {
+			position++
+			_, err = stmt.Exec(id, t, position)
+			if err != nil {
+				return errs.FromErr(err).
+					WithStatusCode(http.StatusInternalServerError).
+					PrefixMessage("could not exec add tag stmt: ")
+			}
+		}
+	}
+	return nil
+}
+
+// ClearTagRuleTags deletes all tags for the given tag rule.
+func (x *sqliteTagRule) ClearTagRuleTags(id string) errs.Error {
+	_, err := x.db.Exec(`DELETE FROM tag_rule_tags WHERE tag_rule_id = ?;`, id)
+	if err != nil {
+		return errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not delete tag rule tags: ")
+	}
+	return nil
+}
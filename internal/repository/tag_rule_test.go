@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSQLiteTagRule(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	repo := repository.NewSQLiteTagRule(db)
+
+	min := int64(-5000)
+	now := time.Now().UTC().Truncate(time.Second)
+	rules := []*domain.TagRule{
+		{ID: "rul:1", ProfileID: "pro:1", Position: 2, LabelContains: "coffee", MinAmount: &min, Currency: "GBP",
+			CreatedAt: now, UpdatedAt: now},
+		{ID: "rul:2", ProfileID: "pro:1", Position: 1, LabelPattern: "^Netflix", AccountID: "acc:1", Stop: true,
+			CreatedAt: now, UpdatedAt: now},
+		{ID: "rul:3", ProfileID: "pro:2", Position: 1, LabelContains: "rent", CreatedAt: now, UpdatedAt: now},
+	}
+	for _, r := range rules {
+		if err := repo.CreateTagRule(r); err != nil {
+			t.Fatalf("could not create rule: %s", err)
+		}
+	}
+	if err := repo.AddTagRuleTags("rul:1", "food", "coffee"); err != nil {
+		t.Fatalf("could not add tags: %s", err)
+	}
+	if err := repo.AddTagRuleTags("rul:1", "drinks"); err != nil {
+		t.Fatalf("could not add tags: %s", err)
+	}
+
+	t.Run("LoadTagRuleByID", func(t *testing.T) {
+		got, err := repo.LoadTagRuleByID("rul:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if got.MinAmount == nil || *got.MinAmount != min || got.MaxAmount != nil {
+			t.Errorf("unexpected amounts: %v, %v", got.MinAmount, got.MaxAmount)
+		}
+		if got.LabelContains != "coffee" || got.Currency != "GBP" || got.Position != 2 || got.Stop {
+			t.Errorf("unexpected rule: %+v", got)
+		}
+
+		_, err = repo.LoadTagRuleByID("rul:unknown")
+		if err == nil || err.Code() != errs.ErrUnknownTagRule {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownTagRule, err)
+		}
+	})
+
+	t.Run("LoadTagRulesByProfileID", func(t *testing.T) {
+		got, err := repo.LoadTagRulesByProfileID("pro:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		ids := make([]string, len(got))
+		for i, r := range got {
+			ids[i] = r.ID
+		}
+		if exp := []string{"rul:2", "rul:1"}; !reflect.DeepEqual(exp, ids) {
+			t.Errorf("expected rules %v, got %v", exp, ids)
+		}
+	})
+
+	t.Run("LoadTagRuleTagsByID", func(t *testing.T) {
+		got, err := repo.LoadTagRuleTagsByID("rul:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if exp := []string{"food", "coffee", "drinks"}; !reflect.DeepEqual(exp, got) {
+			t.Errorf("expected tags %v, got %v", exp, got)
+		}
+	})
+
+	t.Run("DeleteTagRule", func(t *testing.T) {
+		if err := repo.DeleteTagRule("rul:1"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		tags, err := repo.LoadTagRuleTagsByID("rul:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tags) != 0 {
+			t.Errorf("expected tags to be deleted, got %v", tags)
+		}
+		err = repo.DeleteTagRule("rul:1")
+		if err == nil || err.Code() != errs.ErrUnknownTagRule {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownTagRule, err)
+		}
+	})
+}
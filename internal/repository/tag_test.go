@@ -0,0 +1,197 @@
+package repository_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSQLiteTag_RenameTag(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	transactionRepo := repository.NewSQLiteTransaction(db)
+	scheduleRepo := repository.NewSQLiteSchedule(db)
+	tagRuleRepo := repository.NewSQLiteTagRule(db)
+	tagRepo := repository.NewSQLiteTag(db)
+
+	now := time.Now().UTC()
+	transactions := map[string][]string{
+		"tra:1": {"transport"},
+		"tra:2": {"transport/rail", "travel"},
+		"tra:3": {"transport/rail/tube", "transport_other"},
+		"tra:4": {"travel/rail", "transport/rail"},
+		"tra:5": {"food"},
+	}
+	for id, tags := range transactions {
+		profileID := "pro:1"
+		if id == "tra:5" {
+			profileID = "pro:2"
+			tags = []string{"transport"}
+		}
+		transaction := domain.NewTransaction().WithID(id).WithProfileID(profileID).WithAmount(-100).WithDate(now)
+		if err := transactionRepo.CreateTransaction(transaction); err != nil {
+			t.Fatalf("could not create transaction: %s", err)
+		}
+		if err := transactionRepo.AddTransactionTags(id, tags...); err != nil {
+			t.Fatalf("could not add tags: %s", err)
+		}
+	}
+
+	schedule := domain.NewSchedule()
+	schedule.ID, schedule.ProfileID, schedule.Start = "sch:1", "pro:1", now
+	if err := scheduleRepo.CreateSchedule(schedule); err != nil {
+		t.Fatalf("could not create schedule: %s", err)
+	}
+	if err := scheduleRepo.AddScheduleTags("sch:1", "transport/rail"); err != nil {
+		t.Fatalf("could not add schedule tags: %s", err)
+	}
+	rule := &domain.TagRule{ID: "rul:1", ProfileID: "pro:1", Position: 1, LabelContains: "train", CreatedAt: now, UpdatedAt: now}
+	if err := tagRuleRepo.CreateTagRule(rule); err != nil {
+		t.Fatalf("could not create rule: %s", err)
+	}
+	if err := tagRuleRepo.AddTagRuleTags("rul:1", "commute", "transport/rail"); err != nil {
+		t.Fatalf("could not add rule tags: %s", err)
+	}
+
+	if count, err := tagRepo.CountTaggedTransactions("pro:1", "transport"); err != nil || count != 4 {
+		t.Errorf("expected 4 transactions tagged transport, got %d: %v", count, err)
+	}
+
+	changed, err := tagRepo.RenameTag("pro:1", "transport", "travel")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := 4; changed != exp {
+		t.Errorf("expected %d transactions changed, got %d", exp, changed)
+	}
+
+	tags := func(id string) []string {
+		tags, err := transactionRepo.LoadTransactionTagsByID(id)
+		if err != nil {
+			t.Fatalf("could not load tags: %s", err)
+		}
+		sort.Strings(tags)
+		return tags
+	}
+	exp := map[string][]string{
+		"tra:1": {"travel"},
+		"tra:2": {"travel", "travel/rail"},
+		"tra:3": {"transport_other", "travel/rail/tube"},
+		// The transaction already had travel/rail, so it keeps a single copy.
+		"tra:4": {"travel/rail"},
+		// Other profiles are not changed.
+		"tra:5": {"transport"},
+	}
+	for id, e := range exp {
+		if got := tags(id); !reflect.DeepEqual(e, got) {
+			t.Errorf("expected %s tags %v, got %v", id, e, got)
+		}
+	}
+
+	if got, _ := scheduleRepo.LoadScheduleTagsByID("sch:1"); !reflect.DeepEqual([]string{"travel/rail"}, got) {
+		t.Errorf("unexpected schedule tags: %v", got)
+	}
+	if got, _ := tagRuleRepo.LoadTagRuleTagsByID("rul:1"); !reflect.DeepEqual([]string{"commute", "travel/rail"}, got) {
+		t.Errorf("unexpected rule tags: %v", got)
+	}
+
+	// Renaming a tag into its own parent merges the subtree.
+	if _, err := tagRepo.RenameTag("pro:1", "travel/rail", "travel"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got := tags("tra:3"); !reflect.DeepEqual([]string{"transport_other", "travel/tube"}, got) {
+		t.Errorf("unexpected tags after merging into parent: %v", got)
+	}
+	if got := tags("tra:2"); !reflect.DeepEqual([]string{"travel"}, got) {
+		t.Errorf("unexpected tags after merging into parent: %v", got)
+	}
+}
+
+func TestSQLiteTag_DeleteTag(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	transactionRepo := repository.NewSQLiteTransaction(db)
+	scheduleRepo := repository.NewSQLiteSchedule(db)
+	tagRepo := repository.NewSQLiteTag(db)
+
+	now := time.Now().UTC()
+	transactions := map[string][]string{
+		"tra:1": {"Travel", "travel"},
+		"tra:2": {"travel/rail", "food"},
+		"tra:3": {"travel "},
+	}
+	for id, tags := range transactions {
+		transaction := domain.NewTransaction().WithID(id).WithProfileID("pro:1").WithAmount(-100).WithDate(now)
+		if err := transactionRepo.CreateTransaction(transaction); err != nil {
+			t.Fatalf("could not create transaction: %s", err)
+		}
+		if err := transactionRepo.AddTransactionTags(id, tags...); err != nil {
+			t.Fatalf("could not add tags: %s", err)
+		}
+	}
+	schedule := domain.NewSchedule()
+	schedule.ID, schedule.ProfileID, schedule.Start = "sch:1", "pro:1", now
+	if err := scheduleRepo.CreateSchedule(schedule); err != nil {
+		t.Fatalf("could not create schedule: %s", err)
+	}
+	if err := scheduleRepo.AddScheduleTags("sch:1", "travel"); err != nil {
+		t.Fatalf("could not add schedule tags: %s", err)
+	}
+
+	usage, err := tagRepo.LoadTagUsage("pro:1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expUsage := []*domain.TagUsage{
+		{Tag: "Travel", Transactions: 1},
+		{Tag: "food", Transactions: 1},
+		{Tag: "travel", Transactions: 1, Schedules: 1},
+		{Tag: "travel ", Transactions: 1},
+		{Tag: "travel/rail", Transactions: 1},
+	}
+	if !reflect.DeepEqual(expUsage, usage) {
+		t.Errorf("unexpected usage")
+		for _, u := range usage {
+			t.Logf("%+v", u)
+		}
+	}
+
+	changed, err := tagRepo.DeleteTag("pro:1", "travel")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := 2; changed != exp {
+		t.Errorf("expected %d transactions changed, got %d", exp, changed)
+	}
+
+	exp := map[string][]string{
+		"tra:1": {"Travel"},
+		"tra:2": {"food"},
+		"tra:3": {"travel "},
+	}
+	for id, e := range exp {
+		got, err := transactionRepo.LoadTransactionTagsByID(id)
+		if err != nil {
+			t.Fatalf("could not load tags: %s", err)
+		}
+		if !reflect.DeepEqual(e, got) {
+			t.Errorf("expected %s tags %v, got %v", id, e, got)
+		}
+	}
+	if got, _ := scheduleRepo.LoadScheduleTagsByID("sch:1"); len(got) != 0 {
+		t.Errorf("expected schedule tags to be deleted, got %v", got)
+	}
+}
@@ -2,25 +2,34 @@ package repository
 
 import (
 	"database/sql"
-	"fmt"
 	"github.com/tomwright/finance-planner/internal/application/domain"
 	"github.com/tomwright/finance-planner/internal/errs"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 // Transaction allows you to load and save a full transaction.
 type Transaction interface {
-	// Init prepares the repository for use later on.
-	Init() error
-
 	// LoadTransactionByID loads the given transaction by id.
 	LoadTransactionByID(id string) (*domain.Transaction, errs.Error)
-	// LoadTransactionsByProfileID loads the given transaction by id.
-	LoadTransactionsByProfileID(id string) ([]*domain.Transaction, errs.Error)
+	// LoadTransactionsByProfileID loads the transactions belonging to the given profile
+	// that took place within the given date range.
+	LoadTransactionsByProfileID(id string, dateRange domain.DateRange) ([]*domain.Transaction, errs.Error)
+	// LoadTransactionsByFilter loads the transactions belonging to the given profile that match the given filter,
+	// in the order and page given by the filter.
+	// The tags of the transactions are not loaded.
+	LoadTransactionsByFilter(id string, filter domain.TransactionFilter) ([]*domain.Transaction, errs.Error)
+	// LoadTransactionsByTransferID loads both transactions belonging to the given transfer.
+	LoadTransactionsByTransferID(id string) ([]*domain.Transaction, errs.Error)
 	// CreateTransaction creates the given transaction.
 	CreateTransaction(transaction *domain.Transaction) errs.Error
 	// UpdateTransaction updates the given transaction.
 	UpdateTransaction(transaction *domain.Transaction) errs.Error
+	// DeleteTransaction deletes the given transaction and its tags.
+	DeleteTransaction(id string) errs.Error
+	// DeleteTransactionsByProfileID deletes all transactions belonging to the given profile, along with their tags.
+	DeleteTransactionsByProfileID(id string) errs.Error
 
 	// LoadTransactionTagsByID loads the given transactions tags by id.
 	LoadTransactionTagsByID(id string) ([]string, errs.Error)
@@ -38,54 +47,17 @@ func NewSQLiteTransaction(db *sql.DB) Transaction {
 
 // sqliteTransaction implements Transaction
 type sqliteTransaction struct {
-	db *sql.DB
-}
-
-// Init prepares the repository for use later on.
-func (x *sqliteTransaction) Init() error {
-	// Create transactions table
-	query := `BEGIN;
-	CREATE TABLE IF NOT EXISTS transactions (
-		id VARCHAR(255) PRIMARY KEY,
-		profile_id VARCHAR(255),
-		label VARCHAR(255),
-		amount INT
-	);
-	CREATE INDEX IF NOT EXISTS transactions_profile_id ON transactions (profile_id);
-	CREATE INDEX IF NOT EXISTS transactions_label ON transactions (label);
-	CREATE INDEX IF NOT EXISTS transactions_amount ON transactions (amount);
-	COMMIT;`
-	_, err := x.db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("could not create transactions table: %s", err)
-	}
-
-	// Create transaction_tags table
-	query = `BEGIN;
-	CREATE TABLE IF NOT EXISTS transaction_tags (
-		transaction_id VARCHAR(255),
-		tag VARCHAR(255),
-		PRIMARY KEY (transaction_id, tag)
-	);
-	CREATE INDEX IF NOT EXISTS transaction_tags_transaction_id ON transaction_tags (transaction_id);
-	CREATE INDEX IF NOT EXISTS transaction_tags_tag ON transaction_tags (tag);
-	COMMIT;`
-	_, err = x.db.Exec(query)
-	if err != nil {
-		return fmt.Errorf("could not create transaction_tags table: %s", err)
-	}
-
-	return nil
+	db querier
 }
 
 // LoadTransactionByID loads the given transaction by id.
 func (x *sqliteTransaction) LoadTransactionByID(id string) (*domain.Transaction, errs.Error) {
-	query := `SELECT id, profile_id, label, amount FROM transactions WHERE id = ?;`
+	query := `SELECT id, profile_id, account_id, transfer_id, label, amount, currency, date, created_at, updated_at FROM transactions WHERE id = ?;`
 	row := x.db.QueryRow(query, id)
 
 	res := domain.NewTransaction()
 
-	err := row.Scan(&res.ID, &res.ProfileID, &res.Label, &res.Amount)
+	err := row.Scan(&res.ID, &res.ProfileID, &res.AccountID, &res.TransferID, &res.Label, &res.Amount, &res.Currency, &res.Date, &res.CreatedAt, &res.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, errs.New().
 			WithCode(errs.ErrUnknownTransaction).
@@ -98,12 +70,240 @@ func (x *sqliteTransaction) LoadTransactionByID(id string) (*domain.Transaction,
 	return res, nil
 }
 
-// LoadTransactionsByProfileID loads the given transactions tags by id.
-func (x *sqliteTransaction) LoadTransactionsByProfileID(id string) ([]*domain.Transaction, errs.Error) {
-	query := `SELECT id, profile_id, label, amount FROM transactions WHERE profile_id = ?;`
+// LoadTransactionsByProfileID loads the transactions belonging to the given profile
+// that took place within the given date range.
+func (x *sqliteTransaction) LoadTransactionsByProfileID(id string, dateRange domain.DateRange) ([]*domain.Transaction, errs.Error) {
+	return x.LoadTransactionsByFilter(id, domain.TransactionFilter{DateRange: dateRange})
+}
+
+// LoadTransactionsByFilter loads the transactions belonging to the given profile that match the given filter,
+// in the order and page given by the filter.
+// The tags of the transactions are not loaded.
+func (x *sqliteTransaction) LoadTransactionsByFilter(id string, filter domain.TransactionFilter) ([]*domain.Transaction, errs.Error) {
+	query := `SELECT id, profile_id, account_id, transfer_id, label, amount, currency, date, created_at, updated_at FROM transactions WHERE profile_id = ?`
+	args := []interface{}{id}
+	if !filter.DateRange.From.IsZero() {
+		query += ` AND date >= ?`
+		args = append(args, domain.TruncateDay(filter.DateRange.From))
+	}
+	if !filter.DateRange.To.IsZero() {
+		query += ` AND date < ?`
+		args = append(args, filter.DateRange.End())
+	}
+	switch filter.Direction {
+	case domain.DirectionIn:
+		query += ` AND amount > 0`
+	case domain.DirectionOut:
+		query += ` AND amount < 0`
+	}
+	if filter.AccountID != "" {
+		query += ` AND account_id = ?`
+		args = append(args, filter.AccountID)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.MatchAllTags {
+			for _, tag := range filter.Tags {
+				where, whereArgs := tagWithinWhere(tag)
+				query += ` AND id IN (SELECT transaction_id FROM transaction_tags WHERE ` + where + `)`
+				args = append(args, whereArgs...)
+			}
+		} else {
+			where, whereArgs := tagsWithinWhere(filter.Tags)
+			query += ` AND id IN (SELECT transaction_id FROM transaction_tags WHERE ` + where + `)`
+			args = append(args, whereArgs...)
+		}
+	}
+	if len(filter.ExcludeTags) > 0 {
+		where, whereArgs := tagsWithinWhere(filter.ExcludeTags)
+		query += ` AND id NOT IN (SELECT transaction_id FROM transaction_tags WHERE ` + where + `)`
+		args = append(args, whereArgs...)
+	}
+	if filter.Untagged {
+		query += ` AND id NOT IN (SELECT transaction_id FROM transaction_tags)`
+	}
+	if filter.LabelContains != "" {
+		// instr is used rather than LIKE so that % and _ in the text are not treated as wildcards.
+		query += ` AND instr(lower(label), lower(?)) > 0`
+		args = append(args, filter.LabelContains)
+	}
+	if filter.MinAmount.Amount != 0 {
+		bound, boundArgs := amountBound(filter.MinAmount)
+		query += ` AND abs(amount) >= ` + bound
+		args = append(args, boundArgs...)
+	}
+	if filter.MaxAmount.Amount != 0 {
+		bound, boundArgs := amountBound(filter.MaxAmount)
+		query += ` AND abs(amount) <= ` + bound
+		args = append(args, boundArgs...)
+	}
+	if filter.Query != nil {
+		where, whereArgs := queryWhere(filter.Query.Expr)
+		query += ` AND ` + where
+		args = append(args, whereArgs...)
+	}
+
+	direction := ""
+	if filter.Descending {
+		direction = " DESC"
+	}
+	switch filter.Sort {
+	case domain.TransactionSortAmount:
+		query += ` ORDER BY amount` + direction + `, date, created_at`
+	case domain.TransactionSortLabel:
+		query += ` ORDER BY label` + direction + `, date, created_at`
+	default:
+		query += ` ORDER BY date` + direction + `, created_at`
+	}
+	if filter.Paginated() {
+		limit := -1
+		if filter.Limit > 0 {
+			limit = filter.Limit
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, filter.Offset)
+	}
+	query += `;`
+
+	rows, err := x.db.Query(query, args...)
+	if err != nil {
+		return nil, errs.FromErr(err).
+			WithStatusCode(http.StatusInternalServerError).
+			PrefixMessage("could not query transactions: ")
+	}
+	defer rows.Close()
+
+	res := make([]*domain.Transaction, 0)
+
+	for rows.Next() {
+		row := domain.NewTransaction()
+		err := rows.Scan(&row.ID, &row.ProfileID, &row.AccountID, &row.TransferID, &row.Label, &row.Amount, &row.Currency, &row.Date, &row.CreatedAt, &row.UpdatedAt)
+		if err != nil {
+			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
+		}
+		res = append(res, row)
+	}
+
+	return res, nil
+}
+
+// amountBound returns an SQL expression for the given amount as the same number of major units of the
+// currency of each transaction, along with its arguments.
+func amountBound(bound domain.Money) (string, []interface{}) {
+	byExponent := make(map[int][]interface{})
+	exponents := make([]int, 0)
+	for _, c := range domain.Currencies() {
+		exponent := c.Exponent()
+		if _, ok := byExponent[exponent]; !ok {
+			exponents = append(exponents, exponent)
+		}
+		byExponent[exponent] = append(byExponent[exponent], string(c))
+	}
+	sort.Ints(exponents)
+
+	expr := `CASE`
+	args := make([]interface{}, 0)
+	for _, exponent := range exponents {
+		currencies := byExponent[exponent]
+		expr += ` WHEN currency IN (` + placeholders(len(currencies)) + `) THEN ?`
+		args = append(args, currencies...)
+		args = append(args, bound.MinorUnitsIn(domain.Currency(currencies[0].(string))))
+	}
+	// Unsupported currencies are treated as having 2 decimal places, as in Currency.Exponent.
+	expr += ` ELSE ? END`
+	args = append(args, bound.MinorUnitsIn(""))
+	return expr, args
+}
+
+// queryWhere returns a parameterised SQL condition that matches the same transactions as the given query
+// expression, along with its arguments.
+func queryWhere(expr domain.QueryExpr) (string, []interface{}) {
+	switch e := expr.(type) {
+	case *domain.QueryAnd:
+		left, leftArgs := queryWhere(e.Left)
+		right, rightArgs := queryWhere(e.Right)
+		return `(` + left + ` AND ` + right + `)`, append(leftArgs, rightArgs...)
+	case *domain.QueryOr:
+		left, leftArgs := queryWhere(e.Left)
+		right, rightArgs := queryWhere(e.Right)
+		return `(` + left + ` OR ` + right + `)`, append(leftArgs, rightArgs...)
+	case *domain.QueryNot:
+		where, args := queryWhere(e.Expr)
+		return `NOT ` + where, args
+	case *domain.QueryComparison:
+		return queryComparisonWhere(e)
+	default:
+		// Unknown expressions match nothing rather than everything.
+		return `0`, nil
+	}
+}
+
+// queryOperators maps query operators to SQL operators.
+var queryOperators = map[domain.QueryOperator]string{
+	domain.QueryEqual:          `=`,
+	domain.QueryNotEqual:       `!=`,
+	domain.QueryLess:           `<`,
+	domain.QueryLessOrEqual:    `<=`,
+	domain.QueryGreater:        `>`,
+	domain.QueryGreaterOrEqual: `>=`,
+}
+
+// queryComparisonWhere returns a parameterised SQL condition for a single comparison.
+func queryComparisonWhere(c *domain.QueryComparison) (string, []interface{}) {
+	switch c.Field {
+	case domain.QueryFieldTag:
+		where, args := tagWithinWhere(c.Value)
+		if c.Operator == domain.QueryNotEqual {
+			return `id NOT IN (SELECT transaction_id FROM transaction_tags WHERE ` + where + `)`, args
+		}
+		return `id IN (SELECT transaction_id FROM transaction_tags WHERE ` + where + `)`, args
+	case domain.QueryFieldLabel:
+		switch c.Operator {
+		case domain.QueryContains:
+			return `instr(lower(label), lower(?)) > 0`, []interface{}{c.Value}
+		case domain.QueryNotContains:
+			return `instr(lower(label), lower(?)) = 0`, []interface{}{c.Value}
+		default:
+			return `lower(label) ` + queryOperators[c.Operator] + ` lower(?)`, []interface{}{c.Value}
+		}
+	case domain.QueryFieldAmount:
+		return `amount ` + queryOperators[c.Operator] + ` ?`, []interface{}{c.Amount}
+	case domain.QueryFieldCurrency:
+		return `currency ` + queryOperators[c.Operator] + ` ?`, []interface{}{c.Value}
+	case domain.QueryFieldAccount:
+		return `account_id ` + queryOperators[c.Operator] + ` ?`, []interface{}{c.Value}
+	case domain.QueryFieldDate:
+		// Dates are stored as timestamps, so each day is compared as the range from its start to the next.
+		start := domain.TruncateDay(c.Date)
+		end := start.AddDate(0, 0, 1)
+		switch c.Operator {
+		case domain.QueryEqual:
+			return `(date >= ? AND date < ?)`, []interface{}{start, end}
+		case domain.QueryNotEqual:
+			return `(date < ? OR date >= ?)`, []interface{}{start, end}
+		case domain.QueryLess:
+			return `date < ?`, []interface{}{start}
+		case domain.QueryLessOrEqual:
+			return `date < ?`, []interface{}{end}
+		case domain.QueryGreater:
+			return `date >= ?`, []interface{}{end}
+		case domain.QueryGreaterOrEqual:
+			return `date >= ?`, []interface{}{start}
+		}
+	}
+	return `0`, nil
+}
+
+// placeholders returns n comma separated query placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// LoadTransactionsByTransferID loads both transactions belonging to the given transfer.
+func (x *sqliteTransaction) LoadTransactionsByTransferID(id string) ([]*domain.Transaction, errs.Error) {
+	query := `SELECT id, profile_id, account_id, transfer_id, label, amount, currency, date, created_at, updated_at FROM transactions WHERE transfer_id = ? ORDER BY amount;`
 	rows, err := x.db.Query(query, id)
 	if err != nil {
-		return nil, errs.New().
+		return nil, errs.FromErr(err).
 			WithStatusCode(http.StatusInternalServerError).
 			PrefixMessage("could not query transactions: ")
 	}
@@ -113,7 +313,7 @@ func (x *sqliteTransaction) LoadTransactionsByProfileID(id string) ([]*domain.Tr
 
 	for rows.Next() {
 		row := domain.NewTransaction()
-		err := rows.Scan(&row.ID, &row.ProfileID, &row.Label, &row.Amount)
+		err := rows.Scan(&row.ID, &row.ProfileID, &row.AccountID, &row.TransferID, &row.Label, &row.Amount, &row.Currency, &row.Date, &row.CreatedAt, &row.UpdatedAt)
 		if err != nil {
 			return nil, errs.FromErr(err).PrefixMessage("could not scan row: ")
 		}
@@ -125,8 +325,9 @@ func (x *sqliteTransaction) LoadTransactionsByProfileID(id string) ([]*domain.Tr
 
 // CreateTransaction creates the given transaction.
 func (x *sqliteTransaction) CreateTransaction(transaction *domain.Transaction) errs.Error {
-	query := `INSERT INTO transactions (id, profile_id, label, amount) VALUES(?, ?, ?, ?);`
-	_, err := x.db.Exec(query, transaction.ID, transaction.ProfileID, transaction.Label, transaction.Amount)
+	query := `INSERT INTO transactions (id, profile_id, account_id, transfer_id, label, amount, currency, date, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
+	_, err := x.db.Exec(query, transaction.ID, transaction.ProfileID, transaction.AccountID, transaction.TransferID, transaction.Label, transaction.Amount, transaction.Currency,
+		transaction.Date.UTC(), transaction.CreatedAt.UTC(), transaction.UpdatedAt.UTC())
 	if err != nil {
 		return errs.FromErr(err).PrefixMessage("could not insert row: ")
 	}
@@ -135,14 +336,46 @@ func (x *sqliteTransaction) CreateTransaction(transaction *domain.Transaction) e
 
 // UpdateTransaction updates the given transaction.
 func (x *sqliteTransaction) UpdateTransaction(transaction *domain.Transaction) errs.Error {
-	query := `UPDATE transactions SET profile_id = ?, label = ?, amount = ? WHERE id = ?;`
-	_, err := x.db.Exec(query, transaction.ProfileID, transaction.Label, transaction.Amount, transaction.ID)
+	query := `UPDATE transactions SET profile_id = ?, account_id = ?, label = ?, amount = ?, currency = ?, date = ?, updated_at = ? WHERE id = ?;`
+	_, err := x.db.Exec(query, transaction.ProfileID, transaction.AccountID, transaction.Label, transaction.Amount, transaction.Currency,
+		transaction.Date.UTC(), transaction.UpdatedAt.UTC(), transaction.ID)
 	if err != nil {
 		return errs.FromErr(err).PrefixMessage("could not update row: ")
 	}
 	return nil
 }
 
+// DeleteTransaction deletes the given transaction and its tags.
+func (x *sqliteTransaction) DeleteTransaction(id string) errs.Error {
+	if _, err := x.db.Exec(`DELETE FROM transaction_tags WHERE transaction_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete transaction tags: ")
+	}
+
+	res, err := x.db.Exec(`DELETE FROM transactions WHERE id = ?;`, id)
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete row: ")
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return errs.New().
+			WithCode(errs.ErrUnknownTransaction).
+			WithStatusCode(http.StatusNotFound).
+			WithMessage("transaction id not found")
+	}
+	return nil
+}
+
+// DeleteTransactionsByProfileID deletes all transactions belonging to the given profile, along with their tags.
+func (x *sqliteTransaction) DeleteTransactionsByProfileID(id string) errs.Error {
+	query := `DELETE FROM transaction_tags WHERE transaction_id IN (SELECT id FROM transactions WHERE profile_id = ?);`
+	if _, err := x.db.Exec(query, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete transaction tags: ")
+	}
+	if _, err := x.db.Exec(`DELETE FROM transactions WHERE profile_id = ?;`, id); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not delete rows: ")
+	}
+	return nil
+}
+
 // LoadTransactionTagsByID loads the given transactions tags by id.
 func (x *sqliteTransaction) LoadTransactionTagsByID(id string) ([]string, errs.Error) {
 	tags := make([]string, 0)
@@ -0,0 +1,142 @@
+package repository_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestSQLiteTransaction_LoadTransactionsByFilter checks that filters evaluated by the database give the
+// same result as when they are evaluated in memory.
+func TestSQLiteTransaction_LoadTransactionsByFilter(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+	transactionRepo := repository.NewSQLiteTransaction(db)
+
+	day := func(d int) time.Time {
+		return time.Date(2019, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+	transactions := domain.NewTransactionCollection().Add(
+		domain.NewTransaction().WithID("tra:1").WithLabel("Train ticket").WithAmount(-43500).WithCurrency("GBP").
+			WithTags("travel", "commute").WithDate(day(1)),
+		domain.NewTransaction().WithID("tra:2").WithLabel("Salary").WithAmount(250000).WithCurrency("GBP").
+			WithDate(day(2)),
+		domain.NewTransaction().WithID("tra:3").WithLabel("Bus 100%").WithAmount(-250).WithCurrency("GBP").
+			WithTags("travel").WithDate(day(3)),
+		domain.NewTransaction().WithID("tra:4").WithLabel("Ramen").WithAmount(-1200).WithCurrency("JPY").
+			WithTags("food", "travel").WithDate(day(3)),
+		domain.NewTransaction().WithID("tra:5").WithLabel("Groceries").WithAmount(-5000).WithCurrency("GBP").
+			WithTags("food").WithDate(day(4)).WithAccountID("acc:1"),
+		domain.NewTransaction().WithID("tra:6").WithLabel("Souq").WithAmount(-50500).WithCurrency("BHD").
+			WithTags("travel").WithDate(day(5)),
+		domain.NewTransaction().WithID("tra:8").WithLabel("Train").WithAmount(-4000).WithCurrency("GBP").
+			WithTags("transport/rail", "travel/uk").WithDate(day(6)),
+		domain.NewTransaction().WithID("tra:9").WithLabel("Taxi").WithAmount(-1500).WithCurrency("GBP").
+			WithTags("transportation").WithDate(day(6)),
+		domain.NewTransaction().WithID("tra:10").WithLabel("Café Nero").WithAmount(-350).WithCurrency("GBP").
+			WithTags("food").WithDate(day(6)),
+	)
+	for i, tr := range transactions.All() {
+		tr.WithProfileID("pro:1")
+		tr.CreatedAt = day(1).Add(time.Duration(i) * time.Minute)
+		tr.UpdatedAt = tr.CreatedAt
+		if err := transactionRepo.CreateTransaction(tr); err != nil {
+			t.Fatalf("could not create transaction: %s", err)
+		}
+		if err := transactionRepo.AddTransactionTags(tr.ID, tr.Tags...); err != nil {
+			t.Fatalf("could not add tags: %s", err)
+		}
+	}
+	other := domain.NewTransaction().WithID("tra:7").WithProfileID("pro:2").WithLabel("Bus").
+		WithAmount(-250).WithCurrency("GBP").WithDate(day(3))
+	if err := transactionRepo.CreateTransaction(other); err != nil {
+		t.Fatalf("could not create transaction: %s", err)
+	}
+
+	filters := map[string]domain.TransactionFilter{
+		"All":           {},
+		"DateRange":     {DateRange: domain.DateRange{From: day(2), To: day(3)}},
+		"In":            {Direction: domain.DirectionIn},
+		"Out":           {Direction: domain.DirectionOut},
+		"Account":       {AccountID: "acc:1"},
+		"AnyTag":        {Tags: []string{"commute", "food"}},
+		"AllTags":       {Tags: []string{"food", "travel"}, MatchAllTags: true},
+		"ExcludeTags":   {Tags: []string{"travel"}, ExcludeTags: []string{"commute", "food"}},
+		"TagWithin":     {Tags: []string{"transport", "travel/uk"}},
+		"AllTagsWithin": {Tags: []string{"transport", "travel"}, MatchAllTags: true},
+		"ExcludeWithin": {ExcludeTags: []string{"transport", "food"}},
+		"Untagged":      {Untagged: true},
+		"LabelContains": {LabelContains: "RA"},
+		"LabelWildcard": {LabelContains: "%"},
+		"LabelASCII":    {LabelContains: "CAFé"},
+		"LabelAccent":   {LabelContains: "CAFÉ"},
+		"MinAmount":     {Tags: []string{"travel"}, MinAmount: domain.NewMoney(5000, "GBP")},
+		"MaxAmount":     {MinAmount: domain.NewMoney(50, "GBP"), MaxAmount: domain.NewMoney(5050, "GBP")},
+		"SortAmount":    {Sort: domain.TransactionSortAmount},
+		"SortLabelDesc": {Sort: domain.TransactionSortLabel, Descending: true},
+		"SortDateDesc":  {Sort: domain.TransactionSortDate, Descending: true},
+		"Limit":         {Direction: domain.DirectionOut, Limit: 2},
+		"Offset":        {Direction: domain.DirectionOut, Offset: 1, Limit: 2},
+		"OffsetOnly":    {Offset: 4},
+	}
+	queries := []string{
+		`tag:travel and amount < -5000 and label ~ "train"`,
+		`tag != travel or label = "SALARY"`,
+		`not (tag:food or currency = JPY) and amount <= -250`,
+		`label !~ "%"`,
+		`label ~ "CAFé" or label = "café nero"`,
+		`label ~ "CAFÉ" or label = "CAFÉ NERO"`,
+		`date = 2019-03-03`,
+		`date != 2019-03-03 and date > 2019-03-01 and date <= 2019-03-04`,
+		`date < 2019-03-03 or date >= 2019-03-05`,
+		`account = "acc:1" or account != "acc:1" and amount > 0`,
+		`tag:transport or tag = travel/uk`,
+		`tag != transport and tag != travel and amount < 0`,
+	}
+	for _, q := range queries {
+		query, err := domain.ParseQuery(q)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", q, err)
+		}
+		filters["Query "+q] = domain.TransactionFilter{Query: query}
+	}
+
+	for name, filter := range filters {
+		loaded, err := transactionRepo.LoadTransactionsByFilter("pro:1", filter)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", name, err)
+			continue
+		}
+		got := make([]string, 0)
+		for _, tr := range loaded {
+			got = append(got, tr.ID)
+		}
+		exp := make([]string, 0)
+		for _, tr := range transactions.Filter(filter).All() {
+			exp = append(exp, tr.ID)
+		}
+		if !reflect.DeepEqual(exp, got) {
+			t.Errorf("%s: expected %v, got %v", name, exp, got)
+		}
+	}
+
+	t.Run("QueryTagWithin", func(t *testing.T) {
+		query, err := domain.ParseQuery(`tag:transport`)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		loaded, loadErr := transactionRepo.LoadTransactionsByFilter("pro:1", domain.TransactionFilter{Query: query})
+		if loadErr != nil {
+			t.Fatalf("unexpected error: %s", loadErr)
+		}
+		if len(loaded) != 1 || loaded[0].ID != "tra:8" {
+			t.Errorf("expected only the transport/rail transaction, got %v", loaded)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"database/sql"
+	"github.com/tomwright/finance-planner/internal/errs"
+)
+
+// Repositories contains a repository of each type that all read and write within the same unit of work.
+type Repositories struct {
+	Profile      Profile
+	Transaction  Transaction
+	Schedule     Schedule
+	ExchangeRate ExchangeRate
+	Account      Account
+	TagRule      TagRule
+	Tag          Tag
+	Envelope     Envelope
+}
+
+// UnitOfWork allows multiple writes across repositories to be committed or rolled back as a whole.
+type UnitOfWork interface {
+	// Do calls fn with repositories that read and write within a single database transaction.
+	// The transaction is committed if fn returns nil, and rolled back otherwise.
+	Do(fn func(repos Repositories) errs.Error) errs.Error
+}
+
+func NewSQLiteUnitOfWork(db *sql.DB) UnitOfWork {
+	return &sqliteUnitOfWork{
+		db: db,
+	}
+}
+
+// sqliteUnitOfWork implements UnitOfWork
+type sqliteUnitOfWork struct {
+	db *sql.DB
+}
+
+// Do calls fn with repositories that read and write within a single database transaction.
+// The transaction is committed if fn returns nil, and rolled back otherwise.
+func (x *sqliteUnitOfWork) Do(fn func(repos Repositories) errs.Error) errs.Error {
+	tx, err := x.db.Begin()
+	if err != nil {
+		return errs.FromErr(err).PrefixMessage("could not begin tx: ")
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(Repositories{
+		Profile:      &sqliteProfile{db: tx},
+		Transaction:  &sqliteTransaction{db: tx},
+		Schedule:     &sqliteSchedule{db: tx},
+		ExchangeRate: &sqliteExchangeRate{db: tx},
+		Account:      &sqliteAccount{db: tx},
+		TagRule:      &sqliteTagRule{db: tx},
+		Tag:          &sqliteTag{db: tx},
+		Envelope:     &sqliteEnvelope{db: tx},
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.FromErr(err).PrefixMessage("could not commit tx: ")
+	}
+	committed = true
+	return nil
+}
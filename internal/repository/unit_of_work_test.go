@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"github.com/tomwright/finance-planner/internal/application/domain"
+	"github.com/tomwright/finance-planner/internal/errs"
+	"github.com/tomwright/finance-planner/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestUnitOfWork_Do(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := repository.NewSQLiteMigrator(db).Migrate(); err != nil {
+		t.Fatalf("could not migrate: %s", err)
+	}
+
+	unitOfWork := repository.NewSQLiteUnitOfWork(db)
+	transactionRepo := repository.NewSQLiteTransaction(db)
+
+	create := func(id string, tags ...string) errs.Error {
+		return unitOfWork.Do(func(repos repository.Repositories) errs.Error {
+			transaction := domain.NewTransaction().
+				WithID(id).
+				WithProfileID("pro:1").
+				WithLabel("Train ticket").
+				WithAmount(-43500).
+				WithDate(time.Now())
+			if err := repos.Transaction.CreateTransaction(transaction); err != nil {
+				return err
+			}
+			return repos.Transaction.AddTransactionTags(id, tags...)
+		})
+	}
+
+	t.Run("Commit", func(t *testing.T) {
+		if err := create("tra:1", "commute"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := transactionRepo.LoadTransactionByID("tra:1"); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		tags, err := transactionRepo.LoadTransactionTagsByID("tra:1")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(tags) != 1 || tags[0] != "commute" {
+			t.Errorf("unexpected tags: %v", tags)
+		}
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		// Duplicate tags violate the primary key after the transaction row has been written.
+		if err := create("tra:2", "commute", "commute"); err == nil {
+			t.Fatalf("expected error")
+		}
+		_, err := transactionRepo.LoadTransactionByID("tra:2")
+		if err == nil || err.Code() != errs.ErrUnknownTransaction {
+			t.Errorf("expected %s error, got %v", errs.ErrUnknownTransaction, err)
+		}
+	})
+}
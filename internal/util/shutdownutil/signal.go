@@ -11,7 +11,7 @@ import (
 // when a shutdown signal is received.
 // It is expected that this func is run in a go routine.
 func HandleShutdownSignal(errCh chan error) {
-	quitCh := make(chan os.Signal)
+	quitCh := make(chan os.Signal, 1)
 	signal.Notify(quitCh, os.Interrupt, syscall.SIGTERM)
 
 	hit := false